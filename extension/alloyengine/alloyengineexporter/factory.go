@@ -0,0 +1,75 @@
+// Package alloyengineexporter provides the alloyengine exporter, which sends
+// telemetry to otelcol.receiver.alloyengine components of the Alloy
+// configuration run by the alloyengine extension.
+package alloyengineexporter
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+)
+
+var (
+	// typeStr is the type string for the alloyengine exporter.
+	typeStr = component.MustNewType("alloyengine")
+
+	// stability level of the component.
+	stability = component.StabilityLevelDevelopment
+)
+
+// Config configures the alloyengine exporter.
+type Config struct {
+	// Name of the otelcol.receiver.alloyengine component to send telemetry to.
+	Name string `mapstructure:"name"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// NewFactory creates a factory for the alloyengine exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		exporter.WithTraces(createTraces, stability),
+		exporter.WithMetrics(createMetrics, stability),
+		exporter.WithLogs(createLogs, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration for the exporter.
+func createDefaultConfig() component.Config {
+	return &Config{}
+}
+
+func createTraces(_ context.Context, _ exporter.Settings, cfg component.Config) (exporter.Traces, error) {
+	return newBridgeExporter(cfg.(*Config)), nil
+}
+
+func createMetrics(_ context.Context, _ exporter.Settings, cfg component.Config) (exporter.Metrics, error) {
+	return newBridgeExporter(cfg.(*Config)), nil
+}
+
+func createLogs(_ context.Context, _ exporter.Settings, cfg component.Config) (exporter.Logs, error) {
+	return newBridgeExporter(cfg.(*Config)), nil
+}
+
+// bridgeExporter forwards the telemetry it consumes to the
+// otelcol.receiver.alloyengine component registered under its name.
+type bridgeExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+	*bridge.Sender
+}
+
+func newBridgeExporter(cfg *Config) *bridgeExporter {
+	return &bridgeExporter{Sender: bridge.NewSender(bridge.ToDefaultEngine, cfg.Name)}
+}
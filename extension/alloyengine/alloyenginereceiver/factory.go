@@ -0,0 +1,93 @@
+// Package alloyenginereceiver provides the alloyengine receiver, which
+// receives telemetry sent by otelcol.exporter.alloyengine components of the
+// Alloy configuration run by the alloyengine extension.
+package alloyenginereceiver
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+)
+
+var (
+	// typeStr is the type string for the alloyengine receiver.
+	typeStr = component.MustNewType("alloyengine")
+
+	// stability level of the component.
+	stability = component.StabilityLevelDevelopment
+)
+
+// Config configures the alloyengine receiver.
+type Config struct {
+	// Name that otelcol.exporter.alloyengine components send telemetry to.
+	Name string `mapstructure:"name"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// NewFactory creates a factory for the alloyengine receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		receiver.WithTraces(createTraces, stability),
+		receiver.WithMetrics(createMetrics, stability),
+		receiver.WithLogs(createLogs, stability),
+	)
+}
+
+// createDefaultConfig creates the default configuration for the receiver.
+func createDefaultConfig() component.Config {
+	return &Config{}
+}
+
+func createTraces(_ context.Context, _ receiver.Settings, cfg component.Config, next consumer.Traces) (receiver.Traces, error) {
+	name := cfg.(*Config).Name
+	return &bridgeReceiver{register: func() (func(), error) { return bridge.RegisterTraces(bridge.ToOTelEngine, name, next) }}, nil
+}
+
+func createMetrics(_ context.Context, _ receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+	name := cfg.(*Config).Name
+	return &bridgeReceiver{register: func() (func(), error) { return bridge.RegisterMetrics(bridge.ToOTelEngine, name, next) }}, nil
+}
+
+func createLogs(_ context.Context, _ receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+	name := cfg.(*Config).Name
+	return &bridgeReceiver{register: func() (func(), error) { return bridge.RegisterLogs(bridge.ToOTelEngine, name, next) }}, nil
+}
+
+// bridgeReceiver registers the next consumer of a signal in the bridge while
+// it runs.
+type bridgeReceiver struct {
+	register   func() (func(), error)
+	unregister func()
+}
+
+// Start is called when the receiver is started.
+func (r *bridgeReceiver) Start(context.Context, component.Host) error {
+	unregister, err := r.register()
+	if err != nil {
+		return err
+	}
+	r.unregister = unregister
+	return nil
+}
+
+// Shutdown is called when the receiver is being stopped.
+func (r *bridgeReceiver) Shutdown(context.Context) error {
+	if r.unregister != nil {
+		r.unregister()
+		r.unregister = nil
+	}
+	return nil
+}
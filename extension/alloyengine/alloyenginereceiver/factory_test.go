@@ -0,0 +1,47 @@
+package alloyenginereceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+)
+
+func TestReceiveFromBridge(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Name = "test-bridge"
+	require.NoError(t, cfg.Validate())
+
+	rcvr, err := factory.CreateLogs(t.Context(), receivertest.NewNopSettings(typeStr), cfg, sink)
+	require.NoError(t, err)
+
+	// Telemetry sent by otelcol.exporter.alloyengine components.
+	sender := bridge.NewSender(bridge.ToOTelEngine, "test-bridge")
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+
+	// The receiver only receives telemetry while it runs.
+	require.Error(t, sender.ConsumeLogs(t.Context(), logs))
+
+	require.NoError(t, rcvr.Start(t.Context(), componenttest.NewNopHost()))
+	require.NoError(t, sender.ConsumeLogs(t.Context(), logs))
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, "hello", sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	// A second receiver can't use the same name.
+	other, err := factory.CreateLogs(t.Context(), receivertest.NewNopSettings(typeStr), cfg, sink)
+	require.NoError(t, err)
+	require.Error(t, other.Start(t.Context(), componenttest.NewNopHost()))
+
+	require.NoError(t, rcvr.Shutdown(t.Context()))
+	require.Error(t, sender.ConsumeLogs(t.Context(), logs))
+}
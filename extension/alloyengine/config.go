@@ -2,7 +2,11 @@ package alloyengine
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configopaque"
 )
 
 type Config struct {
@@ -13,7 +17,46 @@ type Config struct {
 // This type represents the incoming format of the Alloy configuration
 // This is a one-of type, and it is expected that only one of the fields will be set (ie, we cannot define multiple config sources of different types)
 type AlloyConfig struct {
-	File string `mapstructure:"file"`
+	File      string           `mapstructure:"file"`
+	Inline    string           `mapstructure:"inline"`
+	URL       string           `mapstructure:"url"`
+	RemoteCfg *RemoteCfgConfig `mapstructure:"remotecfg"`
+
+	// PollFrequency is how often the configuration is fetched again from URL.
+	PollFrequency time.Duration `mapstructure:"poll_frequency"`
+	// FetchTimeout is the timeout of each fetch of the configuration from URL.
+	// It defaults to the lowest of defaultFetchTimeout and PollFrequency.
+	FetchTimeout time.Duration `mapstructure:"fetch_timeout"`
+}
+
+// defaultFetchTimeout is the default timeout of fetching the configuration
+// from URL.
+const defaultFetchTimeout = 30 * time.Second
+
+// fetchTimeout returns the timeout of each fetch of the configuration from
+// URL.
+func (cfg AlloyConfig) fetchTimeout() time.Duration {
+	if cfg.FetchTimeout > 0 {
+		return cfg.FetchTimeout
+	}
+	return min(defaultFetchTimeout, cfg.PollFrequency)
+}
+
+// RemoteCfgConfig configures the remotecfg service of the Default Engine,
+// which fetches the Alloy configuration from a remote server.
+type RemoteCfgConfig struct {
+	URL           string            `mapstructure:"url"`
+	ID            string            `mapstructure:"id"`
+	Name          string            `mapstructure:"name"`
+	Attributes    map[string]string `mapstructure:"attributes"`
+	PollFrequency time.Duration     `mapstructure:"poll_frequency"`
+	BasicAuth     *BasicAuthConfig  `mapstructure:"basic_auth"`
+}
+
+// BasicAuthConfig configures basic authentication to the remotecfg server.
+type BasicAuthConfig struct {
+	Username string              `mapstructure:"username"`
+	Password configopaque.String `mapstructure:"password"`
 }
 
 func (cfg *Config) flagsAsSlice() []string {
@@ -25,13 +68,41 @@ func (cfg *Config) flagsAsSlice() []string {
 }
 
 func (cfg *Config) Validate() error {
-	if cfg.AlloyConfig.File == "" {
-		return fmt.Errorf("config.file is required")
+	var sources int
+	for _, set := range []bool{
+		cfg.AlloyConfig.File != "",
+		cfg.AlloyConfig.Inline != "",
+		cfg.AlloyConfig.URL != "",
+		cfg.AlloyConfig.RemoteCfg != nil,
+	} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("exactly one of config.file, config.inline, config.url or config.remotecfg is required")
 	}
 
-	_, err := os.Stat(cfg.AlloyConfig.File)
-	if err != nil {
-		return fmt.Errorf("provided config path %s does not exist or is not readable: %w", cfg.AlloyConfig.File, err)
+	switch {
+	case cfg.AlloyConfig.File != "":
+		_, err := os.Stat(cfg.AlloyConfig.File)
+		if err != nil {
+			return fmt.Errorf("provided config path %s does not exist or is not readable: %w", cfg.AlloyConfig.File, err)
+		}
+	case cfg.AlloyConfig.URL != "":
+		if _, err := url.ParseRequestURI(cfg.AlloyConfig.URL); err != nil {
+			return fmt.Errorf("invalid config.url: %w", err)
+		}
+		if cfg.AlloyConfig.PollFrequency <= 0 {
+			return fmt.Errorf("config.poll_frequency must be greater than 0")
+		}
+		if cfg.AlloyConfig.FetchTimeout < 0 || cfg.AlloyConfig.FetchTimeout > cfg.AlloyConfig.PollFrequency {
+			return fmt.Errorf("config.fetch_timeout must be between 0 and config.poll_frequency")
+		}
+	case cfg.AlloyConfig.RemoteCfg != nil:
+		if cfg.AlloyConfig.RemoteCfg.URL == "" {
+			return fmt.Errorf("config.remotecfg.url is required")
+		}
 	}
 
 	return nil
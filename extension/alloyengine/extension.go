@@ -45,7 +45,7 @@ type alloyEngineExtension struct {
 	config            *Config
 	settings          component.TelemetrySettings
 	runExited         chan struct{}
-	runCommandFactory func() *cobra.Command
+	runCommandFactory func(reload <-chan struct{}) *cobra.Command
 
 	stateMutex sync.Mutex
 	state      state
@@ -58,7 +58,7 @@ func newAlloyEngineExtension(config *Config, settings component.TelemetrySetting
 		config:            config,
 		settings:          settings,
 		state:             stateNotStarted,
-		runCommandFactory: flowcmd.RunCommand,
+		runCommandFactory: flowcmd.RunCommandWithReload,
 	}
 }
 
@@ -74,21 +74,43 @@ func (e *alloyEngineExtension) Start(ctx context.Context, host component.Host) e
 		return fmt.Errorf("cannot start alloyengine extension in current state: %s", e.state.String())
 	}
 
-	runCtx, runCancel := context.WithCancel(context.Background())
-	e.runCancel = runCancel
-	e.runExited = make(chan struct{})
+	source, err := newConfigSource(e.config.AlloyConfig)
+	if err != nil {
+		return err
+	}
 
-	runCommand := e.runCommandFactory()
-	runCommand.SetArgs([]string{e.config.AlloyConfig.File})
-	err := runCommand.ParseFlags(e.config.flagsAsSlice())
+	reload := make(chan struct{}, 1)
+	runCommand := e.runCommandFactory(reload)
+	runCommand.SetArgs([]string{source.path})
+	err = runCommand.ParseFlags(e.config.flagsAsSlice())
 	if err != nil {
+		_ = source.close()
 		return fmt.Errorf("failed to parse flags: %w", err)
 	}
 
+	runCtx, runCancel := context.WithCancel(context.Background())
+	e.runCancel = runCancel
+	e.runExited = make(chan struct{})
+
 	go func() {
 		defer close(e.runExited)
 
-		err := e.runWithBackoffRetry(runCommand, runCtx)
+		var wg sync.WaitGroup
+		if source.polls() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				e.pollConfig(runCtx, source, reload)
+			}()
+		}
+
+		err := e.runWithBackoffRetry(runCommand, source, runCtx)
+
+		runCancel()
+		wg.Wait()
+		if err := source.close(); err != nil {
+			e.settings.Logger.Warn("failed to remove the Alloy configuration directory", zap.Error(err))
+		}
 
 		e.stateMutex.Lock()
 		previousState := e.state
@@ -107,13 +129,13 @@ func (e *alloyEngineExtension) Start(ctx context.Context, host component.Host) e
 	return nil
 }
 
-func (e *alloyEngineExtension) runWithBackoffRetry(runCommand *cobra.Command, ctx context.Context) error {
+func (e *alloyEngineExtension) runWithBackoffRetry(runCommand *cobra.Command, source *configSource, ctx context.Context) error {
 	var lastError error
 	baseDelay := 1 * time.Second
 	i := 1
 
 	for {
-		err := runCommand.ExecuteContext(ctx)
+		err := e.execute(runCommand, source, ctx)
 
 		if err == nil {
 			return nil
@@ -140,6 +162,50 @@ func (e *alloyEngineExtension) runWithBackoffRetry(runCommand *cobra.Command, ct
 	}
 }
 
+// execute refreshes the configuration of the source, and runs the command
+// with it. The last configuration fetched is used if the source can't be
+// refreshed.
+func (e *alloyEngineExtension) execute(runCommand *cobra.Command, source *configSource, ctx context.Context) error {
+	if _, err := source.refresh(ctx); err != nil {
+		if !source.available() {
+			return err
+		}
+		e.settings.Logger.Warn("failed to refresh the Alloy configuration, using the last one fetched", zap.Error(err))
+	}
+	return runCommand.ExecuteContext(ctx)
+}
+
+// pollConfig refreshes the configuration of the source periodically, and
+// requests the run command to reload when it changes.
+func (e *alloyEngineExtension) pollConfig(ctx context.Context, source *configSource, reload chan<- struct{}) {
+	ticker := time.NewTicker(e.config.AlloyConfig.PollFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := source.refresh(ctx)
+		if err != nil {
+			e.settings.Logger.Warn("failed to refresh the Alloy configuration", zap.Error(err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		e.settings.Logger.Info("Alloy configuration changed, reloading")
+		select {
+		case reload <- struct{}{}:
+		default:
+			// A reload is already pending, and will load the latest configuration.
+		}
+	}
+}
+
 // Shutdown is called when the extension is being stopped.
 func (e *alloyEngineExtension) Shutdown(ctx context.Context) error {
 	e.stateMutex.Lock()
@@ -27,7 +27,7 @@ func defaultTestConfig() *Config {
 func newTestExtension(t *testing.T, factory func() *cobra.Command, config *Config) *alloyEngineExtension {
 	t.Helper()
 	e := newAlloyEngineExtension(config, component.TelemetrySettings{Logger: zap.NewNop()})
-	e.runCommandFactory = factory
+	e.runCommandFactory = func(<-chan struct{}) *cobra.Command { return factory() }
 	return e
 }
 
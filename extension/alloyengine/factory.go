@@ -2,6 +2,7 @@ package alloyengine
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
@@ -27,7 +28,11 @@ func NewFactory() extension.Factory {
 
 // createDefaultConfig creates the default configuration for the extension.
 func createDefaultConfig() component.Config {
-	return &Config{}
+	return &Config{
+		AlloyConfig: AlloyConfig{
+			PollFrequency: time.Minute,
+		},
+	}
 }
 
 // createExtension creates an alloyengine extension instance.
@@ -0,0 +1,185 @@
+package alloyengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grafana/alloy/syntax/token/builder"
+)
+
+// configFileName is the name of the file which holds the Alloy configuration
+// of the sources which don't point at a file.
+const configFileName = "config.alloy"
+
+// configSource provides the Alloy configuration loaded by the run command.
+//
+// The configuration of inline, remotecfg and url sources is written to a
+// file in a directory owned by the source, as the run command can only load
+// configuration files.
+type configSource struct {
+	path string
+	dir  string
+
+	// fetch returns the latest configuration of sources which can change
+	// while the extension runs.
+	fetch func(ctx context.Context) ([]byte, error)
+
+	mut     sync.Mutex
+	written bool
+	hash    [sha256.Size]byte
+}
+
+func newConfigSource(cfg AlloyConfig) (*configSource, error) {
+	if cfg.File != "" {
+		return &configSource{path: cfg.File, written: true}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "alloyengine-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Alloy configuration directory: %w", err)
+	}
+	s := &configSource{
+		path: filepath.Join(dir, configFileName),
+		dir:  dir,
+	}
+
+	switch {
+	case cfg.URL != "":
+		timeout := cfg.fetchTimeout()
+		s.fetch = func(ctx context.Context) ([]byte, error) {
+			// A stalled server must not block starting or reloading the run
+			// command.
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return fetchURL(ctx, cfg.URL)
+		}
+		return s, nil
+	case cfg.RemoteCfg != nil:
+		_, err = s.write(remoteCfgConfig(cfg.RemoteCfg))
+	default:
+		_, err = s.write([]byte(cfg.Inline))
+	}
+	if err != nil {
+		_ = s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// polls reports whether the configuration of the source can change.
+func (s *configSource) polls() bool {
+	return s.fetch != nil
+}
+
+// available reports whether the configuration can be loaded from s.path.
+func (s *configSource) available() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.written
+}
+
+// refresh fetches the latest configuration of the source, and reports
+// whether it changed since it was last written to s.path.
+func (s *configSource) refresh(ctx context.Context) (bool, error) {
+	if s.fetch == nil {
+		return false, nil
+	}
+
+	b, err := s.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.write(b)
+}
+
+func (s *configSource) write(b []byte) (bool, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	hash := sha256.Sum256(b)
+	if s.written && hash == s.hash {
+		return false, nil
+	}
+
+	// Write to a temporary file first, so that the run command never reads a
+	// partially written configuration.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return false, fmt.Errorf("failed to write the Alloy configuration: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return false, fmt.Errorf("failed to write the Alloy configuration: %w", err)
+	}
+
+	s.written = true
+	s.hash = hash
+	return true, nil
+}
+
+// close removes the files written by the source.
+func (s *configSource) close() error {
+	if s.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.dir)
+}
+
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the Alloy configuration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the Alloy configuration: unexpected status code %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Alloy configuration: %w", err)
+	}
+	return b, nil
+}
+
+// remoteCfgConfig returns an Alloy configuration which only holds a
+// remotecfg block; the remotecfg service then loads the configuration served
+// by the remote server.
+func remoteCfgConfig(cfg *RemoteCfgConfig) []byte {
+	block := builder.NewBlock([]string{"remotecfg"}, "")
+	body := block.Body()
+
+	// Unset fields are omitted so that the remotecfg defaults apply.
+	body.SetAttributeValue("url", cfg.URL)
+	if cfg.ID != "" {
+		body.SetAttributeValue("id", cfg.ID)
+	}
+	if cfg.Name != "" {
+		body.SetAttributeValue("name", cfg.Name)
+	}
+	if len(cfg.Attributes) > 0 {
+		body.SetAttributeValue("attributes", cfg.Attributes)
+	}
+	if cfg.PollFrequency > 0 {
+		body.SetAttributeValue("poll_frequency", cfg.PollFrequency.String())
+	}
+	if cfg.BasicAuth != nil {
+		basicAuth := builder.NewBlock([]string{"basic_auth"}, "")
+		basicAuth.Body().SetAttributeValue("username", cfg.BasicAuth.Username)
+		basicAuth.Body().SetAttributeValue("password", string(cfg.BasicAuth.Password))
+		body.AppendBlock(basicAuth)
+	}
+
+	f := builder.NewFile()
+	f.Body().AppendBlock(block)
+	return f.Bytes()
+}
@@ -0,0 +1,184 @@
+package alloyengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestConfig_Sources(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AlloyConfig
+		wantErr string
+	}{
+		{
+			name:   "inline",
+			config: AlloyConfig{Inline: `logging {}`},
+		},
+		{
+			name:   "url",
+			config: AlloyConfig{URL: "http://localhost:8080/config.alloy", PollFrequency: time.Minute},
+		},
+		{
+			name:   "remotecfg",
+			config: AlloyConfig{RemoteCfg: &RemoteCfgConfig{URL: "http://localhost:8080"}},
+		},
+		{
+			name:    "multiple sources",
+			config:  AlloyConfig{Inline: `logging {}`, URL: "http://localhost:8080/config.alloy"},
+			wantErr: "exactly one of config.file, config.inline, config.url or config.remotecfg is required",
+		},
+		{
+			name:    "url without poll frequency",
+			config:  AlloyConfig{URL: "http://localhost:8080/config.alloy"},
+			wantErr: "config.poll_frequency must be greater than 0",
+		},
+		{
+			name:    "url with fetch timeout above poll frequency",
+			config:  AlloyConfig{URL: "http://localhost:8080/config.alloy", PollFrequency: time.Second, FetchTimeout: time.Minute},
+			wantErr: "config.fetch_timeout must be between 0 and config.poll_frequency",
+		},
+		{
+			name:    "remotecfg without url",
+			config:  AlloyConfig{RemoteCfg: &RemoteCfgConfig{ID: "collector-1"}},
+			wantErr: "config.remotecfg.url is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{AlloyConfig: tt.config}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURLSource_FetchTimeout(t *testing.T) {
+	// The server accepts the request, but never responds.
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	source, err := newConfigSource(AlloyConfig{URL: srv.URL, PollFrequency: time.Minute, FetchTimeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = source.close() })
+
+	_, err = source.refresh(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, source.available())
+}
+
+func TestConfigSource_Inline(t *testing.T) {
+	source, err := newConfigSource(AlloyConfig{Inline: `logging {}`})
+	require.NoError(t, err)
+	require.True(t, source.available())
+
+	b, err := os.ReadFile(source.path)
+	require.NoError(t, err)
+	require.Equal(t, `logging {}`, string(b))
+
+	require.NoError(t, source.close())
+	_, err = os.Stat(filepath.Dir(source.path))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestConfigSource_RemoteCfg(t *testing.T) {
+	source, err := newConfigSource(AlloyConfig{RemoteCfg: &RemoteCfgConfig{
+		URL:           "https://fleet.example.com",
+		ID:            "collector-1",
+		Attributes:    map[string]string{"env": "prod"},
+		PollFrequency: 30 * time.Second,
+		BasicAuth:     &BasicAuthConfig{Username: "user", Password: "secret"},
+	}})
+	require.NoError(t, err)
+	defer source.close()
+
+	b, err := os.ReadFile(source.path)
+	require.NoError(t, err)
+	require.Equal(t, `remotecfg {
+	url        = "https://fleet.example.com"
+	id         = "collector-1"
+	attributes = {
+		env = "prod",
+	}
+	poll_frequency = "30s"
+
+	basic_auth {
+		username = "user"
+		password = "secret"
+	}
+}`, string(b))
+}
+
+func TestURLSource_ReloadsOnChange(t *testing.T) {
+	var content atomic.Value
+	content.Store(`logging {}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(content.Load().(string)))
+	}))
+	defer srv.Close()
+
+	var (
+		configPath atomic.Value
+		reloads    atomic.Int32
+	)
+	cfg := &Config{AlloyConfig: AlloyConfig{URL: srv.URL, PollFrequency: 10 * time.Millisecond}}
+	e := newAlloyEngineExtension(cfg, componenttest.NewNopTelemetrySettings())
+	e.runCommandFactory = func(reload <-chan struct{}) *cobra.Command {
+		return &cobra.Command{
+			RunE: func(cmd *cobra.Command, args []string) error {
+				configPath.Store(args[0])
+				for {
+					select {
+					case <-cmd.Context().Done():
+						return nil
+					case <-reload:
+						reloads.Add(1)
+					}
+				}
+			},
+		}
+	}
+
+	require.NoError(t, e.Start(t.Context(), componenttest.NewNopHost()))
+
+	require.Eventually(t, func() bool { return configPath.Load() != nil }, time.Second, 10*time.Millisecond)
+	path := configPath.Load().(string)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `logging {}`, string(b))
+
+	// The configuration is only reloaded when it changes.
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, reloads.Load())
+
+	content.Store(`logging { level = "debug" }`)
+	require.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 10*time.Millisecond)
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `logging { level = "debug" }`, string(b))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	t.Cleanup(cancel)
+	require.NoError(t, e.Shutdown(shutdownCtx))
+
+	// The fetched configuration is removed on shutdown.
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
@@ -40,3 +40,9 @@ func RootCommand() *cobra.Command {
 func RunCommand() *cobra.Command {
 	return alloycli.RunCommand()
 }
+
+// RunCommandWithReload exposes the run command, which additionally reloads
+// its configuration each time a value is received from reload.
+func RunCommandWithReload(reload <-chan struct{}) *cobra.Command {
+	return alloycli.RunCommandWithReload(reload)
+}
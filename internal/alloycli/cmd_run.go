@@ -39,6 +39,7 @@ import (
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/runtime/tracing"
 	"github.com/grafana/alloy/internal/service"
+	graphqlservice "github.com/grafana/alloy/internal/service/graphql"
 	httpservice "github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/service/labelstore"
 	"github.com/grafana/alloy/internal/service/livedebugging"
@@ -55,6 +56,14 @@ import (
 )
 
 func RunCommand() *cobra.Command {
+	return RunCommandWithReload(nil)
+}
+
+// RunCommandWithReload is like RunCommand, but the returned command also
+// reloads its configuration each time a value is received from reload. This
+// allows embedders of the Default Engine to trigger reloads without sending
+// SIGHUP to the process.
+func RunCommandWithReload(reload <-chan struct{}) *cobra.Command {
 	r := &alloyRun{
 		inMemoryAddr:          "alloy.internal:12345",
 		httpListenAddr:        "127.0.0.1:12345",
@@ -70,6 +79,7 @@ func RunCommand() *cobra.Command {
 		disableSupportBundle:  false,
 		windowsPriority:       windowspriority.PriorityNormal,
 		taskShutdownDeadline:  10 * time.Minute,
+		reloadTrigger:         reload,
 	}
 
 	cmd := &cobra.Command{
@@ -205,6 +215,7 @@ type alloyRun struct {
 	disableSupportBundle         bool
 	windowsPriority              string
 	taskShutdownDeadline         time.Duration
+	reloadTrigger                <-chan struct{}
 }
 
 func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
@@ -325,6 +336,7 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		})
 	}
 
+	peerClient, peerTLS := clusterService.PeerClient()
 	httpService := httpservice.New(httpservice.Options{
 		Logger:   l,
 		Tracer:   t,
@@ -343,6 +355,8 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		BundleContext: httpservice.SupportBundleContext{
 			RuntimeFlags:         runtimeFlags,
 			DisableSupportBundle: fr.disableSupportBundle,
+			PeerClient:           peerClient,
+			PeerTLS:              peerTLS,
 		},
 	})
 
@@ -372,6 +386,30 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 	labelService := labelstore.New(l, reg)
 	alloyseed.Init(fr.storagePath, l)
 
+	services := []service.Service{
+		clusterService,
+		httpService,
+		labelService,
+		liveDebuggingService,
+		otelService,
+		remoteCfgService,
+		uiService,
+	}
+
+	// The GraphQL API is experimental, so it's only served when experimental
+	// functionality is enabled.
+	if featuregate.CheckAllowed(featuregate.StabilityExperimental, fr.minStability, "GraphQL API") == nil {
+		graphqlService, err := graphqlservice.New(graphqlservice.Options{
+			Logger:          log.With(l, "service", "graphql"),
+			ReadyFunc:       func() bool { return ready() },
+			CallbackManager: liveDebuggingService.Data().(livedebugging.CallbackManager),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the graphql service: %w", err)
+		}
+		services = append(services, graphqlService)
+	}
+
 	f := alloy_runtime.New(alloy_runtime.Options{
 		Logger:               l,
 		Tracer:               t,
@@ -379,15 +417,7 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		Reg:                  reg,
 		MinStability:         fr.minStability,
 		EnableCommunityComps: fr.enableCommunityComps,
-		Services: []service.Service{
-			clusterService,
-			httpService,
-			labelService,
-			liveDebuggingService,
-			otelService,
-			remoteCfgService,
-			uiService,
-		},
+		Services:             services,
 		TaskShutdownDeadline: fr.taskShutdownDeadline,
 	})
 
@@ -477,11 +507,13 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		case <-ctx.Done():
 			return nil
 		case <-reloadSignal:
-			if _, err := reload(); err != nil {
-				level.Error(l).Log("msg", "failed to reload config", "err", err)
-			} else {
-				level.Info(l).Log("msg", "config reloaded")
-			}
+		case <-fr.reloadTrigger:
+		}
+
+		if _, err := reload(); err != nil {
+			level.Error(l).Log("msg", "failed to reload config", "err", err)
+		} else {
+			level.Info(l).Log("msg", "config reloaded")
 		}
 	}
 }
@@ -3,6 +3,7 @@ package alloycli
 import (
 	"fmt"
 
+	lokiwrite "github.com/grafana/alloy/internal/component/loki/write"
 	"github.com/grafana/alloy/internal/component/prometheus/remotewrite"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ func toolsCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		getTools("loki.write", lokiwrite.InstallTools),
 		getTools("prometheus.remote_write", remotewrite.InstallTools),
 	)
 
@@ -3,6 +3,8 @@ package client
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/go-kit/log"
@@ -242,3 +244,20 @@ func (c *walEndpointAdapter) Stop() {
 	c.endpoint.stop()
 	c.markerHandler.Stop()
 }
+
+// LastMarkedSegment returns the last segment of the WAL in walDir whose
+// entries were all sent to the endpoints, or -1 if no segment was marked yet.
+func LastMarkedSegment(walDir string) (int, error) {
+	bs, err := os.ReadFile(filepath.Join(walDir, internal.MarkerFolderName, internal.MarkerFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	segment, err := internal.DecodeMarkerV1(bs)
+	if err != nil {
+		return -1, fmt.Errorf("could not decode segment marker file: %w", err)
+	}
+	return int(segment), nil
+}
@@ -0,0 +1,78 @@
+package wal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+)
+
+// ReadEntries reads the WAL in dir from its first to its last segment, and
+// calls fn for each entry in the order they were written. Entries which
+// reference a series that isn't in the WAL anymore can't be resolved to a
+// stream; they are skipped and their number is returned.
+//
+// ReadEntries is meant for inspecting a WAL which isn't being written to.
+func ReadEntries(dir string, fn func(segment int, entry loki.Entry) error) (int, error) {
+	first, last, err := wlog.Segments(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list WAL segments: %w", err)
+	}
+
+	var (
+		series      = make(map[chunks.HeadSeriesRef]model.LabelSet)
+		invalidRefs int
+	)
+	for segment := first; segment >= 0 && segment <= last; segment++ {
+		s, err := wlog.OpenReadSegment(wlog.SegmentName(dir, segment))
+		if err != nil {
+			return invalidRefs, err
+		}
+		sr := wlog.NewSegmentBufReader(s)
+		r := wlog.NewReader(sr)
+
+		for r.Next() {
+			rec := &Record{}
+			if err := DecodeRecord(r.Record(), rec); err != nil {
+				_ = sr.Close()
+				return invalidRefs, fmt.Errorf("failed to decode record in segment %d: %w", segment, err)
+			}
+
+			for _, s := range rec.Series {
+				series[s.Ref] = labelsToLabelSet(s.Labels)
+			}
+			for _, refEntries := range rec.RefEntries {
+				lbls, ok := series[refEntries.Ref]
+				if !ok {
+					invalidRefs += len(refEntries.Entries)
+					continue
+				}
+				for _, e := range refEntries.Entries {
+					if err := fn(segment, loki.Entry{Labels: lbls, Entry: e}); err != nil {
+						_ = sr.Close()
+						return invalidRefs, err
+					}
+				}
+			}
+		}
+		_ = sr.Close()
+		if err := r.Err(); err != nil {
+			return invalidRefs, fmt.Errorf("failed to read segment %d: %w", segment, err)
+		}
+	}
+	return invalidRefs, nil
+}
+
+func labelsToLabelSet(lbls labels.Labels) model.LabelSet {
+	ls := make(model.LabelSet, lbls.Len())
+	lbls.Range(func(l labels.Label) {
+		// The decoded labels reference the buffer of the reader.
+		ls[model.LabelName(strings.Clone(l.Name))] = model.LabelValue(strings.Clone(l.Value))
+	})
+	return ls
+}
@@ -0,0 +1,283 @@
+// Package signature contains an Alloy-serializable definition of the
+// signature verification settings for configuration fetched from remote
+// sources, and a Verifier which applies them.
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// HTTPHeader is the HTTP response header which holds the detached signature
+// of a configuration.
+const HTTPHeader = "Alloy-Signature"
+
+// FileSuffix is the suffix of the file which holds the detached signature of
+// a configuration file.
+const FileSuffix = ".sig"
+
+var (
+	// ErrUnsigned is returned when a configuration doesn't have a signature.
+	ErrUnsigned = errors.New("configuration is not signed")
+	// ErrInvalidSignature is returned when the signature of a configuration
+	// doesn't match any of the trusted keys.
+	ErrInvalidSignature = errors.New("configuration signature is invalid")
+)
+
+// jwsAlgorithms are the JWS signature algorithms which are accepted.
+var jwsAlgorithms = []jose.SignatureAlgorithm{
+	jose.EdDSA,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+}
+
+// Format is the format of signatures.
+type Format string
+
+const (
+	// FormatDetached is a base64-encoded signature of the configuration,
+	// delivered next to it.
+	FormatDetached Format = "detached"
+	// FormatJWS is a JWS in compact serialization, whose payload is the
+	// configuration.
+	FormatJWS Format = "jws"
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *Format) UnmarshalText(text []byte) error {
+	switch Format(text) {
+	case FormatDetached, FormatJWS:
+		*f = Format(text)
+		return nil
+	default:
+		return fmt.Errorf("unknown signature format %q, must be one of %q or %q", string(text), FormatDetached, FormatJWS)
+	}
+}
+
+// Arguments configures the verification of the signature of configuration.
+type Arguments struct {
+	Format         Format   `alloy:"format,attr,optional"`
+	PublicKeys     []string `alloy:"public_keys,attr,optional"`
+	PublicKeyFiles []string `alloy:"public_key_files,attr,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Format: FormatDetached,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if len(args.PublicKeys) == 0 && len(args.PublicKeyFiles) == 0 {
+		return fmt.Errorf("at least one of public_keys or public_key_files must be set")
+	}
+	for i, key := range args.PublicKeys {
+		if _, err := parseKeys([]byte(key)); err != nil {
+			return fmt.Errorf("invalid public_keys[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Verifier verifies the signature of configuration against a set of trusted
+// keys.
+type Verifier struct {
+	format Format
+	keys   []trustedKey
+}
+
+// trustedKey is a public key, and the certificate it comes from if any.
+type trustedKey struct {
+	pub  crypto.PublicKey
+	cert *x509.Certificate
+}
+
+// NewVerifier creates a Verifier from args. The key files are read once, so
+// a new Verifier must be created to pick up changes to them.
+func NewVerifier(args Arguments) (*Verifier, error) {
+	v := &Verifier{format: args.Format}
+	if v.format == "" {
+		v.format = FormatDetached
+	}
+
+	for i, key := range args.PublicKeys {
+		keys, err := parseKeys([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public_keys[%d]: %w", i, err)
+		}
+		v.keys = append(v.keys, keys...)
+	}
+	for _, path := range args.PublicKeyFiles {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key file: %w", err)
+		}
+		keys, err := parseKeys(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key file %q: %w", path, err)
+		}
+		v.keys = append(v.keys, keys...)
+	}
+
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no public keys configured")
+	}
+	return v, nil
+}
+
+// Format returns the format of the signatures checked by v.
+func (v *Verifier) Format() Format {
+	return v.format
+}
+
+// Verify checks that content was signed by one of the trusted keys, and
+// returns the verified configuration.
+//
+// With the detached format, sig is the base64-encoded signature of content,
+// and content is returned as is. With the JWS format, content is the JWS and
+// its payload is returned; sig is ignored.
+func (v *Verifier) Verify(content []byte, sig string) ([]byte, error) {
+	switch v.format {
+	case FormatJWS:
+		return v.verifyJWS(content)
+	default:
+		return content, v.verifyDetached(content, sig)
+	}
+}
+
+func (v *Verifier) verifyDetached(content []byte, sig string) error {
+	sig = strings.TrimSpace(sig)
+	if sig == "" {
+		return ErrUnsigned
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode signature: %w", ErrInvalidSignature, err)
+	}
+
+	digest := sha256.Sum256(content)
+	now := time.Now()
+	for _, k := range v.keys {
+		if !k.valid(now) {
+			continue
+		}
+
+		var ok bool
+		switch pub := k.pub.(type) {
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(pub, content, raw)
+		case *ecdsa.PublicKey:
+			ok = ecdsa.VerifyASN1(pub, digest[:], raw)
+		case *rsa.PublicKey:
+			ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], raw) == nil
+		}
+		if ok {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func (v *Verifier) verifyJWS(content []byte) ([]byte, error) {
+	compact := strings.TrimSpace(string(content))
+	if compact == "" {
+		return nil, ErrUnsigned
+	}
+	obj, err := jose.ParseSigned(compact, jwsAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JWS: %w", ErrInvalidSignature, err)
+	}
+
+	now := time.Now()
+	for _, k := range v.keys {
+		if !k.valid(now) {
+			continue
+		}
+		if payload, err := obj.Verify(k.pub); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, ErrInvalidSignature
+}
+
+// valid returns whether the key can be used at the given time. Keys which
+// come from a certificate can only be used within its validity period.
+func (k trustedKey) valid(now time.Time) bool {
+	if k.cert == nil {
+		return true
+	}
+	return !now.Before(k.cert.NotBefore) && !now.After(k.cert.NotAfter)
+}
+
+// parseKeys parses the PEM-encoded public keys and X.509 certificates in b.
+func parseKeys(b []byte) ([]trustedKey, error) {
+	var keys []trustedKey
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkKeyType(pub); err != nil {
+				return nil, err
+			}
+			keys = append(keys, trustedKey{pub: pub})
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkKeyType(cert.PublicKey); err != nil {
+				return nil, err
+			}
+			keys = append(keys, trustedKey{pub: cert.PublicKey, cert: cert})
+		default:
+			return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no PEM-encoded public key or certificate found")
+	}
+	return keys, nil
+}
+
+func checkKeyType(pub crypto.PublicKey) error {
+	switch pub.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,143 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/syntax"
+)
+
+var testConfig = []byte(`logging { level = "debug" }`)
+
+func TestVerifier_Detached_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(Arguments{Format: FormatDetached, PublicKeys: []string{publicKeyPEM(t, pub)}})
+	require.NoError(t, err)
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, testConfig))
+	content, err := v.Verify(testConfig, sig)
+	require.NoError(t, err)
+	require.Equal(t, testConfig, content)
+
+	_, err = v.Verify([]byte(`logging { level = "info" }`), sig)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = v.Verify(testConfig, "")
+	require.ErrorIs(t, err, ErrUnsigned)
+}
+
+func TestVerifier_Detached_Certificate(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(testConfig)
+	raw, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	sig := base64.StdEncoding.EncodeToString(raw)
+
+	// Keys can be loaded from files.
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	cert := certificatePEM(t, priv, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, os.WriteFile(path, []byte(cert), 0o644))
+
+	v, err := NewVerifier(Arguments{PublicKeyFiles: []string{path}})
+	require.NoError(t, err)
+	_, err = v.Verify(testConfig, sig)
+	require.NoError(t, err)
+
+	// Expired certificates aren't trusted.
+	expired := certificatePEM(t, priv, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	v, err = NewVerifier(Arguments{PublicKeys: []string{expired}})
+	require.NoError(t, err)
+	_, err = v.Verify(testConfig, sig)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifier_JWS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.EdDSA, Key: priv}, nil)
+	require.NoError(t, err)
+	obj, err := signer.Sign(testConfig)
+	require.NoError(t, err)
+	compact, err := obj.CompactSerialize()
+	require.NoError(t, err)
+
+	v, err := NewVerifier(Arguments{Format: FormatJWS, PublicKeys: []string{publicKeyPEM(t, pub)}})
+	require.NoError(t, err)
+
+	content, err := v.Verify([]byte(compact), "")
+	require.NoError(t, err)
+	require.Equal(t, testConfig, content)
+
+	// Unsigned configuration isn't a valid JWS.
+	_, err = v.Verify(testConfig, "")
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// JWS signed with another key is rejected.
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	v, err = NewVerifier(Arguments{Format: FormatJWS, PublicKeys: []string{publicKeyPEM(t, otherPub)}})
+	require.NoError(t, err)
+	_, err = v.Verify([]byte(compact), "")
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestArguments_Unmarshal(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var args Arguments
+	err = syntax.Unmarshal([]byte(fmt.Sprintf("format = \"jws\"\npublic_keys = [%q]", publicKeyPEM(t, pub))), &args)
+	require.NoError(t, err)
+	require.Equal(t, FormatJWS, args.Format)
+
+	err = syntax.Unmarshal([]byte(`format = "jws"`), &args)
+	require.EqualError(t, err, "at least one of public_keys or public_key_files must be set")
+
+	err = syntax.Unmarshal([]byte(`format = "pgp"
+public_key_files = ["key.pem"]`), &args)
+	require.ErrorContains(t, err, `unknown signature format "pgp"`)
+
+	err = syntax.Unmarshal([]byte(`public_keys = ["not a key"]`), &args)
+	require.ErrorContains(t, err, "invalid public_keys[0]")
+}
+
+func publicKeyPEM(t *testing.T, pub any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func certificatePEM(t *testing.T, priv *ecdsa.PrivateKey, notBefore, notAfter time.Time) string {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "release-pipeline"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,250 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/database_observability"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	LocksCollector = "locks"
+	OP_DATA_LOCKS  = "query_data_locks"
+)
+
+const insufficientPrivilege = "<insufficient privilege>"
+
+// selectDataLocks returns a row for each lock a backend is waiting for,
+// paired with each backend holding a conflicting lock on the same object.
+const selectDataLocks = `
+	SELECT
+		clock_timestamp() as now,
+		d.datname,
+		blocked_locks.locktype,
+		blocked_activity.pid as blocked_pid,
+		blocked_locks.mode as blocked_mode,
+		blocked_activity.state_change as blocked_state_change,
+		blocked_activity.xact_start as blocked_xact_start,
+		blocked_activity.query_id as blocked_query_id,
+		blocked_activity.query as blocked_query,
+		blocking_activity.pid as blocking_pid,
+		blocking_locks.mode as blocking_mode,
+		blocking_activity.state as blocking_state,
+		blocking_activity.xact_start as blocking_xact_start,
+		blocking_activity.query_id as blocking_query_id,
+		blocking_activity.query as blocking_query
+	FROM pg_locks blocked_locks
+		JOIN pg_stat_activity blocked_activity ON blocked_activity.pid = blocked_locks.pid
+		JOIN pg_locks blocking_locks
+			ON blocking_locks.locktype = blocked_locks.locktype
+			AND blocking_locks.database IS NOT DISTINCT FROM blocked_locks.database
+			AND blocking_locks.relation IS NOT DISTINCT FROM blocked_locks.relation
+			AND blocking_locks.page IS NOT DISTINCT FROM blocked_locks.page
+			AND blocking_locks.tuple IS NOT DISTINCT FROM blocked_locks.tuple
+			AND blocking_locks.virtualxid IS NOT DISTINCT FROM blocked_locks.virtualxid
+			AND blocking_locks.transactionid IS NOT DISTINCT FROM blocked_locks.transactionid
+			AND blocking_locks.classid IS NOT DISTINCT FROM blocked_locks.classid
+			AND blocking_locks.objid IS NOT DISTINCT FROM blocked_locks.objid
+			AND blocking_locks.objsubid IS NOT DISTINCT FROM blocked_locks.objsubid
+			AND blocking_locks.pid != blocked_locks.pid
+		JOIN pg_stat_activity blocking_activity ON blocking_activity.pid = blocking_locks.pid
+		JOIN pg_database d ON blocked_activity.datid = d.oid
+	WHERE
+		NOT blocked_locks.granted
+		AND blocking_locks.granted
+		AND blocking_locks.pid = ANY(pg_blocking_pids(blocked_locks.pid))
+		AND d.datname NOT IN %s
+`
+
+type LocksArguments struct {
+	DB                *sql.DB
+	CollectInterval   time.Duration
+	LockWaitThreshold time.Duration
+	ExcludeDatabases  []string
+	EntryHandler      loki.EntryHandler
+
+	Logger log.Logger
+}
+
+type Locks struct {
+	dbConnection     *sql.DB
+	collectInterval  time.Duration
+	excludeDatabases []string
+	entryHandler     loki.EntryHandler
+	logger           log.Logger
+
+	// The minimum amount of time elapsed waiting due to a lock
+	// to be selected for scrape
+	lockWaitThreshold time.Duration
+	running           *atomic.Bool
+	ctx               context.Context
+	cancel            context.CancelFunc
+}
+
+type lockInfo struct {
+	Now                time.Time
+	DatabaseName       sql.NullString
+	LockType           sql.NullString
+	BlockedPID         int
+	BlockedMode        sql.NullString
+	BlockedStateChange sql.NullTime
+	BlockedXactStart   sql.NullTime
+	BlockedQueryID     sql.NullInt64
+	BlockedQuery       sql.NullString
+	BlockingPID        int
+	BlockingMode       sql.NullString
+	BlockingState      sql.NullString
+	BlockingXactStart  sql.NullTime
+	BlockingQueryID    sql.NullInt64
+	BlockingQuery      sql.NullString
+}
+
+func NewLocks(args LocksArguments) (*Locks, error) {
+	if args.DB == nil {
+		return nil, errors.New("nil DB connection")
+	}
+
+	return &Locks{
+		dbConnection:      args.DB,
+		collectInterval:   args.CollectInterval,
+		excludeDatabases:  args.ExcludeDatabases,
+		lockWaitThreshold: args.LockWaitThreshold,
+		entryHandler:      args.EntryHandler,
+		logger:            log.With(args.Logger, "collector", LocksCollector),
+		running:           &atomic.Bool{},
+	}, nil
+}
+
+func (c *Locks) Name() string {
+	return LocksCollector
+}
+
+func (c *Locks) Start(ctx context.Context) error {
+	level.Debug(c.logger).Log("msg", "collector started")
+
+	c.running.Store(true)
+	ctx, cancel := context.WithCancel(ctx)
+	c.ctx = ctx
+	c.cancel = cancel
+
+	go func() {
+		defer func() {
+			c.Stop()
+			c.running.Store(false)
+		}()
+
+		ticker := time.NewTicker(c.collectInterval)
+
+		for {
+			if err := c.fetchLocks(c.ctx); err != nil {
+				level.Error(c.logger).Log("msg", "collector error", "err", err)
+			}
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+				// continue loop
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (c *Locks) Stopped() bool {
+	return !c.running.Load()
+}
+
+// Stop should be kept idempotent
+func (c *Locks) Stop() {
+	c.cancel()
+}
+
+func (c *Locks) fetchLocks(ctx context.Context) error {
+	query := fmt.Sprintf(selectDataLocks, buildExcludedDatabasesClause(c.excludeDatabases))
+	rows, err := c.dbConnection.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query pg_locks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lock lockInfo
+		err := rows.Scan(
+			&lock.Now,
+			&lock.DatabaseName,
+			&lock.LockType,
+			&lock.BlockedPID,
+			&lock.BlockedMode,
+			&lock.BlockedStateChange,
+			&lock.BlockedXactStart,
+			&lock.BlockedQueryID,
+			&lock.BlockedQuery,
+			&lock.BlockingPID,
+			&lock.BlockingMode,
+			&lock.BlockingState,
+			&lock.BlockingXactStart,
+			&lock.BlockingQueryID,
+			&lock.BlockingQuery,
+		)
+		if err != nil {
+			level.Error(c.logger).Log("msg", "failed to scan pg_locks", "err", err)
+			continue
+		}
+
+		// only log if the blocked query waited longer than the threshold
+		if lock.BlockedStateChange.Valid && lock.Now.Sub(lock.BlockedStateChange.Time) < c.lockWaitThreshold {
+			continue
+		}
+
+		lockMsg := fmt.Sprintf(
+			`datname="%s" locktype="%s" blocked_pid="%d" blocked_mode="%s" blocked_wait_time="%s" blocked_xact_time="%s" blocked_queryid="%d" blocked_query="%s" blocking_pid="%d" blocking_mode="%s" blocking_state="%s" blocking_xact_time="%s" blocking_queryid="%d" blocking_query="%s"`,
+			lock.DatabaseName.String,
+			lock.LockType.String,
+			lock.BlockedPID,
+			lock.BlockedMode.String,
+			calculateDuration(lock.BlockedStateChange, lock.Now),
+			calculateDuration(lock.BlockedXactStart, lock.Now),
+			lock.BlockedQueryID.Int64,
+			redactLockQuery(lock.BlockedQuery),
+			lock.BlockingPID,
+			lock.BlockingMode.String,
+			lock.BlockingState.String,
+			calculateDuration(lock.BlockingXactStart, lock.Now),
+			lock.BlockingQueryID.Int64,
+			redactLockQuery(lock.BlockingQuery),
+		)
+
+		c.entryHandler.Chan() <- database_observability.BuildLokiEntryWithTimestamp(
+			logging.LevelInfo,
+			OP_DATA_LOCKS,
+			lockMsg,
+			lock.Now.UnixNano(),
+		)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate over locks result set: %w", err)
+	}
+
+	return nil
+}
+
+// redactLockQuery replaces the literals of the query text reported by
+// pg_stat_activity, as it includes the parameters of the query.
+func redactLockQuery(query sql.NullString) string {
+	if !query.Valid || query.String == insufficientPrivilege {
+		return query.String
+	}
+	return database_observability.RedactSql(query.String)
+}
@@ -0,0 +1,258 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/database_observability"
+)
+
+var locksColumns = []string{
+	"now",
+	"datname",
+	"locktype",
+	"blocked_pid",
+	"blocked_mode",
+	"blocked_state_change",
+	"blocked_xact_start",
+	"blocked_query_id",
+	"blocked_query",
+	"blocking_pid",
+	"blocking_mode",
+	"blocking_state",
+	"blocking_xact_start",
+	"blocking_query_id",
+	"blocking_query",
+}
+
+func TestLocks(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	now := time.Now()
+
+	t.Run("no logs with no lock events", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		lokiClient := loki.NewCollectingHandler()
+
+		collector, err := NewLocks(LocksArguments{
+			DB:              db,
+			CollectInterval: 10 * time.Second,
+			EntryHandler:    lokiClient,
+			Logger:          log.NewLogfmtLogger(os.Stderr),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).RowsWillBeClosed().WillReturnRows(
+			sqlmock.NewRows(locksColumns),
+		)
+
+		require.NoError(t, collector.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return mock.ExpectationsWereMet() == nil
+		}, 2*time.Second, 50*time.Millisecond)
+
+		collector.Stop()
+		lokiClient.Stop()
+
+		assert.Empty(t, lokiClient.Received(), "Expected no log entries for no lock events")
+	})
+
+	t.Run("blocked and blocking queries are logged redacted", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		lokiClient := loki.NewCollectingHandler()
+
+		collector, err := NewLocks(LocksArguments{
+			DB:                db,
+			CollectInterval:   time.Second,
+			LockWaitThreshold: time.Second,
+			EntryHandler:      lokiClient,
+			Logger:            log.NewLogfmtLogger(os.Stderr),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).RowsWillBeClosed().WillReturnRows(
+			sqlmock.NewRows(locksColumns).AddRow(
+				now,
+				"books_store",
+				"transactionid",
+				200,
+				"ShareLock",
+				now.Add(-5*time.Second),
+				now.Add(-6*time.Second),
+				123,
+				"UPDATE accounts SET balance = balance - 100 WHERE id = 42",
+				100,
+				"ExclusiveLock",
+				"idle in transaction",
+				now.Add(-1*time.Minute),
+				456,
+				"SELECT * FROM accounts WHERE id = 42 FOR UPDATE",
+			),
+		)
+
+		require.NoError(t, collector.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return len(lokiClient.Received()) == 1
+		}, 2*time.Second, 50*time.Millisecond)
+
+		collector.Stop()
+		lokiClient.Stop()
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		lokiEntries := lokiClient.Received()
+		assert.Equal(t, model.LabelSet{"op": OP_DATA_LOCKS}, lokiEntries[0].Labels)
+		assert.Equal(t, now.UnixNano(), lokiEntries[0].Timestamp.UnixNano())
+		assert.Equal(t, `level="info" datname="books_store" locktype="transactionid" blocked_pid="200" blocked_mode="ShareLock" blocked_wait_time="5s" blocked_xact_time="6s" blocked_queryid="123" blocked_query="UPDATE accounts SET balance = balance - ? WHERE id = ?" blocking_pid="100" blocking_mode="ExclusiveLock" blocking_state="idle in transaction" blocking_xact_time="1m0s" blocking_queryid="456" blocking_query="SELECT * FROM accounts WHERE id = ? FOR UPDATE"`, lokiEntries[0].Line)
+	})
+
+	t.Run("locks below the threshold are not logged", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		lokiClient := loki.NewCollectingHandler()
+
+		collector, err := NewLocks(LocksArguments{
+			DB:                db,
+			CollectInterval:   time.Second,
+			LockWaitThreshold: 10 * time.Second,
+			EntryHandler:      lokiClient,
+			Logger:            log.NewLogfmtLogger(os.Stderr),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).RowsWillBeClosed().WillReturnRows(
+			sqlmock.NewRows(locksColumns).AddRow(
+				now, "books_store", "relation", 200, "AccessExclusiveLock", now.Add(-5*time.Second), now.Add(-5*time.Second),
+				123, "ALTER TABLE books ADD COLUMN isbn text", 100, "RowExclusiveLock", "active", now.Add(-8*time.Second),
+				456, "INSERT INTO books (title) VALUES ('Dune')",
+			).AddRow(
+				now, "books_store", "tuple", 300, "ExclusiveLock", now.Add(-20*time.Second), now.Add(-20*time.Second),
+				789, "DELETE FROM books WHERE id = 7", 100, "RowExclusiveLock", "active", now.Add(-30*time.Second),
+				456, "INSERT INTO books (title) VALUES ('Dune')",
+			),
+		)
+
+		require.NoError(t, collector.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return len(lokiClient.Received()) == 1
+		}, 2*time.Second, 50*time.Millisecond)
+
+		collector.Stop()
+		lokiClient.Stop()
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		lokiEntries := lokiClient.Received()
+		require.Len(t, lokiEntries, 1)
+		assert.Equal(t, `level="info" datname="books_store" locktype="tuple" blocked_pid="300" blocked_mode="ExclusiveLock" blocked_wait_time="20s" blocked_xact_time="20s" blocked_queryid="789" blocked_query="DELETE FROM books WHERE id = ?" blocking_pid="100" blocking_mode="RowExclusiveLock" blocking_state="active" blocking_xact_time="30s" blocking_queryid="456" blocking_query="INSERT INTO books (title) VALUES (?)"`, lokiEntries[0].Line)
+	})
+
+	t.Run("lock with null values and insufficient privilege", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		lokiClient := loki.NewCollectingHandler()
+
+		collector, err := NewLocks(LocksArguments{
+			DB:               db,
+			CollectInterval:  time.Second,
+			ExcludeDatabases: []string{"other_db"},
+			EntryHandler:     lokiClient,
+			Logger:           log.NewLogfmtLogger(os.Stderr),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, database_observability.BuildExclusionClause([]string{"azure_maintenance", "other_db"}))).RowsWillBeClosed().WillReturnRows(
+			sqlmock.NewRows(locksColumns).AddRow(
+				now, "books_store", "advisory", 200, "ExclusiveLock", nil, nil,
+				nil, nil, 100, "ExclusiveLock", nil, nil,
+				nil, "<insufficient privilege>",
+			),
+		)
+
+		require.NoError(t, collector.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return len(lokiClient.Received()) == 1
+		}, 2*time.Second, 50*time.Millisecond)
+
+		collector.Stop()
+		lokiClient.Stop()
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		lokiEntries := lokiClient.Received()
+		assert.Equal(t, `level="info" datname="books_store" locktype="advisory" blocked_pid="200" blocked_mode="ExclusiveLock" blocked_wait_time="" blocked_xact_time="" blocked_queryid="0" blocked_query="" blocking_pid="100" blocking_mode="ExclusiveLock" blocking_state="" blocking_xact_time="" blocking_queryid="0" blocking_query="<insufficient privilege>"`, lokiEntries[0].Line)
+	})
+
+	t.Run("recoverable sql error", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		defer db.Close()
+
+		lokiClient := loki.NewCollectingHandler()
+
+		collector, err := NewLocks(LocksArguments{
+			DB:              db,
+			CollectInterval: 10 * time.Millisecond,
+			EntryHandler:    lokiClient,
+			Logger:          log.NewLogfmtLogger(os.Stderr),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, collector)
+
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).WillReturnError(fmt.Errorf("some error"))
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).RowsWillBeClosed().WillReturnRows( // first row has too few columns
+			sqlmock.NewRows([]string{"now"}).AddRow(now),
+		)
+		mock.ExpectQuery(fmt.Sprintf(selectDataLocks, exclusionClause)).RowsWillBeClosed().WillReturnRows(
+			sqlmock.NewRows(locksColumns).AddRow(
+				now, "books_store", "relation", 200, "AccessExclusiveLock", now.Add(-2*time.Second), now.Add(-2*time.Second),
+				123, "TRUNCATE books", 100, "AccessShareLock", "active", now.Add(-3*time.Second),
+				456, "SELECT count(*) FROM books",
+			),
+		)
+
+		require.NoError(t, collector.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return len(lokiClient.Received()) == 1
+		}, 2*time.Second, 10*time.Millisecond)
+
+		collector.Stop()
+		lokiClient.Stop()
+
+		require.NoError(t, mock.ExpectationsWereMet())
+		lokiEntries := lokiClient.Received()
+		require.Len(t, lokiEntries, 1)
+		assert.Equal(t, `level="info" datname="books_store" locktype="relation" blocked_pid="200" blocked_mode="AccessExclusiveLock" blocked_wait_time="2s" blocked_xact_time="2s" blocked_queryid="123" blocked_query="TRUNCATE books" blocking_pid="100" blocking_mode="AccessShareLock" blocking_state="active" blocking_xact_time="3s" blocking_queryid="456" blocking_query="SELECT count(*) FROM books"`, lokiEntries[0].Line)
+	})
+}
+
+func TestNewLocks_NilDB(t *testing.T) {
+	_, err := NewLocks(LocksArguments{})
+	require.EqualError(t, err, "nil DB connection")
+}
@@ -73,6 +73,7 @@ type Arguments struct {
 	SchemaDetailsArguments SchemaDetailsArguments `alloy:"schema_details,block,optional"`
 	ExplainPlansArguments  ExplainPlansArguments  `alloy:"explain_plans,block,optional"`
 	HealthCheckArguments   HealthCheckArguments   `alloy:"health_check,block,optional"`
+	LocksArguments         LocksArguments         `alloy:"locks,block,optional"`
 }
 
 type CloudProvider struct {
@@ -130,6 +131,10 @@ var DefaultArguments = Arguments{
 	HealthCheckArguments: HealthCheckArguments{
 		CollectInterval: 1 * time.Hour,
 	},
+	LocksArguments: LocksArguments{
+		CollectInterval: 30 * time.Second,
+		Threshold:       1 * time.Second,
+	},
 }
 
 type ExplainPlansArguments struct {
@@ -141,6 +146,11 @@ type HealthCheckArguments struct {
 	CollectInterval time.Duration `alloy:"collect_interval,attr,optional"`
 }
 
+type LocksArguments struct {
+	CollectInterval time.Duration `alloy:"collect_interval,attr,optional"`
+	Threshold       time.Duration `alloy:"threshold,attr,optional"`
+}
+
 func (a *Arguments) SetToDefault() {
 	*a = DefaultArguments
 }
@@ -402,6 +412,7 @@ func enableOrDisableCollectors(a Arguments) map[string]bool {
 		collector.QuerySamplesCollector:  true,
 		collector.SchemaDetailsCollector: true,
 		collector.ExplainPlanCollector:   true,
+		collector.LocksCollector:         false,
 	}
 
 	for _, disabled := range a.DisableCollectors {
@@ -528,6 +539,25 @@ func (c *Component) startCollectors(systemID string, engineVersion string, cloud
 		c.collectors = append(c.collectors, epCollector)
 	}
 
+	if collectors[collector.LocksCollector] {
+		locksCollector, err := collector.NewLocks(collector.LocksArguments{
+			DB:                c.dbConnection,
+			CollectInterval:   c.args.LocksArguments.CollectInterval,
+			LockWaitThreshold: c.args.LocksArguments.Threshold,
+			ExcludeDatabases:  c.args.ExcludeDatabases,
+			EntryHandler:      entryHandler,
+			Logger:            c.opts.Logger,
+		})
+		if err != nil {
+			logStartError(collector.LocksCollector, "create", err)
+		} else {
+			if err := locksCollector.Start(context.Background()); err != nil {
+				logStartError(collector.LocksCollector, "start", err)
+			}
+			c.collectors = append(c.collectors, locksCollector)
+		}
+	}
+
 	// HealthCheck collector is always enabled
 	hcCollector, err := collector.NewHealthCheck(collector.HealthCheckArguments{
 		DB:              c.dbConnection,
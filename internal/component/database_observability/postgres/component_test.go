@@ -44,6 +44,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -66,6 +67,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -88,6 +90,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -111,6 +114,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -134,6 +138,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -156,6 +161,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -178,6 +184,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 
@@ -200,6 +207,30 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  true,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
+		}, actualCollectors)
+	})
+
+	t.Run("enable locks collector", func(t *testing.T) {
+		exampleDBO11yAlloyConfig := `
+		data_source_name = "postgres://db"
+		forward_to = []
+		targets = []
+		enable_collectors = ["locks"]
+	`
+
+		var args Arguments
+		err := syntax.Unmarshal([]byte(exampleDBO11yAlloyConfig), &args)
+		require.NoError(t, err)
+
+		actualCollectors := enableOrDisableCollectors(args)
+
+		assert.Equal(t, map[string]bool{
+			collector.QueryDetailsCollector:  true,
+			collector.QuerySamplesCollector:  true,
+			collector.SchemaDetailsCollector: true,
+			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         true,
 		}, actualCollectors)
 	})
 
@@ -222,6 +253,7 @@ func Test_enableOrDisableCollectors(t *testing.T) {
 			collector.QuerySamplesCollector:  false,
 			collector.SchemaDetailsCollector: true,
 			collector.ExplainPlanCollector:   true,
+			collector.LocksCollector:         false,
 		}, actualCollectors)
 	})
 }
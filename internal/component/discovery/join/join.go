@@ -0,0 +1,293 @@
+package join
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "discovery.join",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the discovery.join
+// component.
+type Arguments struct {
+	// Targets contains the targets to enrich.
+	Targets []discovery.Target `alloy:"targets,attr"`
+
+	// JoinTargets contains the targets to copy labels from.
+	JoinTargets []discovery.Target `alloy:"join_targets,attr"`
+
+	// The labels of Targets and JoinTargets which must have the same values
+	// for two targets to match. JoinMatchLabels defaults to TargetMatchLabels.
+	TargetMatchLabels []string `alloy:"target_match_labels,attr"`
+	JoinMatchLabels   []string `alloy:"join_match_labels,attr,optional"`
+
+	// Type determines what happens to targets without a match.
+	Type JoinType `alloy:"join_type,attr,optional"`
+
+	// LabelsToCopy lists the labels copied from the matching join target. If
+	// empty, all the labels which aren't reserved are copied.
+	LabelsToCopy []string `alloy:"labels_to_copy,attr,optional"`
+
+	// LabelPrefix is prepended to the names of the copied labels.
+	LabelPrefix string `alloy:"label_prefix,attr,optional"`
+
+	// OnConflict determines which value is kept when a copied label is already
+	// set on the target.
+	OnConflict ConflictStrategy `alloy:"on_conflict,attr,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Type:       JoinTypeLeft,
+	OnConflict: ConflictStrategyKeep,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if len(args.TargetMatchLabels) == 0 {
+		return fmt.Errorf("target_match_labels must not be empty")
+	}
+	if len(args.JoinMatchLabels) > 0 && len(args.JoinMatchLabels) != len(args.TargetMatchLabels) {
+		return fmt.Errorf("join_match_labels must have the same number of labels as target_match_labels")
+	}
+	return nil
+}
+
+func (args *Arguments) joinMatchLabels() []string {
+	if len(args.JoinMatchLabels) > 0 {
+		return args.JoinMatchLabels
+	}
+	return args.TargetMatchLabels
+}
+
+// JoinType is the type of join performed by the component.
+type JoinType string
+
+const (
+	// JoinTypeInner only keeps targets which match a join target.
+	JoinTypeInner JoinType = "inner"
+	// JoinTypeLeft keeps all targets, whether they match a join target or not.
+	JoinTypeLeft JoinType = "left"
+)
+
+var _ encoding.TextUnmarshaler = (*JoinType)(nil)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *JoinType) UnmarshalText(text []byte) error {
+	switch JoinType(text) {
+	case JoinTypeInner, JoinTypeLeft:
+		*t = JoinType(text)
+		return nil
+	default:
+		return fmt.Errorf("unknown join_type %q, must be one of %q or %q", string(text), JoinTypeInner, JoinTypeLeft)
+	}
+}
+
+// ConflictStrategy determines which value is kept when a label copied from a
+// join target is already set on a target.
+type ConflictStrategy string
+
+const (
+	// ConflictStrategyKeep keeps the value of the target.
+	ConflictStrategyKeep ConflictStrategy = "keep"
+	// ConflictStrategyReplace replaces the value of the target with the value
+	// of the join target.
+	ConflictStrategyReplace ConflictStrategy = "replace"
+)
+
+var _ encoding.TextUnmarshaler = (*ConflictStrategy)(nil)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ConflictStrategy) UnmarshalText(text []byte) error {
+	switch ConflictStrategy(text) {
+	case ConflictStrategyKeep, ConflictStrategyReplace:
+		*s = ConflictStrategy(text)
+		return nil
+	default:
+		return fmt.Errorf("unknown on_conflict %q, must be one of %q or %q", string(text), ConflictStrategyKeep, ConflictStrategyReplace)
+	}
+}
+
+// Exports holds values which are exported by the discovery.join component.
+type Exports struct {
+	Output []discovery.Target `alloy:"output,attr"`
+}
+
+// Component implements the discovery.join component.
+type Component struct {
+	opts component.Options
+
+	mut sync.Mutex
+
+	debugDataPublisher livedebugging.DebugDataPublisher
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new discovery.join component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	debugDataPublisher, err := o.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	c := &Component{
+		opts:               o,
+		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+	}
+
+	// Call to Update() to set the output once at the start
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	newArgs := args.(Arguments)
+	componentID := livedebugging.ComponentID(c.opts.ID)
+
+	output := join(newArgs, func(target, joined discovery.Target) {
+		c.debugDataPublisher.PublishIfActive(livedebugging.NewData(
+			componentID,
+			livedebugging.Target,
+			1,
+			func() string { return fmt.Sprintf("%s => %s", target, joined) },
+		))
+	})
+
+	c.opts.OnStateChange(Exports{
+		Output: output,
+	})
+
+	return nil
+}
+
+func (c *Component) LiveDebugging() {}
+
+// join joins args.Targets with args.JoinTargets. onTarget is called for every
+// target with the resulting target, which is empty if the target was dropped.
+func join(args Arguments, onTarget func(target, joined discovery.Target)) []discovery.Target {
+	// Index the join targets by the values of their match labels. When several
+	// join targets have the same values, the first one is used.
+	index := make(map[string]discovery.Target, len(args.JoinTargets))
+	for _, jt := range args.JoinTargets {
+		key, ok := matchKey(jt, args.joinMatchLabels())
+		if !ok {
+			continue
+		}
+		if _, exists := index[key]; !exists {
+			index[key] = jt
+		}
+	}
+
+	output := make([]discovery.Target, 0, len(args.Targets))
+	for _, t := range args.Targets {
+		var (
+			joined discovery.Target
+			match  discovery.Target
+			found  bool
+		)
+		if key, ok := matchKey(t, args.TargetMatchLabels); ok {
+			match, found = index[key]
+		}
+
+		switch {
+		case found:
+			joined = copyLabels(args, t, match)
+			output = append(output, joined)
+		case args.Type == JoinTypeLeft:
+			joined = t
+			output = append(output, joined)
+		}
+
+		onTarget(t, joined)
+	}
+	return output
+}
+
+// matchKey returns the values of the labels of a target, joined into a single
+// key. It returns false if one of the labels isn't set.
+func matchKey(t discovery.Target, labels []string) (string, bool) {
+	var sb strings.Builder
+	for i, label := range labels {
+		value, ok := t.Get(label)
+		if !ok || value == "" {
+			return "", false
+		}
+		if i > 0 {
+			// Use a separator which can't be part of a label value in practice
+			// so that values can't be combined into the same key.
+			sb.WriteByte(0xff)
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), true
+}
+
+// copyLabels copies the labels of match into a copy of target.
+func copyLabels(args Arguments, target, match discovery.Target) discovery.Target {
+	builder := discovery.NewTargetBuilderFrom(target)
+
+	set := func(name, value string) {
+		name = args.LabelPrefix + name
+		if args.OnConflict == ConflictStrategyKeep && builder.Get(name) != "" {
+			return
+		}
+		builder.Set(name, value)
+	}
+
+	if len(args.LabelsToCopy) > 0 {
+		for _, name := range args.LabelsToCopy {
+			if value, ok := match.Get(name); ok && value != "" {
+				set(name, value)
+			}
+		}
+	} else {
+		match.ForEachLabel(func(name, value string) bool {
+			if !strings.HasPrefix(name, "__") {
+				set(name, value)
+			}
+			return true
+		})
+	}
+
+	return builder.Target()
+}
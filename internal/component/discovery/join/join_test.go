@@ -0,0 +1,167 @@
+package join_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/component/discovery/join"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/syntax"
+)
+
+const testTargets = `
+targets = [
+	{ "__address__" = "10.0.0.1:8080", "__meta_kubernetes_pod_ip" = "10.0.0.1", "pod" = "web-1", "team" = "web" },
+	{ "__address__" = "10.0.0.2:8080", "__meta_kubernetes_pod_ip" = "10.0.0.2", "pod" = "db-1" },
+	{ "__address__" = "10.0.0.3:8080", "__meta_kubernetes_pod_ip" = "10.0.0.3", "pod" = "cache-1" },
+]
+
+join_targets = [
+	{ "__address__" = "cmdb", "ip" = "10.0.0.1", "team" = "payments", "owner" = "alice" },
+	{ "__address__" = "cmdb", "ip" = "10.0.0.2", "team" = "storage",  "owner" = "bob" },
+	{ "__address__" = "cmdb", "ip" = "10.0.0.2", "team" = "ignored",  "owner" = "ignored" },
+]
+
+target_match_labels = ["__meta_kubernetes_pod_ip"]
+join_match_labels   = ["ip"]
+`
+
+func TestJoin(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		config   string
+		expected []discovery.Target
+	}{
+		{
+			name:   "left join",
+			config: testTargets,
+			expected: []discovery.Target{
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.1:8080", "__meta_kubernetes_pod_ip": "10.0.0.1", "pod": "web-1", "team": "web", "ip": "10.0.0.1", "owner": "alice"}),
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.2:8080", "__meta_kubernetes_pod_ip": "10.0.0.2", "pod": "db-1", "team": "storage", "ip": "10.0.0.2", "owner": "bob"}),
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.3:8080", "__meta_kubernetes_pod_ip": "10.0.0.3", "pod": "cache-1"}),
+			},
+		},
+		{
+			name: "inner join with replace",
+			config: testTargets + `
+join_type   = "inner"
+on_conflict = "replace"`,
+			expected: []discovery.Target{
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.1:8080", "__meta_kubernetes_pod_ip": "10.0.0.1", "pod": "web-1", "team": "payments", "ip": "10.0.0.1", "owner": "alice"}),
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.2:8080", "__meta_kubernetes_pod_ip": "10.0.0.2", "pod": "db-1", "team": "storage", "ip": "10.0.0.2", "owner": "bob"}),
+			},
+		},
+		{
+			name: "labels to copy with prefix",
+			config: testTargets + `
+join_type      = "inner"
+labels_to_copy = ["team", "owner"]
+label_prefix   = "cmdb_"`,
+			expected: []discovery.Target{
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.1:8080", "__meta_kubernetes_pod_ip": "10.0.0.1", "pod": "web-1", "team": "web", "cmdb_team": "payments", "cmdb_owner": "alice"}),
+				discovery.NewTargetFromMap(map[string]string{"__address__": "10.0.0.2:8080", "__meta_kubernetes_pod_ip": "10.0.0.2", "pod": "db-1", "cmdb_team": "storage", "cmdb_owner": "bob"}),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args join.Arguments
+			require.NoError(t, syntax.Unmarshal([]byte(tc.config), &args))
+
+			ctrl, err := componenttest.NewControllerFromID(nil, "discovery.join")
+			require.NoError(t, err)
+			go func() {
+				err := ctrl.Run(componenttest.TestContext(t), args)
+				require.NoError(t, err)
+			}()
+
+			require.NoError(t, ctrl.WaitExports(time.Second))
+			require.Equal(t, tc.expected, ctrl.Exports().(join.Exports).Output)
+		})
+	}
+}
+
+func TestJoin_MultipleMatchLabels(t *testing.T) {
+	config := `
+targets = [
+	{ "host" = "a", "port" = "80" },
+	{ "host" = "a", "port" = "81" },
+	{ "host" = "a" },
+]
+join_targets = [
+	{ "host" = "a", "port" = "81", "service" = "api" },
+]
+target_match_labels = ["host", "port"]
+join_type           = "inner"
+`
+	var args join.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(config), &args))
+
+	ctrl, err := componenttest.NewControllerFromID(nil, "discovery.join")
+	require.NoError(t, err)
+	go func() {
+		err := ctrl.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	require.Equal(t, []discovery.Target{
+		discovery.NewTargetFromMap(map[string]string{"host": "a", "port": "81", "service": "api"}),
+	}, ctrl.Exports().(join.Exports).Output)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	var testCases = []struct {
+		name                  string
+		config                string
+		expectedErrorContains string
+	}{
+		{
+			name: "no match labels",
+			config: `
+targets             = []
+join_targets        = []
+target_match_labels = []`,
+			expectedErrorContains: "target_match_labels must not be empty",
+		},
+		{
+			name: "mismatched match labels",
+			config: `
+targets             = []
+join_targets        = []
+target_match_labels = ["a", "b"]
+join_match_labels   = ["a"]`,
+			expectedErrorContains: "join_match_labels must have the same number of labels as target_match_labels",
+		},
+		{
+			name: "unknown join type",
+			config: `
+targets             = []
+join_targets        = []
+target_match_labels = ["a"]
+join_type           = "outer"`,
+			expectedErrorContains: `unknown join_type "outer"`,
+		},
+		{
+			name: "unknown conflict strategy",
+			config: `
+targets             = []
+join_targets        = []
+target_match_labels = ["a"]
+on_conflict         = "merge"`,
+			expectedErrorContains: `unknown on_conflict "merge"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args join.Arguments
+			err := syntax.Unmarshal([]byte(tc.config), &args)
+			require.ErrorContains(t, err, tc.expectedErrorContains)
+		})
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"encoding"
 	"fmt"
 	"math"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/alecthomas/units"
+	common_config "github.com/grafana/alloy/internal/component/common/config"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/remote/s3"
 	"github.com/grafana/alloy/syntax"
 	"github.com/grafana/alloy/syntax/alloytypes"
+	"github.com/prometheus/prometheus/storage"
 )
 
 // Arguments configures the app_agent_receiver component.
@@ -20,16 +25,29 @@ type Arguments struct {
 
 	Server     ServerArguments     `alloy:"server,block,optional"`
 	SourceMaps SourceMapsArguments `alloy:"sourcemaps,block,optional"`
+	Metrics    MetricsArguments    `alloy:"metrics,block,optional"`
 	Output     OutputArguments     `alloy:"output,block"`
 }
 
-var _ syntax.Defaulter = (*Arguments)(nil)
+var (
+	_ syntax.Defaulter = (*Arguments)(nil)
+	_ syntax.Validator = (*Arguments)(nil)
+)
 
 // SetToDefault applies default settings.
 func (args *Arguments) SetToDefault() {
 	args.LogFormat = FormatDefault
 	args.Server.SetToDefault()
 	args.SourceMaps.SetToDefault()
+	args.Metrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if err := args.SourceMaps.Validate(); err != nil {
+		return err
+	}
+	return args.Metrics.Validate()
 }
 
 // ServerArguments configures the HTTP server where telemetry information will
@@ -79,6 +97,9 @@ type SourceMapsArguments struct {
 	DownloadTimeout     time.Duration       `alloy:"download_timeout,attr,optional"`
 	Cache               *CacheArguments     `alloy:"cache,block,optional"`
 	Locations           []LocationArguments `alloy:"location,block,optional"`
+
+	S3Locations   []S3LocationArguments   `alloy:"s3_location,block,optional"`
+	HTTPLocations []HTTPLocationArguments `alloy:"http_location,block,optional"`
 }
 
 func (s *SourceMapsArguments) SetToDefault() {
@@ -91,6 +112,26 @@ func (s *SourceMapsArguments) SetToDefault() {
 	s.Cache.SetToDefault()
 }
 
+func (s *SourceMapsArguments) Validate() error {
+	for _, loc := range s.S3Locations {
+		if !strings.HasPrefix(loc.Path, "s3://") {
+			return fmt.Errorf("s3_location path %q must start with s3://", loc.Path)
+		}
+		if _, err := template.New(loc.Path).Parse(loc.Path); err != nil {
+			return fmt.Errorf("invalid s3_location path %q: %w", loc.Path, err)
+		}
+	}
+	for _, loc := range s.HTTPLocations {
+		if !strings.HasPrefix(loc.URL, "http://") && !strings.HasPrefix(loc.URL, "https://") {
+			return fmt.Errorf("http_location url %q must start with http:// or https://", loc.URL)
+		}
+		if _, err := template.New(loc.URL).Parse(loc.URL); err != nil {
+			return fmt.Errorf("invalid http_location url %q: %w", loc.URL, err)
+		}
+	}
+	return nil
+}
+
 // CacheArguments configures sourcemap caching behavior.
 type CacheArguments struct {
 	TTL                  time.Duration `alloy:"ttl,attr,optional"`
@@ -112,11 +153,82 @@ type LocationArguments struct {
 	MinifiedPathPrefix string `alloy:"minified_path_prefix,attr"`
 }
 
-// OutputArguments configures where to send emitted logs and traces. Metrics
-// emitted by app_agent_receiver are exported as targets to be scraped.
+// MetricsArguments configures the metrics generated from measurements, which
+// are sent to the metrics output.
+type MetricsArguments struct {
+	FlushInterval       time.Duration `alloy:"flush_interval,attr,optional"`
+	MaxApps             int           `alloy:"max_apps,attr,optional"`
+	MaxEnvironments     int           `alloy:"max_environments,attr,optional"`
+	MaxPageRoutes       int           `alloy:"max_page_routes,attr,optional"`
+	MaxBrowsers         int           `alloy:"max_browsers,attr,optional"`
+	MaxMeasurementNames int           `alloy:"max_measurement_names,attr,optional"`
+	MaxSeries           int           `alloy:"max_series,attr,optional"`
+}
+
+func (m *MetricsArguments) SetToDefault() {
+	*m = MetricsArguments{
+		FlushInterval:       time.Minute,
+		MaxApps:             50,
+		MaxEnvironments:     10,
+		MaxPageRoutes:       100,
+		MaxBrowsers:         20,
+		MaxMeasurementNames: 100,
+		MaxSeries:           10000,
+	}
+}
+
+func (m *MetricsArguments) Validate() error {
+	if m.FlushInterval <= 0 {
+		return fmt.Errorf("flush_interval must be greater than 0")
+	}
+
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"max_apps", m.MaxApps},
+		{"max_environments", m.MaxEnvironments},
+		{"max_page_routes", m.MaxPageRoutes},
+		{"max_browsers", m.MaxBrowsers},
+		{"max_measurement_names", m.MaxMeasurementNames},
+		{"max_series", m.MaxSeries},
+	}
+	for _, limit := range limits {
+		if limit.value <= 0 {
+			return fmt.Errorf("%s must be greater than 0", limit.name)
+		}
+	}
+	return nil
+}
+
+// S3LocationArguments specifies an S3 bucket where source maps will be loaded.
+type S3LocationArguments struct {
+	Path               string    `alloy:"path,attr"`
+	MinifiedPathPrefix string    `alloy:"minified_path_prefix,attr"`
+	Client             s3.Client `alloy:"client,block,optional"`
+}
+
+// HTTPLocationArguments specifies an HTTP server where source maps will be
+// loaded.
+type HTTPLocationArguments struct {
+	URL                string                         `alloy:"url,attr"`
+	MinifiedPathPrefix string                         `alloy:"minified_path_prefix,attr"`
+	Client             common_config.HTTPClientConfig `alloy:"client,block,optional"`
+}
+
+func (h *HTTPLocationArguments) SetToDefault() {
+	*h = HTTPLocationArguments{
+		Client: common_config.DefaultHTTPClientConfig,
+	}
+}
+
+// OutputArguments configures where to send emitted logs, traces and metrics
+// generated from measurements. Metrics about the receiver itself are exposed
+// as debug metrics of the component.
 type OutputArguments struct {
-	Logs   []loki.LogsReceiver `alloy:"logs,attr,optional"`
-	Traces []otelcol.Consumer  `alloy:"traces,attr,optional"`
+	Logs    []loki.LogsReceiver  `alloy:"logs,attr,optional"`
+	Traces  []otelcol.Consumer   `alloy:"traces,attr,optional"`
+	Metrics []storage.Appendable `alloy:"metrics,attr,optional"`
 }
 
 type LogFormat string
@@ -0,0 +1,345 @@
+package receiver
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/alloy/internal/component/faro/receiver/internal/payload"
+	alloyprom "github.com/grafana/alloy/internal/component/prometheus"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/util"
+)
+
+const (
+	// webVitalsMeasurementType is the type of the measurements sent by the
+	// web vitals instrumentation of the Faro Web SDK.
+	webVitalsMeasurementType = "web-vitals"
+
+	// otherLabelValue replaces label values once the limit of distinct values
+	// of a label has been reached.
+	otherLabelValue = "other"
+
+	measurementMetricName = "faro_measurement_value"
+)
+
+// Label names of the metrics generated from measurements.
+const (
+	labelApp             = "app"
+	labelEnvironment     = "environment"
+	labelPageRoute       = "page_route"
+	labelBrowser         = "browser"
+	labelMeasurementType = "type"
+	labelMeasurementName = "name"
+)
+
+var (
+	// webVitalsDurationBuckets are the buckets of the web vitals measured in
+	// seconds. They include the thresholds of good and poor scores of each web
+	// vital.
+	webVitalsDurationBuckets = []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.8, 1, 1.8, 2.5, 3, 4, 6, 10}
+
+	// webVitalsScoreBuckets are the buckets of the web vitals without unit,
+	// such as CLS.
+	webVitalsScoreBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.15, 0.25, 0.5, 1}
+)
+
+// webVital describes how a web vital is converted to a histogram.
+type webVital struct {
+	metricName string
+	buckets    []float64
+	// divisor converts the measured value to the unit of the metric, for
+	// example milliseconds to seconds.
+	divisor float64
+}
+
+// webVitals holds the web vitals which are converted to histograms, indexed
+// by the name of their value in a measurement.
+var webVitals = map[string]webVital{
+	"lcp":  {metricName: "faro_web_vitals_lcp_seconds", buckets: webVitalsDurationBuckets, divisor: 1000},
+	"fcp":  {metricName: "faro_web_vitals_fcp_seconds", buckets: webVitalsDurationBuckets, divisor: 1000},
+	"inp":  {metricName: "faro_web_vitals_inp_seconds", buckets: webVitalsDurationBuckets, divisor: 1000},
+	"fid":  {metricName: "faro_web_vitals_fid_seconds", buckets: webVitalsDurationBuckets, divisor: 1000},
+	"ttfb": {metricName: "faro_web_vitals_ttfb_seconds", buckets: webVitalsDurationBuckets, divisor: 1000},
+	"cls":  {metricName: "faro_web_vitals_cls", buckets: webVitalsScoreBuckets, divisor: 1},
+}
+
+// measurementsExporter converts measurements into Prometheus metrics, which
+// are periodically written to a set of appendables.
+type measurementsExporter struct {
+	log          log.Logger
+	fanout       *alloyprom.Fanout
+	droppedTotal prometheus.Counter
+
+	mut      sync.Mutex
+	enabled  bool
+	args     MetricsArguments
+	limiters map[string]*labelLimiter
+	series   map[string]*measurementSeries
+}
+
+var _ exporter = (*measurementsExporter)(nil)
+
+func newMeasurementsExporter(log log.Logger, reg prometheus.Registerer, fanout *alloyprom.Fanout) *measurementsExporter {
+	droppedTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "faro_receiver_measurement_metrics_dropped_total",
+		Help: "Total number of measurement values dropped because the series limit of the metrics output was reached",
+	})
+
+	return &measurementsExporter{
+		log:          log,
+		fanout:       fanout,
+		droppedTotal: util.MustRegisterOrGet(reg, droppedTotal).(prometheus.Counter),
+
+		limiters: make(map[string]*labelLimiter),
+		series:   make(map[string]*measurementSeries),
+	}
+}
+
+// Update updates the settings of the generated metrics and the appendables
+// which they're written to. Measurements are only converted into metrics
+// when there is at least one appendable.
+func (exp *measurementsExporter) Update(args MetricsArguments, appendables []storage.Appendable) {
+	exp.mut.Lock()
+	defer exp.mut.Unlock()
+
+	exp.fanout.UpdateChildren(appendables)
+	exp.args = args
+	exp.enabled = len(appendables) > 0
+
+	if !exp.enabled {
+		// Free the state of the metrics; there is nowhere to write it to.
+		exp.limiters = make(map[string]*labelLimiter)
+		exp.series = make(map[string]*measurementSeries)
+	}
+}
+
+func (exp *measurementsExporter) Name() string { return "measurements exporter" }
+
+func (exp *measurementsExporter) Export(ctx context.Context, p payload.Payload) error {
+	if len(p.Measurements) == 0 {
+		return nil
+	}
+
+	exp.mut.Lock()
+	defer exp.mut.Unlock()
+
+	if !exp.enabled {
+		return nil
+	}
+
+	base := []string{
+		labelApp, exp.limit(labelApp, exp.args.MaxApps, p.Meta.App.Name),
+		labelEnvironment, exp.limit(labelEnvironment, exp.args.MaxEnvironments, p.Meta.App.Environment),
+		labelPageRoute, exp.limit(labelPageRoute, exp.args.MaxPageRoutes, pageRoute(p.Meta.Page.URL)),
+		labelBrowser, exp.limit(labelBrowser, exp.args.MaxBrowsers, p.Meta.Browser.Name),
+	}
+
+	for _, m := range p.Measurements {
+		for name, value := range m.Values {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+
+			if vital, ok := webVitals[name]; ok && m.Type == webVitalsMeasurementType {
+				exp.observe(vital.metricName, base, vital.buckets, value/vital.divisor)
+				continue
+			}
+
+			// Other measurements have no known unit, so only their sum and
+			// count are tracked. The type and name of a measurement are
+			// limited together, as they're set by the instrumentation.
+			mType, mName := m.Type, name
+			if exp.limit(labelMeasurementName, exp.args.MaxMeasurementNames, mType+"\x00"+mName) == otherLabelValue {
+				mType, mName = otherLabelValue, otherLabelValue
+			}
+			lbls := append(slices.Clone(base), labelMeasurementType, mType, labelMeasurementName, mName)
+			exp.observe(measurementMetricName, lbls, nil, value)
+		}
+	}
+	return nil
+}
+
+// limit returns value if the limit of distinct values of the label hasn't
+// been reached yet, and otherLabelValue otherwise.
+func (exp *measurementsExporter) limit(label string, limit int, value string) string {
+	l, ok := exp.limiters[label]
+	if !ok {
+		l = &labelLimiter{values: make(map[string]struct{})}
+		exp.limiters[label] = l
+	}
+	return l.value(limit, value)
+}
+
+// observe records a value for the series of metricName with the given label
+// name and value pairs. Must be called with exp.mut held.
+func (exp *measurementsExporter) observe(metricName string, lbls []string, buckets []float64, value float64) {
+	builder := labels.NewScratchBuilder(len(lbls)/2 + 1)
+	builder.Add(labels.MetricName, metricName)
+	for i := 0; i < len(lbls); i += 2 {
+		// Empty label values are omitted, the same way Prometheus does.
+		if lbls[i+1] != "" {
+			builder.Add(lbls[i], lbls[i+1])
+		}
+	}
+	builder.Sort()
+	ls := builder.Labels()
+
+	key := ls.String()
+	s, ok := exp.series[key]
+	if !ok {
+		if len(exp.series) >= exp.args.MaxSeries {
+			exp.droppedTotal.Inc()
+			return
+		}
+		s = &measurementSeries{
+			labels:       ls,
+			buckets:      buckets,
+			bucketCounts: make([]uint64, len(buckets)),
+		}
+		exp.series[key] = s
+	}
+	s.observe(value)
+}
+
+// Run periodically writes the generated metrics to the appendables until ctx
+// is canceled.
+func (exp *measurementsExporter) Run(ctx context.Context) {
+	timer := time.NewTimer(exp.flushInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := exp.flush(ctx, time.Now()); err != nil {
+				level.Error(exp.log).Log("msg", "failed to write measurement metrics", "err", err)
+			}
+			timer.Reset(exp.flushInterval())
+		}
+	}
+}
+
+func (exp *measurementsExporter) flushInterval() time.Duration {
+	exp.mut.Lock()
+	defer exp.mut.Unlock()
+	return exp.args.FlushInterval
+}
+
+// flush writes the current value of every series to the appendables.
+func (exp *measurementsExporter) flush(ctx context.Context, now time.Time) error {
+	exp.mut.Lock()
+	defer exp.mut.Unlock()
+
+	if !exp.enabled || len(exp.series) == 0 {
+		return nil
+	}
+
+	ts := now.UnixMilli()
+	app := exp.fanout.Appender(ctx)
+	for _, s := range exp.series {
+		if err := s.append(app, ts); err != nil {
+			_ = app.Rollback()
+			return err
+		}
+	}
+	return app.Commit()
+}
+
+// measurementSeries holds the state of a histogram. When the series has no
+// buckets, only its sum and count are tracked.
+type measurementSeries struct {
+	labels       labels.Labels
+	buckets      []float64
+	bucketCounts []uint64
+	sum          float64
+	count        uint64
+}
+
+func (s *measurementSeries) observe(value float64) {
+	for i, upperBound := range s.buckets {
+		if value <= upperBound {
+			s.bucketCounts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// append appends the samples of the series to app.
+func (s *measurementSeries) append(app storage.Appender, ts int64) error {
+	name := s.labels.Get(labels.MetricName)
+	builder := labels.NewBuilder(s.labels)
+
+	if len(s.buckets) > 0 {
+		builder.Set(labels.MetricName, name+"_bucket")
+		var cumulative uint64
+		for i, upperBound := range s.buckets {
+			cumulative += s.bucketCounts[i]
+			builder.Set(labels.BucketLabel, strconv.FormatFloat(upperBound, 'f', -1, 64))
+			if _, err := app.Append(0, builder.Labels(), ts, float64(cumulative)); err != nil {
+				return err
+			}
+		}
+		builder.Set(labels.BucketLabel, "+Inf")
+		if _, err := app.Append(0, builder.Labels(), ts, float64(s.count)); err != nil {
+			return err
+		}
+		builder.Del(labels.BucketLabel)
+	}
+
+	builder.Set(labels.MetricName, name+"_sum")
+	if _, err := app.Append(0, builder.Labels(), ts, s.sum); err != nil {
+		return err
+	}
+	builder.Set(labels.MetricName, name+"_count")
+	_, err := app.Append(0, builder.Labels(), ts, float64(s.count))
+	return err
+}
+
+// labelLimiter limits the number of distinct values of a label. The first
+// values which are seen are kept; values seen after the limit has been
+// reached are replaced by otherLabelValue.
+type labelLimiter struct {
+	values map[string]struct{}
+}
+
+func (l *labelLimiter) value(limit int, value string) string {
+	if value == "" {
+		return value
+	}
+	if _, ok := l.values[value]; ok {
+		return value
+	}
+	if len(l.values) >= limit {
+		return otherLabelValue
+	}
+	l.values[value] = struct{}{}
+	return value
+}
+
+// pageRoute returns the path of a page URL, without its query and fragment.
+func pageRoute(pageURL string) string {
+	if pageURL == "" {
+		return ""
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return otherLabelValue
+	}
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
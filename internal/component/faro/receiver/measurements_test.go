@@ -0,0 +1,136 @@
+package receiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/faro/receiver/internal/payload"
+	alloyprom "github.com/grafana/alloy/internal/component/prometheus"
+	"github.com/grafana/alloy/internal/service/labelstore"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/internal/util/testappender"
+	"github.com/grafana/alloy/syntax"
+)
+
+func Test_measurementsExporter_Export(t *testing.T) {
+	exp, app := newMeasurementsExporterForTesting(t, func(args *MetricsArguments) {
+		args.MaxPageRoutes = 1
+	})
+
+	p := payload.Payload{
+		Meta: payload.Meta{
+			App:     payload.App{Name: "shop", Environment: "production"},
+			Page:    payload.Page{URL: "https://shop.example.com/cart?item=42#summary"},
+			Browser: payload.Browser{Name: "Firefox"},
+		},
+		Measurements: []payload.Measurement{
+			{Type: "web-vitals", Values: map[string]float64{"lcp": 1500, "cls": 0.02}},
+			{Type: "web-vitals", Values: map[string]float64{"lcp": 3500}},
+			{Type: "checkout", Values: map[string]float64{"items": 3}},
+		},
+	}
+	require.NoError(t, exp.Export(t.Context(), p))
+
+	p.Meta.Page.URL = "https://shop.example.com/checkout"
+	p.Measurements = []payload.Measurement{{Type: "checkout", Values: map[string]float64{"items": 5}}}
+	require.NoError(t, exp.Export(t.Context(), p))
+
+	require.NoError(t, exp.flush(t.Context(), time.UnixMilli(1000)))
+
+	series := func(name string, lbls ...string) string {
+		base := []string{labels.MetricName, name, "app", "shop", "browser", "Firefox", "environment", "production"}
+		return labels.FromStrings(append(base, lbls...)...).String()
+	}
+	expect := map[string]float64{
+		series("faro_web_vitals_lcp_seconds_bucket", "page_route", "/cart", "le", "1.8"):  1,
+		series("faro_web_vitals_lcp_seconds_bucket", "page_route", "/cart", "le", "2.5"):  1,
+		series("faro_web_vitals_lcp_seconds_bucket", "page_route", "/cart", "le", "4"):    2,
+		series("faro_web_vitals_lcp_seconds_bucket", "page_route", "/cart", "le", "+Inf"): 2,
+		series("faro_web_vitals_lcp_seconds_sum", "page_route", "/cart"):                  5,
+		series("faro_web_vitals_lcp_seconds_count", "page_route", "/cart"):                2,
+		series("faro_web_vitals_cls_bucket", "page_route", "/cart", "le", "0.01"):         0,
+		series("faro_web_vitals_cls_bucket", "page_route", "/cart", "le", "0.025"):        1,
+		series("faro_web_vitals_cls_count", "page_route", "/cart"):                        1,
+
+		series("faro_measurement_value_sum", "page_route", "/cart", "type", "checkout", "name", "items"):   3,
+		series("faro_measurement_value_count", "page_route", "/cart", "type", "checkout", "name", "items"): 1,
+
+		// The page route limit has been reached, so other routes are replaced.
+		series("faro_measurement_value_sum", "page_route", "other", "type", "checkout", "name", "items"): 5,
+	}
+	for key, value := range expect {
+		sample := app.LatestSampleFor(key)
+		require.NotNil(t, sample, "missing series %s", key)
+		require.Equal(t, value, sample.Value, "unexpected value for series %s", key)
+		require.Equal(t, int64(1000), sample.Timestamp)
+	}
+}
+
+func Test_measurementsExporter_MaxSeries(t *testing.T) {
+	exp, app := newMeasurementsExporterForTesting(t, func(args *MetricsArguments) {
+		args.MaxSeries = 1
+	})
+
+	p := payload.Payload{
+		Meta: payload.Meta{App: payload.App{Name: "shop"}},
+		Measurements: []payload.Measurement{
+			{Type: "web-vitals", Values: map[string]float64{"lcp": 1500}},
+		},
+	}
+	require.NoError(t, exp.Export(t.Context(), p))
+	p.Measurements[0].Values = map[string]float64{"inp": 100}
+	require.NoError(t, exp.Export(t.Context(), p))
+
+	require.NoError(t, exp.flush(t.Context(), time.UnixMilli(1000)))
+	require.NotNil(t, app.LatestSampleFor(`{__name__="faro_web_vitals_lcp_seconds_count", app="shop"}`))
+	require.Nil(t, app.LatestSampleFor(`{__name__="faro_web_vitals_inp_seconds_count", app="shop"}`))
+}
+
+func Test_measurementsExporter_Disabled(t *testing.T) {
+	exp, app := newMeasurementsExporterForTesting(t, nil)
+
+	var args MetricsArguments
+	args.SetToDefault()
+	exp.Update(args, nil)
+
+	p := payload.Payload{
+		Measurements: []payload.Measurement{
+			{Type: "web-vitals", Values: map[string]float64{"lcp": 1500}},
+		},
+	}
+	require.NoError(t, exp.Export(t.Context(), p))
+	require.NoError(t, exp.flush(t.Context(), time.UnixMilli(1000)))
+	require.Empty(t, app.CollectedSamples())
+}
+
+func TestMetricsArguments_Validate(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		metrics {
+			max_page_routes = 0
+		}
+		output {}
+	`), &args)
+	require.ErrorContains(t, err, "max_page_routes must be greater than 0")
+}
+
+func newMeasurementsExporterForTesting(t *testing.T, configure func(*MetricsArguments)) (*measurementsExporter, testappender.CollectingAppender) {
+	reg := prometheus.NewRegistry()
+	fanout := alloyprom.NewFanout(nil, "faro.receiver", reg, labelstore.New(nil, reg))
+	exp := newMeasurementsExporter(util.TestLogger(t), reg, fanout)
+
+	var args MetricsArguments
+	args.SetToDefault()
+	if configure != nil {
+		configure(&args)
+	}
+
+	app := testappender.NewCollectingAppender()
+	exp.Update(args, []storage.Appendable{testappender.ConstantAppendable{Inner: app}})
+	return exp, app
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-sourcemap/sourcemap"
 	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/prometheus"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/labelstore"
 )
 
 func init() {
@@ -26,6 +28,7 @@ func init() {
 }
 
 type Component struct {
+	id                string
 	log               log.Logger
 	handler           *handler
 	lazySourceMaps    *varSourceMapsStore
@@ -35,9 +38,10 @@ type Component struct {
 	argsMut sync.RWMutex
 	args    Arguments
 
-	metrics *metricsExporter
-	logs    *logsExporter
-	traces  *tracesExporter
+	metrics      *metricsExporter
+	logs         *logsExporter
+	traces       *tracesExporter
+	measurements *measurementsExporter
 
 	actorCh chan func(context.Context)
 
@@ -48,6 +52,12 @@ type Component struct {
 var _ component.HealthComponent = (*Component)(nil)
 
 func New(o component.Options, args Arguments) (*Component, error) {
+	data, err := o.GetServiceData(labelstore.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	ls := data.(labelstore.LabelStore)
+
 	var (
 		// The source maps store changes at runtime based on settings, so we create
 		// a lazy store to pass to the logs exporter.
@@ -56,22 +66,30 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		metrics = newMetricsExporter(o.Registerer)
 		logs    = newLogsExporter(log.With(o.Logger, "exporter", "logs"), varStore, args.LogFormat)
 		traces  = newTracesExporter(log.With(o.Logger, "exporter", "traces"))
+
+		measurements = newMeasurementsExporter(
+			log.With(o.Logger, "exporter", "measurements"),
+			o.Registerer,
+			prometheus.NewFanout(nil, o.ID, o.Registerer, ls),
+		)
 	)
 
 	c := &Component{
+		id:  o.ID,
 		log: o.Logger,
 		handler: newHandler(
 			log.With(o.Logger, "subcomponent", "handler"),
 			o.Registerer,
-			[]exporter{metrics, logs, traces},
+			[]exporter{metrics, logs, traces, measurements},
 		),
 		lazySourceMaps:    varStore,
 		sourceMapsMetrics: newSourceMapMetrics(o.Registerer),
 		serverMetrics:     newServerMetrics(o.Registerer),
 
-		metrics: metrics,
-		logs:    logs,
-		traces:  traces,
+		metrics:      metrics,
+		logs:         logs,
+		traces:       traces,
+		measurements: measurements,
 
 		actorCh: make(chan func(context.Context), 1),
 	}
@@ -86,6 +104,14 @@ func (c *Component) Run(ctx context.Context) error {
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
+	// The measurements exporter runs for the lifetime of the component, so it
+	// isn't tracked by wg, which is used to wait for the current actor.
+	var measurementsWg sync.WaitGroup
+	defer measurementsWg.Wait()
+	measurementsWg.Go(func() {
+		c.measurements.Run(ctx)
+	})
+
 	var (
 		cancelCurrentActor context.CancelFunc
 	)
@@ -123,6 +149,11 @@ func (c *Component) Run(ctx context.Context) error {
 func (c *Component) Update(args component.Arguments) error {
 	newArgs := args.(Arguments)
 
+	remoteLocations, err := newSourceMapRemoteLocations(newArgs.SourceMaps, c.id)
+	if err != nil {
+		return err
+	}
+
 	c.argsMut.Lock()
 	c.args = newArgs
 	c.argsMut.Unlock()
@@ -141,6 +172,7 @@ func (c *Component) Update(args component.Arguments) error {
 		nil, // Use default HTTP client.
 		nil, // Use default FS implementation.
 	)
+	innerStore.SetRemoteLocations(remoteLocations)
 	c.lazySourceMaps.SetInner(innerStore)
 
 	// Start cleanup for new store
@@ -148,6 +180,7 @@ func (c *Component) Update(args component.Arguments) error {
 
 	c.logs.SetReceivers(newArgs.Output.Logs)
 	c.traces.SetConsumers(newArgs.Output.Traces)
+	c.measurements.Update(newArgs.Metrics, newArgs.Output.Metrics)
 
 	// Create a new server actor to run.
 	makeNewServer := func(ctx context.Context) {
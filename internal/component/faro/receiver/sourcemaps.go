@@ -74,6 +74,8 @@ type sourceMapMetrics struct {
 	cacheSize *prometheus.GaugeVec
 	downloads *prometheus.CounterVec
 	fileReads *prometheus.CounterVec
+
+	remoteReads *prometheus.CounterVec
 }
 
 func newSourceMapMetrics(reg prometheus.Registerer) *sourceMapMetrics {
@@ -90,11 +92,16 @@ func newSourceMapMetrics(reg prometheus.Registerer) *sourceMapMetrics {
 			Name: "faro_receiver_sourcemap_file_reads_total",
 			Help: "source map file reads from file system, by origin and status",
 		}, []string{"origin", "status"}),
+		remoteReads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "faro_receiver_sourcemap_remote_reads_total",
+			Help: "source map reads from remote locations, by origin, location type and status",
+		}, []string{"origin", "location", "status"}),
 	}
 
 	m.cacheSize = util.MustRegisterOrGet(reg, m.cacheSize).(*prometheus.GaugeVec)
 	m.downloads = util.MustRegisterOrGet(reg, m.downloads).(*prometheus.CounterVec)
 	m.fileReads = util.MustRegisterOrGet(reg, m.fileReads).(*prometheus.CounterVec)
+	m.remoteReads = util.MustRegisterOrGet(reg, m.remoteReads).(*prometheus.CounterVec)
 	return m
 }
 
@@ -120,6 +127,7 @@ type sourceMapsStoreImpl struct {
 	args    SourceMapsArguments
 	metrics *sourceMapMetrics
 	locs    []*sourcemapFileLocation
+	remote  []*sourcemapRemoteLocation
 
 	cacheMut      sync.Mutex
 	cache         map[string]*cachedSourceMap
@@ -176,6 +184,12 @@ func newSourceMapsStore(log log.Logger, args SourceMapsArguments, metrics *sourc
 	}
 }
 
+// SetRemoteLocations sets the remote locations searched for source maps, after
+// the locations on the filesystem. It must be called before the store is used.
+func (store *sourceMapsStoreImpl) SetRemoteLocations(locs []*sourcemapRemoteLocation) {
+	store.remote = locs
+}
+
 func (store *sourceMapsStoreImpl) GetSourceMap(sourceURL string, release string) (*sourcemap.Consumer, error) {
 	store.cacheMut.Lock()
 	defer store.cacheMut.Unlock()
@@ -318,6 +332,14 @@ func (store *sourceMapsStoreImpl) getSourceMapContent(sourceURL string, release
 		}
 	}
 
+	// Then in remote locations, such as S3 buckets.
+	for _, loc := range store.remote {
+		content, sourceMapURL, err = store.getSourceMapFromRemoteLocation(sourceURL, release, loc)
+		if content != nil || err != nil {
+			return content, sourceMapURL, err
+		}
+	}
+
 	// Attempt to download the sourcemap if enabled.
 	if strings.HasPrefix(sourceURL, "http") && urlMatchesOrigins(sourceURL, store.args.DownloadFromOrigins) && store.args.Download {
 		return store.downloadSourceMapContent(sourceURL)
@@ -0,0 +1,182 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	prom_config "github.com/prometheus/common/config"
+
+	remote_s3 "github.com/grafana/alloy/internal/component/remote/s3"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	remoteLocationS3   = "s3"
+	remoteLocationHTTP = "http"
+)
+
+// objectFetcher retrieves source maps from a remote location.
+type objectFetcher interface {
+	// Fetch returns the contents of the object at url, or nil if the object
+	// doesn't exist.
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// sourcemapRemoteLocation is a location where source maps are stored which
+// isn't the local filesystem, such as an S3 bucket or an HTTP server.
+type sourcemapRemoteLocation struct {
+	kind               string
+	minifiedPathPrefix string
+	pathTemplate       *template.Template
+	fetcher            objectFetcher
+}
+
+// newSourceMapRemoteLocations creates the remote locations configured in
+// args, in the order in which they're searched.
+func newSourceMapRemoteLocations(args SourceMapsArguments, componentID string) ([]*sourcemapRemoteLocation, error) {
+	var locs []*sourcemapRemoteLocation
+
+	for _, loc := range args.S3Locations {
+		tpl, err := template.New(loc.Path).Parse(loc.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3_location path %q: %w", loc.Path, err)
+		}
+		cli, err := remote_s3.NewClient(loc.Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client for s3_location %q: %w", loc.Path, err)
+		}
+		locs = append(locs, &sourcemapRemoteLocation{
+			kind:               remoteLocationS3,
+			minifiedPathPrefix: loc.MinifiedPathPrefix,
+			pathTemplate:       tpl,
+			fetcher:            &s3Fetcher{cli: cli},
+		})
+	}
+
+	for _, loc := range args.HTTPLocations {
+		tpl, err := template.New(loc.URL).Parse(loc.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http_location url %q: %w", loc.URL, err)
+		}
+		cli, err := prom_config.NewClientFromConfig(*loc.Client.Convert(), componentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client for http_location %q: %w", loc.URL, err)
+		}
+		locs = append(locs, &sourcemapRemoteLocation{
+			kind:               remoteLocationHTTP,
+			minifiedPathPrefix: loc.MinifiedPathPrefix,
+			pathTemplate:       tpl,
+			fetcher:            &httpFetcher{cli: cli},
+		})
+	}
+
+	return locs, nil
+}
+
+func (store *sourceMapsStoreImpl) getSourceMapFromRemoteLocation(sourceURL string, release string, loc *sourcemapRemoteLocation) (content []byte, sourceMapURL string, err error) {
+	if len(sourceURL) == 0 || !strings.HasPrefix(sourceURL, loc.minifiedPathPrefix) || strings.HasSuffix(sourceURL, "/") {
+		return nil, "", nil
+	}
+
+	var rootPath bytes.Buffer
+
+	err = loc.pathTemplate.Execute(&rootPath, struct{ Release string }{Release: cleanFilePathPart(release)})
+	if err != nil {
+		return nil, "", err
+	}
+
+	pathParts := []string{strings.TrimSuffix(rootPath.String(), "/")}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.Split(sourceURL, "?")[0], loc.minifiedPathPrefix), "/") {
+		if len(part) > 0 && part != "." && part != ".." {
+			pathParts = append(pathParts, part)
+		}
+	}
+	mapURL := strings.Join(pathParts, "/") + ".map"
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.args.DownloadTimeout)
+	defer cancel()
+
+	content, err = loc.fetcher.Fetch(ctx, mapURL)
+	switch {
+	case err != nil:
+		store.metrics.remoteReads.WithLabelValues(getOrigin(sourceURL), loc.kind, "error").Inc()
+		level.Debug(store.log).Log("msg", "failed to fetch source map", "url", sourceURL, "location", loc.kind, "source_map_url", mapURL, "err", err)
+		return nil, "", err
+	case content == nil:
+		store.metrics.remoteReads.WithLabelValues(getOrigin(sourceURL), loc.kind, "not_found").Inc()
+		level.Debug(store.log).Log("msg", "source map not found", "url", sourceURL, "location", loc.kind, "source_map_url", mapURL)
+		return nil, "", nil
+	default:
+		store.metrics.remoteReads.WithLabelValues(getOrigin(sourceURL), loc.kind, "ok").Inc()
+		level.Debug(store.log).Log("msg", "source map found", "url", sourceURL, "location", loc.kind, "source_map_url", mapURL)
+		return content, sourceURL, nil
+	}
+}
+
+// s3Fetcher fetches objects from S3. Object URLs have the form
+// s3://<bucket>/<key>.
+type s3Fetcher struct {
+	cli *s3.Client
+}
+
+func (f *s3Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(url, "s3://"), "/")
+
+	output, err := f.cli.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
+func isS3NotFound(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+	var respErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// httpFetcher fetches objects from an HTTP server.
+type httpFetcher struct {
+	cli *http.Client
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %v", resp.StatusCode)
+	}
+}
@@ -0,0 +1,157 @@
+package receiver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	common_config "github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/faro/receiver/internal/payload"
+	"github.com/grafana/alloy/internal/component/remote/s3"
+	alloyutil "github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func Test_sourceMapsStoreImpl_ReadFromS3(t *testing.T) {
+	// A minimal S3 stand-in serving objects with path-style addressing.
+	srv := newObjectServer(t, map[string][]byte{
+		"/sourcemaps/123/foo.js.map": loadTestData(t, "foo.js.map"),
+	})
+
+	args := SourceMapsArguments{
+		Download:        false,
+		DownloadTimeout: defaultTestDownloadTimeout,
+		S3Locations: []S3LocationArguments{{
+			Path:               "s3://sourcemaps/{{ .Release }}",
+			MinifiedPathPrefix: "http://localhost:1234/",
+			Client: s3.Client{
+				AccessKey:    "access-key",
+				Secret:       alloytypes.Secret("secret-key"),
+				Endpoint:     srv.URL,
+				UsePathStyle: true,
+				Region:       "us-east-1",
+			},
+		}},
+	}
+
+	store := newRemoteSourceMapsStoreForTesting(t, args)
+	actual := transformException(alloyutil.TestLogger(t), store, mockException(), "123")
+	require.Equal(t, expectedMockExceptionFrames(), actual.Stacktrace.Frames)
+	require.Equal(t, []string{"/sourcemaps/123/foo.js.map"}, srv.requests())
+
+	// Missing source maps leave the frames as is.
+	actual = transformException(alloyutil.TestLogger(t), store, mockException(), "456")
+	require.Equal(t, mockException().Stacktrace.Frames, actual.Stacktrace.Frames)
+}
+
+func Test_sourceMapsStoreImpl_ReadFromHTTP(t *testing.T) {
+	srv := newObjectServer(t, map[string][]byte{
+		"/maps/123/foo.js.map": loadTestData(t, "foo.js.map"),
+	})
+
+	args := SourceMapsArguments{
+		Download:        false,
+		DownloadTimeout: defaultTestDownloadTimeout,
+		HTTPLocations: []HTTPLocationArguments{{
+			URL:                srv.URL + "/maps/{{ .Release }}/",
+			MinifiedPathPrefix: "http://localhost:1234/",
+			Client:             common_config.DefaultHTTPClientConfig,
+		}},
+	}
+
+	store := newRemoteSourceMapsStoreForTesting(t, args)
+	actual := transformException(alloyutil.TestLogger(t), store, mockException(), "123")
+	require.Equal(t, expectedMockExceptionFrames(), actual.Stacktrace.Frames)
+
+	// The source map is cached for both frames.
+	require.Equal(t, []string{"/maps/123/foo.js.map"}, srv.requests())
+}
+
+func TestSourceMapsArguments_Validate(t *testing.T) {
+	args := SourceMapsArguments{
+		S3Locations: []S3LocationArguments{{Path: "sourcemaps/{{ .Release }}"}},
+	}
+	require.ErrorContains(t, args.Validate(), "must start with s3://")
+
+	args = SourceMapsArguments{
+		HTTPLocations: []HTTPLocationArguments{{URL: "https://example.com/{{ .Release"}},
+	}
+	require.ErrorContains(t, args.Validate(), "invalid http_location url")
+}
+
+const defaultTestDownloadTimeout = 5 * time.Second
+
+func newRemoteSourceMapsStoreForTesting(t *testing.T, args SourceMapsArguments) *sourceMapsStoreImpl {
+	t.Helper()
+
+	locs, err := newSourceMapRemoteLocations(args, "faro.receiver.test")
+	require.NoError(t, err)
+
+	store := newSourceMapsStore(
+		alloyutil.TestLogger(t),
+		args,
+		newSourceMapMetrics(prometheus.NewRegistry()),
+		&mockHTTPClient{},
+		newTestFileService(),
+	)
+	store.SetRemoteLocations(locs)
+	return store
+}
+
+func expectedMockExceptionFrames() []payload.Frame {
+	return []payload.Frame{
+		{
+			Colno:    37,
+			Filename: "/__parcel_source_root/demo/src/actions.ts",
+			Function: "?",
+			Lineno:   6,
+		},
+		{
+			Colno:    2,
+			Filename: "/__parcel_source_root/demo/src/actions.ts",
+			Function: "?",
+			Lineno:   7,
+		},
+	}
+}
+
+type objectServer struct {
+	*httptest.Server
+
+	mut  sync.Mutex
+	reqs []string
+}
+
+// newObjectServer returns a server which serves objects by path, and responds
+// to requests for other paths the same way S3 responds to missing keys.
+func newObjectServer(t *testing.T, objects map[string][]byte) *objectServer {
+	srv := &objectServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mut.Lock()
+		srv.reqs = append(srv.reqs, r.URL.Path)
+		srv.mut.Unlock()
+
+		content, ok := objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (srv *objectServer) requests() []string {
+	srv.mut.Lock()
+	defer srv.mut.Unlock()
+	return slices.Clone(srv.reqs)
+}
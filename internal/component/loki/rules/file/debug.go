@@ -0,0 +1,64 @@
+package rules
+
+import "sort"
+
+type DebugInfo struct {
+	Error              string               `alloy:"error,attr,optional"`
+	RuleFiles          []DebugRuleFile      `alloy:"rule_file,block,optional"`
+	LokiRuleNamespaces []DebugLokiNamespace `alloy:"loki_rule_namespace,block,optional"`
+}
+
+type DebugRuleFile struct {
+	Name          string `alloy:"name,attr"`
+	TenantID      string `alloy:"tenant_id,attr,optional"`
+	NumRuleGroups int    `alloy:"num_rule_groups,attr"`
+}
+
+type DebugLokiNamespace struct {
+	Name          string `alloy:"name,attr"`
+	TenantID      string `alloy:"tenant_id,attr,optional"`
+	NumRuleGroups int    `alloy:"num_rule_groups,attr"`
+}
+
+func (c *Component) DebugInfo() any {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	var output DebugInfo
+	if c.lastErr != nil {
+		output.Error = c.lastErr.Error()
+	}
+
+	for tenant, state := range c.currentState {
+		for ns, groups := range state {
+			output.LokiRuleNamespaces = append(output.LokiRuleNamespaces, DebugLokiNamespace{
+				Name:          ns,
+				TenantID:      tenant,
+				NumRuleGroups: len(groups),
+			})
+		}
+	}
+	for key, groups := range c.ruleFiles {
+		output.RuleFiles = append(output.RuleFiles, DebugRuleFile{
+			Name:          key.name,
+			TenantID:      key.tenant,
+			NumRuleGroups: len(groups),
+		})
+	}
+
+	sort.Slice(output.LokiRuleNamespaces, func(i, j int) bool {
+		a, b := output.LokiRuleNamespaces[i], output.LokiRuleNamespaces[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(output.RuleFiles, func(i, j int) bool {
+		a, b := output.RuleFiles[i], output.RuleFiles[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		return a.Name < b.Name
+	})
+	return output
+}
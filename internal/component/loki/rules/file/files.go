@@ -0,0 +1,205 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/alloy/internal/component/common/kubernetes"
+)
+
+// ruleFileExtensions holds the extensions of the files loaded from a
+// directory.
+var ruleFileExtensions = []string{".yaml", ".yml"}
+
+// ruleFileKey identifies a rule file.
+type ruleFileKey struct {
+	tenant string
+	name   string
+}
+
+// loadRuleFiles reads the rule files found at args.Path and set in
+// args.RuleFiles, and returns their validated rule groups.
+func loadRuleFiles(args Arguments) (map[ruleFileKey][]rulefmt.RuleGroup, error) {
+	contents := make(map[ruleFileKey][]byte)
+	if args.Path != "" {
+		if err := readRuleFiles(args, contents); err != nil {
+			return nil, err
+		}
+	}
+	for _, rf := range args.RuleFiles {
+		key := ruleFileKey{tenant: rf.tenant(args), name: rf.Name}
+		if _, ok := contents[key]; ok {
+			return nil, fmt.Errorf("rule file %q of tenant %q is both found in path and set in a rule_file block", key.name, key.tenant)
+		}
+		contents[key] = []byte(rf.Content.Value)
+	}
+
+	out := make(map[ruleFileKey][]rulefmt.RuleGroup, len(contents))
+	for key, content := range contents {
+		groups, err := parseRuleFile(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rule file %q: %w", key.name, err)
+		}
+		out[key] = groups
+	}
+	return out, nil
+}
+
+// parseRuleFile parses a rule file and validates its rule groups. The
+// expressions of the rules are validated as LogQL, which is why the
+// validation of rulefmt.Parse, which expects PromQL, can't be used.
+func parseRuleFile(content []byte) ([]rulefmt.RuleGroup, error) {
+	var groups rulefmt.RuleGroups
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	// Ignore io.EOF which happens with empty input.
+	if err := decoder.Decode(&groups); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var err error
+	seen := make(map[string]struct{}, len(groups.Groups))
+	for _, group := range groups.Groups {
+		if group.Name == "" {
+			err = multierror.Append(err, fmt.Errorf("group name must not be empty"))
+		}
+		if _, ok := seen[group.Name]; ok {
+			err = multierror.Append(err, fmt.Errorf("group name %q is repeated in the same file", group.Name))
+		}
+		seen[group.Name] = struct{}{}
+
+		for _, rule := range group.Rules {
+			if (rule.Record == "") == (rule.Alert == "") {
+				err = multierror.Append(err, fmt.Errorf("exactly one of record or alert must be set for each rule in group '%s'", group.Name))
+				continue
+			}
+			if _, parseErr := syntax.ParseExpr(rule.Expr); parseErr != nil {
+				if rule.Record != "" {
+					err = multierror.Append(err, fmt.Errorf("could not parse expression for record '%s' in group '%s': %w", rule.Record, group.Name, parseErr))
+				} else {
+					err = multierror.Append(err, fmt.Errorf("could not parse expression for alert '%s' in group '%s': %w", rule.Alert, group.Name, parseErr))
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return groups.Groups, nil
+}
+
+func readRuleFiles(args Arguments, contents map[ruleFileKey][]byte) error {
+	path := args.Path
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule files: %w", err)
+	}
+	if !fi.IsDir() {
+		if args.TenantFromDirectory {
+			return fmt.Errorf("path must be a directory when tenant_from_directory is set")
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		contents[ruleFileKey{tenant: args.TenantID, name: filepath.Base(path)}] = b
+		return nil
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read rule files: %w", err)
+		}
+		// Skip hidden files and directories, such as the .git directory of
+		// a repository checkout or the temporary files of editors.
+		if p != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isRuleFile(d.Name()) {
+			return nil
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+
+		key := ruleFileKey{tenant: args.TenantID, name: filepath.ToSlash(rel)}
+		if args.TenantFromDirectory {
+			// The first directory of the relative path is the tenant; files
+			// directly in path belong to the default tenant.
+			if tenant, name, ok := strings.Cut(key.name, "/"); ok {
+				key = ruleFileKey{tenant: tenant, name: name}
+			}
+		}
+		contents[key] = b
+		return nil
+	})
+}
+
+func isRuleFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range ruleFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// desiredState converts rule files to the rule groups they should be loaded
+// as, indexed by tenant and Loki namespace.
+func desiredState(prefix string, ruleFiles map[ruleFileKey][]rulefmt.RuleGroup) (map[string]kubernetes.PrometheusRuleGroupsByNamespace, error) {
+	out := make(map[string]kubernetes.PrometheusRuleGroupsByNamespace)
+	names := make(map[ruleFileKey]string)
+	for key, groups := range ruleFiles {
+		if len(groups) == 0 {
+			continue
+		}
+
+		ns := lokiNamespaceForRuleFile(prefix, key.name)
+		nsKey := ruleFileKey{tenant: key.tenant, name: ns}
+		if other, ok := names[nsKey]; ok {
+			return nil, fmt.Errorf("rule files %q and %q of tenant %q are both loaded into the Loki namespace %q", other, key.name, key.tenant, ns)
+		}
+		names[nsKey] = key.name
+
+		if out[key.tenant] == nil {
+			out[key.tenant] = make(kubernetes.PrometheusRuleGroupsByNamespace)
+		}
+		out[key.tenant][ns] = groups
+	}
+	return out, nil
+}
+
+// lokiNamespaceForRuleFile returns the namespace that the rule file should be
+// stored in Loki. This function, along with isManagedLokiNamespace, is used
+// to determine if a rule file is managed by Alloy.
+func lokiNamespaceForRuleFile(prefix, name string) string {
+	// Set to - to separate, loki doesn't support prefixpath like mimir ruler does
+	return prefix + "-" + strings.ReplaceAll(name, "/", "-")
+}
+
+// isManagedLokiNamespace returns true if the namespace is managed by Alloy.
+// Unmanaged namespaces are left as is by the component.
+func isManagedLokiNamespace(prefix, namespace string) bool {
+	return strings.HasPrefix(namespace, prefix+"-")
+}
@@ -0,0 +1,371 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/instrument"
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/rulefmt"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/kubernetes"
+	"github.com/grafana/alloy/internal/featuregate"
+	lokiClient "github.com/grafana/alloy/internal/loki/client"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/util"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "loki.rules.file",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   nil,
+		Build: func(o component.Options, c component.Arguments) (component.Component, error) {
+			return NewComponent(o, c.(Arguments))
+		},
+	})
+}
+
+// Component loads LogQL rule files into the Loki ruler. Each rule file is
+// stored in its own Loki namespace, for the tenant it belongs to.
+type Component struct {
+	log  log.Logger
+	opts component.Options
+
+	mut  sync.Mutex
+	args Arguments
+	// clients holds a Loki client per tenant. It is reset when the
+	// arguments change.
+	clients   map[string]lokiClient.Interface
+	newClient func(args Arguments, tenant string) (lokiClient.Interface, error)
+
+	configUpdates chan struct{}
+
+	stateMut  sync.RWMutex
+	ruleFiles map[ruleFileKey][]rulefmt.RuleGroup
+	// currentState holds the managed rule groups of each tenant, indexed by
+	// Loki namespace. Tenants stay in currentState until their managed
+	// namespaces are deleted, so that their rules are cleaned up once the
+	// tenant no longer has rule files.
+	currentState map[string]kubernetes.PrometheusRuleGroupsByNamespace
+	lastErr      error
+
+	metrics   *metrics
+	healthMut sync.RWMutex
+	health    component.Health
+}
+
+type metrics struct {
+	configUpdatesTotal prometheus.Counter
+
+	reconcilesTotal  prometheus.Counter
+	reconcilesFailed prometheus.Counter
+
+	lokiClientTiming *prometheus.HistogramVec
+}
+
+func (m *metrics) Register(r prometheus.Registerer) error {
+	m.configUpdatesTotal = util.MustRegisterOrGet(r, m.configUpdatesTotal).(prometheus.Counter)
+	m.reconcilesTotal = util.MustRegisterOrGet(r, m.reconcilesTotal).(prometheus.Counter)
+	m.reconcilesFailed = util.MustRegisterOrGet(r, m.reconcilesFailed).(prometheus.Counter)
+	m.lokiClientTiming = util.MustRegisterOrGet(r, m.lokiClientTiming).(*prometheus.HistogramVec)
+	return nil
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		configUpdatesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "loki_rules",
+			Name:      "config_updates_total",
+			Help:      "Total number of times the configuration has been updated.",
+		}),
+		reconcilesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "loki_rules",
+			Name:      "reconciles_total",
+			Help:      "Total number of times the rule files have been reconciled with the Loki ruler.",
+		}),
+		reconcilesFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "loki_rules",
+			Name:      "reconciles_failed_total",
+			Help:      "Total number of times the rule files failed to be reconciled with the Loki ruler.",
+		}),
+		lokiClientTiming: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Subsystem: "loki_rules",
+			Name:      "loki_client_request_duration_seconds",
+			Help:      "Duration of requests to the Loki API.",
+			Buckets:   instrument.DefBuckets,
+		}, instrument.HistogramCollectorBuckets),
+	}
+}
+
+var _ component.Component = (*Component)(nil)
+var _ component.DebugComponent = (*Component)(nil)
+var _ component.HealthComponent = (*Component)(nil)
+
+func NewComponent(o component.Options, args Arguments) (*Component, error) {
+	metrics := newMetrics()
+	err := metrics.Register(o.Registerer)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics failed: %w", err)
+	}
+
+	c := &Component{
+		log:           o.Logger,
+		opts:          o,
+		args:          args,
+		clients:       make(map[string]lokiClient.Interface),
+		configUpdates: make(chan struct{}, 1),
+		currentState:  make(map[string]kubernetes.PrometheusRuleGroupsByNamespace),
+		metrics:       metrics,
+	}
+	c.newClient = c.newLokiClient
+
+	// Create the client of the default tenant early to report invalid
+	// client configurations.
+	if _, err := c.clientFor(args.TenantID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Component) Run(ctx context.Context) error {
+	args := c.getArgs()
+	syncTicker := time.NewTicker(args.SyncInterval)
+	defer syncTicker.Stop()
+	pollTicker := time.NewTicker(args.PollFrequency)
+	defer pollTicker.Stop()
+
+	c.reconcile(ctx, true)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.configUpdates:
+			c.metrics.configUpdatesTotal.Inc()
+			args = c.getArgs()
+			syncTicker.Reset(args.SyncInterval)
+			pollTicker.Reset(args.PollFrequency)
+			c.reconcile(ctx, true)
+		case <-syncTicker.C:
+			c.reconcile(ctx, true)
+		case <-pollTicker.C:
+			c.reconcile(ctx, false)
+		}
+	}
+}
+
+func (c *Component) Update(newConfig component.Arguments) error {
+	args := newConfig.(Arguments)
+
+	// Validate the client configuration before applying it.
+	client, err := c.newClient(args, args.TenantID)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	c.args = args
+	c.clients = map[string]lokiClient.Interface{args.TenantID: client}
+	c.mut.Unlock()
+
+	select {
+	case c.configUpdates <- struct{}{}:
+	default: // update already scheduled
+	}
+	return nil
+}
+
+func (c *Component) getArgs() Arguments {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.args
+}
+
+// clientFor returns the Loki client of a tenant.
+func (c *Component) clientFor(tenant string) (lokiClient.Interface, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if client, ok := c.clients[tenant]; ok {
+		return client, nil
+	}
+	client, err := c.newClient(c.args, tenant)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[tenant] = client
+	return client, nil
+}
+
+func (c *Component) newLokiClient(args Arguments, tenant string) (lokiClient.Interface, error) {
+	return lokiClient.New(c.log, lokiClient.Config{
+		ID:               tenant,
+		Address:          args.Address,
+		UseLegacyRoutes:  args.UseLegacyRoutes,
+		HTTPClientConfig: *args.HTTPClientConfig.Convert(),
+	}, c.metrics.lokiClientTiming)
+}
+
+// reconcile loads the rule files and applies their changes to the Loki
+// ruler. The state of the ruler is first synced when syncLoki is true, or
+// when the state of a tenant was never synced before.
+func (c *Component) reconcile(ctx context.Context, syncLoki bool) {
+	c.metrics.reconcilesTotal.Inc()
+
+	err := c.reconcileState(ctx, syncLoki)
+
+	c.stateMut.Lock()
+	c.lastErr = err
+	c.stateMut.Unlock()
+
+	if err != nil {
+		c.metrics.reconcilesFailed.Inc()
+		level.Error(c.log).Log("msg", "failed to reconcile rule files", "err", err)
+		c.reportUnhealthy(err)
+		return
+	}
+	c.reportHealthy()
+}
+
+func (c *Component) reconcileState(ctx context.Context, syncLoki bool) error {
+	args := c.getArgs()
+
+	ruleFiles, err := loadRuleFiles(args)
+	if err != nil {
+		return err
+	}
+	desired, err := desiredState(args.LokiNameSpacePrefix, ruleFiles)
+	if err != nil {
+		return err
+	}
+
+	c.stateMut.Lock()
+	c.ruleFiles = ruleFiles
+	tenants := slices.Collect(maps.Keys(c.currentState))
+	c.stateMut.Unlock()
+
+	// The default tenant is always reconciled, so that the rules of its
+	// deleted rule files are cleaned up after a restart.
+	for _, tenant := range append(slices.Collect(maps.Keys(desired)), args.TenantID) {
+		if !slices.Contains(tenants, tenant) {
+			tenants = append(tenants, tenant)
+		}
+	}
+	slices.Sort(tenants)
+
+	var errs error
+	for _, tenant := range tenants {
+		if err := c.reconcileTenant(ctx, args.LokiNameSpacePrefix, tenant, desired[tenant], syncLoki); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("tenant %q: %w", tenant, err))
+		}
+	}
+	return errs
+}
+
+func (c *Component) reconcileTenant(ctx context.Context, prefix, tenant string, desired kubernetes.PrometheusRuleGroupsByNamespace, syncLoki bool) error {
+	client, err := c.clientFor(tenant)
+	if err != nil {
+		return err
+	}
+
+	c.stateMut.RLock()
+	current, synced := c.currentState[tenant]
+	c.stateMut.RUnlock()
+
+	if syncLoki || !synced {
+		if current, err = c.syncLoki(ctx, client, prefix, tenant); err != nil {
+			return err
+		}
+	}
+
+	diffs := kubernetes.DiffPrometheusRuleGroupState(desired, current)
+	if len(diffs) == 0 {
+		c.forgetTenantIfUnused(tenant, desired)
+		return nil
+	}
+
+	var errs error
+	for ns, diff := range diffs {
+		if err := c.applyChanges(ctx, client, ns, diff); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	// resync loki state after applying changes
+	if _, err := c.syncLoki(ctx, client, prefix, tenant); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if errs == nil {
+		c.forgetTenantIfUnused(tenant, desired)
+	}
+	return errs
+}
+
+// forgetTenantIfUnused stops tracking tenants which no longer have rule
+// files nor managed namespaces.
+func (c *Component) forgetTenantIfUnused(tenant string, desired kubernetes.PrometheusRuleGroupsByNamespace) {
+	if len(desired) > 0 {
+		return
+	}
+
+	c.stateMut.Lock()
+	defer c.stateMut.Unlock()
+	if len(c.currentState[tenant]) == 0 {
+		delete(c.currentState, tenant)
+	}
+}
+
+func (c *Component) syncLoki(ctx context.Context, client lokiClient.Interface, prefix, tenant string) (kubernetes.PrometheusRuleGroupsByNamespace, error) {
+	rulesByNamespace, err := client.ListRules(ctx, "")
+	if err != nil {
+		level.Error(c.log).Log("msg", "failed to list rules from loki", "tenant", tenant, "err", err)
+		return nil, err
+	}
+
+	for ns := range rulesByNamespace {
+		if !isManagedLokiNamespace(prefix, ns) {
+			delete(rulesByNamespace, ns)
+		}
+	}
+
+	c.stateMut.Lock()
+	c.currentState[tenant] = rulesByNamespace
+	c.stateMut.Unlock()
+	return rulesByNamespace, nil
+}
+
+func (c *Component) applyChanges(ctx context.Context, client lokiClient.Interface, namespace string, diffs []kubernetes.PrometheusRuleGroupDiff) error {
+	for _, diff := range diffs {
+		switch diff.Kind {
+		case kubernetes.RuleGroupDiffKindAdd:
+			err := client.CreateRuleGroup(ctx, namespace, diff.Desired)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "added rule group", "namespace", namespace, "group", diff.Desired.Name)
+		case kubernetes.RuleGroupDiffKindRemove:
+			err := client.DeleteRuleGroup(ctx, namespace, diff.Actual.Name)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "removed rule group", "namespace", namespace, "group", diff.Actual.Name)
+		case kubernetes.RuleGroupDiffKindUpdate:
+			err := client.CreateRuleGroup(ctx, namespace, diff.Desired)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "updated rule group", "namespace", namespace, "group", diff.Desired.Name)
+		default:
+			level.Error(c.log).Log("msg", "unknown rule group diff kind", "kind", diff.Kind)
+		}
+	}
+	return nil
+}
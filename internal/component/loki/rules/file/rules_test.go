@@ -0,0 +1,298 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	lokiClient "github.com/grafana/alloy/internal/loki/client"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func TestAlloyConfigs(t *testing.T) {
+	var testCases = []struct {
+		name                  string
+		config                string
+		expectedErrorContains string
+	}{
+		{
+			name: "path",
+			config: `
+	address = "LOKI_URL"
+	path    = "/etc/alloy/rules"`,
+		},
+		{
+			name: "tenant from directory",
+			config: `
+	address               = "LOKI_URL"
+	path                  = "/etc/alloy/rules"
+	tenant_from_directory = true`,
+		},
+		{
+			name: "same rule file name for different tenants",
+			config: `
+	address = "LOKI_URL"
+	rule_file {
+		name      = "alerts"
+		content   = "groups: []"
+		tenant_id = "team-a"
+	}
+	rule_file {
+		name      = "alerts"
+		content   = "groups: []"
+		tenant_id = "team-b"
+	}`,
+		},
+		{
+			name: "duplicate rule file",
+			config: `
+	address   = "LOKI_URL"
+	tenant_id = "team-a"
+	rule_file {
+		name    = "alerts"
+		content = "groups: []"
+	}
+	rule_file {
+		name      = "alerts"
+		content   = "groups: []"
+		tenant_id = "team-a"
+	}`,
+			expectedErrorContains: `rule_file name "alerts" is used more than once for tenant "team-a"`,
+		},
+		{
+			name: "no rules",
+			config: `
+	address = "LOKI_URL"`,
+			expectedErrorContains: "at least one of path or rule_file must be provided",
+		},
+		{
+			name: "tenant from directory without path",
+			config: `
+	address               = "LOKI_URL"
+	tenant_from_directory = true
+	rule_file {
+		name    = "alerts"
+		content = "groups: []"
+	}`,
+			expectedErrorContains: "tenant_from_directory requires path to be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tc.config), &args)
+			if tc.expectedErrorContains == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErrorContains)
+			}
+		})
+	}
+}
+
+func TestLoadRuleFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "default.yaml"), testRules("default"))
+	writeFile(t, filepath.Join(dir, "team-a", "alerts.yaml"), testRules("team_a"))
+	writeFile(t, filepath.Join(dir, "team-b", "nested", "alerts.yml"), testRules("team_b"))
+	writeFile(t, filepath.Join(dir, ".git", "config.yaml"), "not a rule file")
+
+	args := DefaultArguments
+	args.Path = dir
+	args.TenantID = "fallback"
+
+	ruleFiles, err := loadRuleFiles(args)
+	require.NoError(t, err)
+	require.Len(t, ruleFiles, 3)
+	require.Contains(t, ruleFiles, ruleFileKey{tenant: "fallback", name: "team-a/alerts.yaml"})
+
+	args.TenantFromDirectory = true
+	ruleFiles, err = loadRuleFiles(args)
+	require.NoError(t, err)
+	require.Len(t, ruleFiles, 3)
+	require.Equal(t, "default", ruleFiles[ruleFileKey{tenant: "fallback", name: "default.yaml"}][0].Name)
+	require.Equal(t, "team_a", ruleFiles[ruleFileKey{tenant: "team-a", name: "alerts.yaml"}][0].Name)
+	require.Equal(t, "team_b", ruleFiles[ruleFileKey{tenant: "team-b", name: "nested/alerts.yml"}][0].Name)
+}
+
+func TestParseRuleFile(t *testing.T) {
+	_, err := parseRuleFile([]byte(testRules("valid")))
+	require.NoError(t, err)
+
+	_, err = parseRuleFile([]byte(`groups:
+- name: invalid
+  rules:
+  - alert: HighErrorRate
+    expr: sum(rate({app="foo"} |= "error" [5m]) > 10
+`))
+	require.ErrorContains(t, err, "could not parse expression for alert 'HighErrorRate' in group 'invalid'")
+}
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "team-a", "alerts.yaml"), testRules("team_a"))
+
+	args := DefaultArguments
+	args.Address = "http://localhost:3100"
+	args.Path = dir
+	args.TenantFromDirectory = true
+	args.RuleFiles = []RuleFile{{
+		Name:     "shared",
+		Content:  alloytypes.OptionalSecret{Value: testRules("shared")},
+		TenantID: "team-b",
+	}}
+
+	c, ruler := newComponentForTesting(t, args)
+	// Namespaces not managed by the component must be left as is.
+	ruler.tenant("team-a").rules["unmanaged"] = []rulefmt.RuleGroup{{Name: "unmanaged"}}
+
+	c.reconcile(t.Context(), true)
+	require.NoError(t, c.lastErr)
+	require.Equal(t, []string{"alloy-file-alerts.yaml", "unmanaged"}, ruler.tenant("team-a").namespaces())
+	require.Equal(t, []string{"alloy-file-shared"}, ruler.tenant("team-b").namespaces())
+
+	// Removed files are removed from the ruler, even when their tenant has no
+	// rule files left.
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "team-a")))
+	c.reconcile(t.Context(), false)
+	require.NoError(t, c.lastErr)
+	require.Equal(t, []string{"unmanaged"}, ruler.tenant("team-a").namespaces())
+	require.Equal(t, []string{"alloy-file-shared"}, ruler.tenant("team-b").namespaces())
+
+	// Invalid rule files make the component unhealthy and leave the ruler as is.
+	writeFile(t, filepath.Join(dir, "team-b", "invalid.yaml"), "groups: [")
+	c.reconcile(t.Context(), false)
+	require.Error(t, c.lastErr)
+	require.Equal(t, component.HealthTypeUnhealthy, c.CurrentHealth().Health)
+	require.Equal(t, []string{"alloy-file-shared"}, ruler.tenant("team-b").namespaces())
+
+	debugInfo := c.DebugInfo().(DebugInfo)
+	require.NotEmpty(t, debugInfo.Error)
+	require.Equal(t, []DebugLokiNamespace{{Name: "alloy-file-shared", TenantID: "team-b", NumRuleGroups: 1}}, debugInfo.LokiRuleNamespaces)
+}
+
+func TestDesiredState_NamespaceConflict(t *testing.T) {
+	groups := []rulefmt.RuleGroup{{Name: "group"}}
+	_, err := desiredState("alloy", map[ruleFileKey][]rulefmt.RuleGroup{
+		{name: "team-a/alerts.yaml"}: groups,
+		{name: "team-a-alerts.yaml"}: groups,
+	})
+	require.ErrorContains(t, err, `are both loaded into the Loki namespace "alloy-team-a-alerts.yaml"`)
+}
+
+func newComponentForTesting(t *testing.T, args Arguments) (*Component, *fakeRuler) {
+	opts := component.Options{
+		ID:         "loki.rules.file",
+		Logger:     util.TestLogger(t),
+		Registerer: prometheus.NewRegistry(),
+	}
+
+	c, err := NewComponent(opts, args)
+	require.NoError(t, err)
+
+	ruler := &fakeRuler{tenants: make(map[string]*fakeLokiClient)}
+	c.clients = make(map[string]lokiClient.Interface)
+	c.newClient = func(_ Arguments, tenant string) (lokiClient.Interface, error) {
+		return ruler.tenant(tenant), nil
+	}
+	return c, ruler
+}
+
+func testRules(group string) string {
+	return fmt.Sprintf(`groups:
+- name: %s
+  rules:
+  - alert: HighErrorRate
+    expr: sum(rate({app="foo"} |= "error" [5m])) > 10
+`, group)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// fakeRuler holds the rules of each tenant.
+type fakeRuler struct {
+	mut     sync.Mutex
+	tenants map[string]*fakeLokiClient
+}
+
+func (r *fakeRuler) tenant(tenant string) *fakeLokiClient {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	if _, ok := r.tenants[tenant]; !ok {
+		r.tenants[tenant] = &fakeLokiClient{rules: make(map[string][]rulefmt.RuleGroup)}
+	}
+	return r.tenants[tenant]
+}
+
+type fakeLokiClient struct {
+	rulesMut sync.RWMutex
+	rules    map[string][]rulefmt.RuleGroup
+}
+
+var _ lokiClient.Interface = &fakeLokiClient{}
+
+func (m *fakeLokiClient) CreateRuleGroup(_ context.Context, namespace string, rule rulefmt.RuleGroup) error {
+	m.rulesMut.Lock()
+	defer m.rulesMut.Unlock()
+	m.deleteLocked(namespace, rule.Name)
+	m.rules[namespace] = append(m.rules[namespace], rule)
+	return nil
+}
+
+func (m *fakeLokiClient) DeleteRuleGroup(_ context.Context, namespace, group string) error {
+	m.rulesMut.Lock()
+	defer m.rulesMut.Unlock()
+	m.deleteLocked(namespace, group)
+	return nil
+}
+
+func (m *fakeLokiClient) deleteLocked(namespace, group string) {
+	for i, g := range m.rules[namespace] {
+		if g.Name == group {
+			m.rules[namespace] = append(m.rules[namespace][:i], m.rules[namespace][i+1:]...)
+			if len(m.rules[namespace]) == 0 {
+				delete(m.rules, namespace)
+			}
+			return
+		}
+	}
+}
+
+func (m *fakeLokiClient) ListRules(_ context.Context, namespace string) (map[string][]rulefmt.RuleGroup, error) {
+	m.rulesMut.RLock()
+	defer m.rulesMut.RUnlock()
+	output := make(map[string][]rulefmt.RuleGroup)
+	for ns, v := range m.rules {
+		if namespace != "" && namespace != ns {
+			continue
+		}
+		output[ns] = v
+	}
+	return output, nil
+}
+
+func (m *fakeLokiClient) namespaces() []string {
+	m.rulesMut.RLock()
+	defer m.rulesMut.RUnlock()
+	var out []string
+	for ns := range m.rules {
+		out = append(out, ns)
+	}
+	slices.Sort(out)
+	return out
+}
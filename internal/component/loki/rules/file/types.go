@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+type Arguments struct {
+	Address             string                  `alloy:"address,attr"`
+	TenantID            string                  `alloy:"tenant_id,attr,optional"`
+	UseLegacyRoutes     bool                    `alloy:"use_legacy_routes,attr,optional"`
+	HTTPClientConfig    config.HTTPClientConfig `alloy:",squash"`
+	SyncInterval        time.Duration           `alloy:"sync_interval,attr,optional"`
+	LokiNameSpacePrefix string                  `alloy:"loki_namespace_prefix,attr,optional"`
+
+	Path                string        `alloy:"path,attr,optional"`
+	PollFrequency       time.Duration `alloy:"poll_frequency,attr,optional"`
+	TenantFromDirectory bool          `alloy:"tenant_from_directory,attr,optional"`
+	RuleFiles           []RuleFile    `alloy:"rule_file,block,optional"`
+}
+
+// RuleFile holds the contents of a rule file which is loaded into its own
+// Loki namespace.
+type RuleFile struct {
+	Name     string                    `alloy:"name,attr"`
+	Content  alloytypes.OptionalSecret `alloy:"content,attr"`
+	TenantID string                    `alloy:"tenant_id,attr,optional"`
+}
+
+var DefaultArguments = Arguments{
+	SyncInterval:        30 * time.Second,
+	PollFrequency:       time.Minute,
+	LokiNameSpacePrefix: "alloy-file",
+	HTTPClientConfig:    config.DefaultHTTPClientConfig,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.SyncInterval <= 0 {
+		return fmt.Errorf("sync_interval must be greater than 0")
+	}
+	if args.PollFrequency <= 0 {
+		return fmt.Errorf("poll_frequency must be greater than 0")
+	}
+	if args.LokiNameSpacePrefix == "" {
+		return fmt.Errorf("loki_namespace_prefix must not be empty")
+	}
+	if args.Path == "" && len(args.RuleFiles) == 0 {
+		return fmt.Errorf("at least one of path or rule_file must be provided")
+	}
+	if args.TenantFromDirectory && args.Path == "" {
+		return fmt.Errorf("tenant_from_directory requires path to be set")
+	}
+
+	names := make(map[ruleFileKey]struct{}, len(args.RuleFiles))
+	for _, rf := range args.RuleFiles {
+		if rf.Name == "" {
+			return fmt.Errorf("rule_file name must not be empty")
+		}
+		key := ruleFileKey{tenant: rf.tenant(*args), name: rf.Name}
+		if _, ok := names[key]; ok {
+			return fmt.Errorf("rule_file name %q is used more than once for tenant %q", rf.Name, key.tenant)
+		}
+		names[key] = struct{}{}
+	}
+
+	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
+	return args.HTTPClientConfig.Validate()
+}
+
+// tenant returns the tenant the rule file is loaded for.
+func (rf RuleFile) tenant(args Arguments) string {
+	if rf.TenantID != "" {
+		return rf.TenantID
+	}
+	return args.TenantID
+}
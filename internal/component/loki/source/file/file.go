@@ -138,9 +138,14 @@ func (d DecompressionConfig) GetFormat() string {
 
 func supportedCompressedFormats() map[string]struct{} {
 	return map[string]struct{}{
-		"gz":  {},
-		"z":   {},
-		"bz2": {},
+		"gz":   {},
+		"z":    {},
+		"bz2":  {},
+		"zstd": {},
+		"xz":   {},
+		"lz4":  {},
+		// auto detects the format from the first bytes of the file.
+		"auto": {},
 		// TODO: add support for zip.
 	}
 }
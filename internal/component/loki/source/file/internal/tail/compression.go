@@ -0,0 +1,137 @@
+package tail
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// Supported compression formats.
+const (
+	compressionGzip  = "gz"
+	compressionZlib  = "z"
+	compressionBzip2 = "bz2"
+	compressionZstd  = "zstd"
+	compressionXz    = "xz"
+	compressionLz4   = "lz4"
+
+	// compressionAuto detects the compression format from the first bytes
+	// of the file. Files that don't start with a known magic number are
+	// read as uncompressed files.
+	compressionAuto = "auto"
+)
+
+// Magic numbers of the compression formats that can be detected. zlib
+// streams don't have one and are detected from their header instead.
+var (
+	magicGzip  = []byte{0x1F, 0x8B}
+	magicBzip2 = []byte("BZh")
+	magicZstd  = []byte{0x28, 0xB5, 0x2F, 0xFD}
+	magicXz    = []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}
+	magicLz4   = []byte{0x04, 0x22, 0x4D, 0x18}
+)
+
+// A bzip2 stream header is followed by the magic of its first block, or by
+// the end of stream magic if the stream is empty.
+var (
+	magicBzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	magicBzip2End   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// detectCompression returns the compression format of the content read from r
+// or an empty string if it is not compressed. It is important that the reader
+// and underlying file are positioned at the beginning of the file
+// when calling this function, as it reads the first 10 bytes to detect the format.
+func detectCompression(r io.Reader) (string, error) {
+	buf := make([]byte, bzip2HeaderLen)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	buf = buf[:n]
+
+	switch {
+	case bytes.HasPrefix(buf, magicGzip):
+		return compressionGzip, nil
+	case isBzip2Header(buf):
+		return compressionBzip2, nil
+	case bytes.HasPrefix(buf, magicZstd):
+		return compressionZstd, nil
+	case bytes.HasPrefix(buf, magicXz):
+		return compressionXz, nil
+	case bytes.HasPrefix(buf, magicLz4):
+		return compressionLz4, nil
+	case isZlibHeader(buf):
+		return compressionZlib, nil
+	default:
+		return "", nil
+	}
+}
+
+// bzip2HeaderLen is the length of the bzip2 stream header, followed by the
+// magic of the first block.
+const bzip2HeaderLen = 10
+
+// isBzip2Header reports whether buf starts with a bzip2 stream header. The
+// "BZh" magic alone is too likely to match the first bytes of an
+// uncompressed file, so the block size and the magic of the first block are
+// checked too.
+func isBzip2Header(buf []byte) bool {
+	if len(buf) < bzip2HeaderLen || !bytes.HasPrefix(buf, magicBzip2) {
+		return false
+	}
+	if buf[3] < '1' || buf[3] > '9' {
+		return false
+	}
+	block := buf[4:bzip2HeaderLen]
+	return bytes.Equal(block, magicBzip2Block) || bytes.Equal(block, magicBzip2End)
+}
+
+// isZlibHeader reports whether buf starts with one of the zlib headers written
+// with the default 32K window. Other valid headers are ignored because they
+// are too likely to match the first bytes of an uncompressed file.
+func isZlibHeader(buf []byte) bool {
+	if len(buf) < 2 || buf[0] != 0x78 {
+		return false
+	}
+	switch buf[1] {
+	case 0x01, 0x5E, 0x9C, 0xDA:
+		return true
+	default:
+		return false
+	}
+}
+
+// newDecompressor wraps r with a reader that decompresses the given format.
+func newDecompressor(r io.Reader, compression string) (io.Reader, error) {
+	switch compression {
+	case compressionGzip:
+		return gzip.NewReader(r)
+	case compressionZlib:
+		return zlib.NewReader(r)
+	case compressionBzip2:
+		return bzip2.NewReader(r), nil
+	case compressionZstd:
+		// With a concurrency of 1 the decoder doesn't start any goroutine so
+		// it doesn't need to be closed.
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	case compressionXz:
+		return xz.NewReader(r)
+	case compressionLz4:
+		return lz4.NewReader(r), nil
+	default:
+		return r, nil
+	}
+}
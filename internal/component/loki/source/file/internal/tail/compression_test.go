@@ -0,0 +1,114 @@
+package tail
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCompression(t *testing.T) {
+	for _, compression := range []string{"gz", "z", "zstd", "xz", "lz4"} {
+		t.Run(compression, func(t *testing.T) {
+			name := createCompressedFile(t, compression, compression, strings.NewReader("line1\nline2\n"))
+			defer removeFile(t, name)
+
+			content, err := os.ReadFile(name)
+			require.NoError(t, err)
+
+			detected, err := detectCompression(bytes.NewReader(content))
+			require.NoError(t, err)
+			require.Equal(t, compression, detected)
+		})
+	}
+
+	t.Run("bz2", func(t *testing.T) {
+		detected, err := detectCompression(strings.NewReader("BZh91AY&SY"))
+		require.NoError(t, err)
+		require.Equal(t, "bz2", detected)
+	})
+
+	t.Run("uncompressed starting with bz2 magic", func(t *testing.T) {
+		detected, err := detectCompression(strings.NewReader("BZh lines are not compressed\n"))
+		require.NoError(t, err)
+		require.Equal(t, "", detected)
+
+		detected, err = detectCompression(strings.NewReader("BZh91 not compressed\n"))
+		require.NoError(t, err)
+		require.Equal(t, "", detected)
+	})
+
+	t.Run("uncompressed", func(t *testing.T) {
+		detected, err := detectCompression(strings.NewReader("line1\nline2\n"))
+		require.NoError(t, err)
+		require.Equal(t, "", detected)
+	})
+
+	t.Run("short content", func(t *testing.T) {
+		detected, err := detectCompression(strings.NewReader("x"))
+		require.NoError(t, err)
+		require.Equal(t, "", detected)
+
+		detected, err = detectCompression(strings.NewReader(""))
+		require.NoError(t, err)
+		require.Equal(t, "", detected)
+	})
+}
+
+func TestFileAutoCompression(t *testing.T) {
+	for _, compression := range []string{"", "gz", "z", "zstd", "xz", "lz4"} {
+		t.Run("format "+compression, func(t *testing.T) {
+			name := createCompressedFile(t, "auto", compression, strings.NewReader("line1\nline2\nline3\n"))
+			defer removeFile(t, name)
+
+			file, err := NewFile(log.NewNopLogger(), &Config{
+				Filename:    name,
+				Compression: "auto",
+				Offset:      6,
+			})
+			require.NoError(t, err)
+			defer file.Stop()
+
+			verifyResult(t, file, &Line{Text: "line2", Offset: 12}, nil)
+			verifyResult(t, file, &Line{Text: "line3", Offset: 18}, nil)
+
+			// Compressed files are read once while uncompressed files are tailed.
+			if compression != "" {
+				verifyResult(t, file, nil, io.EOF)
+			}
+		})
+	}
+}
+
+func TestFileAutoCompressionRotated(t *testing.T) {
+	name := createFile(t, "rotated", "line1\n")
+	defer removeFile(t, name)
+
+	file, err := NewFile(log.NewNopLogger(), &Config{
+		Filename:    name,
+		Compression: "auto",
+		WatcherConfig: WatcherConfig{
+			MinPollFrequency: 50 * time.Millisecond,
+			MaxPollFrequency: 50 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+	defer file.Stop()
+
+	verifyResult(t, file, &Line{Text: "line1", Offset: 6}, nil)
+
+	// Replace the uncompressed file with a compressed one.
+	compressed := createCompressedFile(t, "rotated.gz", "gz", strings.NewReader("newline1\nnewline2\n"))
+	removeFile(t, name)
+	require.NoError(t, os.Rename(compressed, name))
+
+	// The new file is read once instead of being tailed.
+	verifyResult(t, file, &Line{Text: "newline1", Offset: 9}, nil)
+	verifyResult(t, file, &Line{Text: "newline2", Offset: 18}, nil)
+	verifyResult(t, file, nil, io.EOF)
+}
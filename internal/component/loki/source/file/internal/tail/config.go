@@ -20,7 +20,8 @@ type Config struct {
 	// and the file is assumed to be UTF-8.
 	Encoding string
 
-	// Compression used for file. Supported values are gz (gzip), z (zlib) and bz2 (bzip2).
+	// Compression used for file. Supported values are gz (gzip), z (zlib), bz2 (bzip2),
+	// zstd, xz and lz4. With auto the compression is detected from the content of the file.
 	Compression string
 
 	// WatcherConfig controls how the file system is polled for changes.
@@ -59,7 +59,7 @@ func NewFile(logger log.Logger, cfg *Config) (*File, error) {
 		ctx:       ctx,
 		cancel:    cancel,
 		signature: sig,
-		waitAtEOF: cfg.Compression == "",
+		waitAtEOF: reader.format == "",
 	}, nil
 }
 
@@ -338,6 +338,8 @@ func (f *File) reopen(truncated bool) error {
 			file.Close()
 			return err
 		}
+		// The new file may not use the same compression as the previous one.
+		f.waitAtEOF = f.reader.format == ""
 
 		break
 	}
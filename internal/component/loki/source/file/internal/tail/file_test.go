@@ -13,7 +13,10 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/unicode"
 )
@@ -438,6 +441,24 @@ func TestFile(t *testing.T) {
 		compressionTest(t, "utf-16le", "z", utf16leBOMEncoder, utf16offsets)
 	})
 
+	t.Run("read zstd", func(t *testing.T) {
+		compressionTest(t, "plain", "zstd", nopEncoder, utf8offsets)
+		compressionTest(t, "utf-16be", "zstd", utf16beBOMEncoder, utf16offsets)
+		compressionTest(t, "utf-16le", "zstd", utf16leBOMEncoder, utf16offsets)
+	})
+
+	t.Run("read xz", func(t *testing.T) {
+		compressionTest(t, "plain", "xz", nopEncoder, utf8offsets)
+		compressionTest(t, "utf-16be", "xz", utf16beBOMEncoder, utf16offsets)
+		compressionTest(t, "utf-16le", "xz", utf16leBOMEncoder, utf16offsets)
+	})
+
+	t.Run("read lz4", func(t *testing.T) {
+		compressionTest(t, "plain", "lz4", nopEncoder, utf8offsets)
+		compressionTest(t, "utf-16be", "lz4", utf16beBOMEncoder, utf16offsets)
+		compressionTest(t, "utf-16le", "lz4", utf16leBOMEncoder, utf16offsets)
+	})
+
 	t.Run("start from end", func(t *testing.T) {
 		startFromEndTest(t, "utf-8", nopEncoder, nopEncoder, false, 0, []Line{{Text: "line3", Offset: 18}})
 		startFromEndTest(t, "utf-16be", utf16beBOMEncoder, utf16beEncoder, false, 0, []Line{{Text: "line3", Offset: 38}})
@@ -605,6 +626,14 @@ func createCompressedFile(t *testing.T, name, compression string, reader io.Read
 		writer = gzip.NewWriter(f)
 	case "z":
 		writer = zlib.NewWriter(f)
+	case "zstd":
+		writer, err = zstd.NewWriter(f)
+		require.NoError(t, err)
+	case "xz":
+		writer, err = xz.NewWriter(f)
+		require.NoError(t, err)
+	case "lz4":
+		writer = lz4.NewWriter(f)
 	case "bz2":
 		// go std lib to not provide writer for bzip2.
 		t.Fatalf("bz2 unimplemented")
@@ -3,9 +3,6 @@ package tail
 import (
 	"bufio"
 	"bytes"
-	"compress/bzip2"
-	"compress/gzip"
-	"compress/zlib"
 	"errors"
 	"io"
 	"os"
@@ -22,7 +19,12 @@ const defaultBufSize = 4096
 // newReader creates a new reader that is used to read from file.
 // It is important that the provided file is positioned at the start of the file.
 func newReader(logger log.Logger, f *os.File, offset int64, enc encoding.Encoding, compression string, startFromEnd bool) (*reader, error) {
-	rr, err := newReaderAt(f, compression, 0)
+	format, err := resolveCompression(f, compression)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := newReaderAt(f, format, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -56,19 +58,21 @@ func newReader(logger log.Logger, f *os.File, offset int64, enc encoding.Encodin
 		offset = offsetAfterBOM
 	}
 
-	rr, err = newReaderAt(f, compression, offset)
+	rr, err = newReaderAt(f, format, offset)
 	if err != nil {
 		return nil, err
 	}
 
 	return &reader{
-		pos:     offset,
-		br:      bufio.NewReader(rr),
-		decoder: decoder,
-		nl:      nl,
-		lastNl:  nl[len(nl)-1],
-		cr:      cr,
-		pending: make([]byte, 0, defaultBufSize),
+		pos:         offset,
+		br:          bufio.NewReader(rr),
+		compression: compression,
+		format:      format,
+		decoder:     decoder,
+		nl:          nl,
+		lastNl:      nl[len(nl)-1],
+		cr:          cr,
+		pending:     make([]byte, 0, defaultBufSize),
 	}, nil
 }
 
@@ -77,7 +81,10 @@ type reader struct {
 	br      *bufio.Reader
 	pending []byte
 
+	// compression is the configured compression and format is the one
+	// used for the current file. They only differ when compression is "auto".
 	compression string
+	format      string
 	decoder     *encoding.Decoder
 
 	nl     []byte
@@ -163,51 +170,43 @@ func (r *reader) position() int64 {
 // reset prepares the reader for a new file handle, assuming the same encoding.
 // It is important that the provided file is positioned at the start of the file.
 func (r *reader) reset(f *os.File, offset int64) error {
-	rr, err := newReaderAt(f, r.compression, 0)
+	format, err := resolveCompression(f, r.compression)
+	if err != nil {
+		return err
+	}
+
+	rr, err := newReaderAt(f, format, 0)
 	if err != nil {
 		return err
 	}
 
 	offset, _ = detectBOM(rr, offset)
-	rr, err = newReaderAt(f, r.compression, offset)
+	rr, err = newReaderAt(f, format, offset)
 	if err != nil {
 		return err
 	}
 
 	r.br.Reset(rr)
+	r.format = format
 	r.pos = offset
 	r.pending = make([]byte, 0, defaultBufSize)
 	return nil
 }
 
 func newReaderAt(f *os.File, compression string, offset int64) (io.Reader, error) {
-	// NOTE: If compression is used we always need to read from the beginning.
-	if compression != "" {
-		if _, err := f.Seek(0, io.SeekStart); err != nil {
-			return nil, err
-		}
-	}
-
-	var (
-		reader io.Reader
-		err    error
-	)
-
-	switch compression {
-	case "gz":
-		reader, err = gzip.NewReader(f)
-	case "z":
-		reader, err = zlib.NewReader(f)
-	case "bz2":
-		reader = bzip2.NewReader(f)
-	default:
+	if compression == "" {
 		if _, err := f.Seek(offset, io.SeekStart); err != nil {
 			return nil, err
 		}
+		return f, nil
+	}
 
-		reader = f
+	// NOTE: If compression is used we always need to read from the beginning.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
 	}
 
+	reader, err := newDecompressor(f, compression)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +214,7 @@ func newReaderAt(f *os.File, compression string, offset int64) (io.Reader, error
 	// NOTE: If compression is used there is no easy way to seek to correct offset in the file
 	// because the offset we store is for uncompressed data. Instead we can discard until the correct
 	// offset.
-	if compression != "" && offset != 0 {
+	if offset != 0 {
 		if _, err := io.CopyN(io.Discard, reader, offset); err != nil {
 			return nil, err
 		}
@@ -223,3 +222,16 @@ func newReaderAt(f *os.File, compression string, offset int64) (io.Reader, error
 
 	return reader, nil
 }
+
+// resolveCompression returns the compression format to use for f. When
+// compression is "auto" the format is detected from the content of the file.
+func resolveCompression(f *os.File, compression string) (string, error) {
+	if compression != compressionAuto {
+		return compression, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return detectCompression(f)
+}
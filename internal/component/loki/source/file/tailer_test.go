@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -481,6 +482,47 @@ func TestTailer_CompressedOnelineFile(t *testing.T) {
 		require.Equal(t, string(fileContent), entries[0].Line)
 	})
 
+	for _, tc := range []struct {
+		file   string
+		format CompressionFormat
+	}{
+		{file: "testdata/onelinelog.log.zst", format: "zstd"},
+		{file: "testdata/onelinelog.log.xz", format: "xz"},
+		{file: "testdata/onelinelog.log.lz4", format: "lz4"},
+		{file: "testdata/onelinelog.log.gz", format: "auto"},
+		{file: "testdata/onelinelog.log.bz2", format: "auto"},
+		{file: "testdata/onelinelog.log.zst", format: "auto"},
+		{file: "testdata/onelinelog.log.xz", format: "auto"},
+		{file: "testdata/onelinelog.log.lz4", format: "auto"},
+	} {
+		t.Run(fmt.Sprintf("%s file with %s format", filepath.Ext(tc.file), tc.format), func(t *testing.T) {
+			handler := loki.NewCollectingHandler()
+			defer handler.Stop()
+
+			tailer := newTailer(
+				newMetrics(prometheus.NewRegistry()),
+				log.NewNopLogger(),
+				handler.Receiver(),
+				positions.NewNop(),
+				func() bool { return false },
+				sourceOptions{
+					path:                tc.file,
+					decompressionConfig: DecompressionConfig{Enabled: true, Format: tc.format},
+				},
+			)
+
+			// We expect tailer to exit when all compressed data have been consumed.
+			tailer.Run(t.Context())
+
+			require.Eventually(t, func() bool {
+				return len(handler.Received()) == 1
+			}, 2*time.Second, 50*time.Millisecond)
+
+			entries := handler.Received()
+			require.Equal(t, string(fileContent), entries[0].Line)
+		})
+	}
+
 	t.Run("tar.gz file", func(t *testing.T) {
 		file := "testdata/onelinelog.tar.gz"
 		handler := loki.NewCollectingHandler()
@@ -0,0 +1,507 @@
+package write
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/olekukonko/tablewriter"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/spf13/cobra"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/client"
+	"github.com/grafana/alloy/internal/component/common/loki/wal"
+	"github.com/grafana/alloy/internal/useragent"
+)
+
+// InstallTools installs command line utilities as subcommands of the provided
+// cmd.
+func InstallTools(cmd *cobra.Command) {
+	cmd.AddCommand(
+		walStatsCmd(),
+		walDumpCmd(),
+		walReplayCmd(),
+	)
+}
+
+func walStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "wal-stats [WAL directory]",
+		Short: "Collect stats on the WAL",
+		Long: `wal-stats reads a WAL directory and collects information on the streams and
+entries within it.
+
+"Invalid Refs" is the number of entries whose stream can't be found in the WAL.
+Entries of segments after the "Last Marked Segment" may not have been sent to
+the endpoints yet.`,
+		Args: cobra.ExactArgs(1),
+
+		Run: func(_ *cobra.Command, args []string) {
+			directory := walDirectory(args[0])
+
+			stats, err := calculateWALStats(directory)
+			if err != nil {
+				fmt.Printf("failed to get WAL stats: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("Oldest Entry:        %s\n", stats.From)
+			fmt.Printf("Newest Entry:        %s\n", stats.To)
+			fmt.Printf("Total Streams:       %d\n", len(stats.Streams))
+			fmt.Printf("Total Entries:       %d\n", stats.Entries)
+			fmt.Printf("Total Bytes:         %d\n", stats.Bytes)
+			fmt.Printf("Invalid Refs:        %d\n", stats.InvalidRefs)
+			fmt.Printf("First Segment:       %d\n", stats.FirstSegment)
+			fmt.Printf("Latest Segment:      %d\n", stats.LastSegment)
+			fmt.Printf("Last Marked Segment: %d\n", stats.LastMarkedSegment)
+
+			fmt.Printf("\nPer-stream stats:\n")
+
+			table := tablewriter.NewWriter(os.Stdout)
+			defer table.Render()
+
+			table.SetHeader([]string{"Stream", "Entries", "Bytes"})
+
+			for _, s := range stats.Streams {
+				table.Append([]string{s.Labels, fmt.Sprintf("%d", s.Entries), fmt.Sprintf("%d", s.Bytes)})
+			}
+		},
+	}
+}
+
+func walDumpCmd() *cobra.Command {
+	var filter entryFilter
+
+	cmd := &cobra.Command{
+		Use:   "wal-dump [WAL directory]",
+		Short: "Print the entries of the WAL as JSON lines",
+		Long: `wal-dump reads a WAL directory and prints its entries, one JSON object per
+line, in the order they were written. A stream selector and a time range can be
+used to filter the entries.
+
+Examples:
+
+Dump all entries of the WAL:
+
+wal-dump /tmp/wal
+
+
+Dump the entries of the 'app=api' streams of the last hour:
+
+wal-dump -s '{app="api"}' --from 2025-01-01T10:00:00Z --to 2025-01-01T11:00:00Z /tmp/wal
+`,
+		Args: cobra.ExactArgs(1),
+
+		Run: func(_ *cobra.Command, args []string) {
+			directory := walDirectory(args[0])
+			if err := filter.parse(); err != nil {
+				fmt.Printf("invalid filter: %v\n", err)
+				os.Exit(1)
+			}
+
+			out := bufio.NewWriter(os.Stdout)
+			if err := dumpWAL(out, directory, filter); err != nil {
+				_ = out.Flush()
+				fmt.Fprintf(os.Stderr, "failed to dump WAL: %v\n", err)
+				os.Exit(1)
+			}
+			if err := out.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to dump WAL: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	filter.addFlags(cmd)
+	return cmd
+}
+
+func walReplayCmd() *cobra.Command {
+	var (
+		filter entryFilter
+		opts   replayOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wal-replay --url URL [WAL directory]",
+		Short: "Push the entries of the WAL to a Loki endpoint",
+		Long: `wal-replay reads a WAL directory and pushes its entries to a Loki endpoint, in
+the order they were written. It is meant to send entries which were left in a
+copy of the WAL after an incident. A stream selector and a time range can be
+used to filter the entries.
+
+Credentials for basic authentication can be set in the URL.`,
+		Args: cobra.ExactArgs(1),
+
+		Run: func(_ *cobra.Command, args []string) {
+			directory := walDirectory(args[0])
+			if err := filter.parse(); err != nil {
+				fmt.Printf("invalid filter: %v\n", err)
+				os.Exit(1)
+			}
+
+			res, err := replayWAL(context.Background(), http.DefaultClient, directory, filter, opts)
+			fmt.Printf("Pushed Entries:      %d\n", res.Entries)
+			fmt.Printf("Pushed Bytes:        %d\n", res.Bytes)
+			fmt.Printf("Invalid Refs:        %d\n", res.InvalidRefs)
+			if err != nil {
+				fmt.Printf("failed to replay WAL: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	filter.addFlags(cmd)
+	cmd.Flags().StringVar(&opts.url, "url", "", "URL of the Loki push API, for example http://localhost:3100/loki/api/v1/push")
+	cmd.Flags().StringVar(&opts.tenantID, "tenant-id", "", "tenant ID to push the entries as, overrides the tenant of the entries")
+	cmd.Flags().StringVar(&opts.batchSize, "batch-size", "1MiB", "maximum size of the entries sent in a single request")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of each request")
+	must(cmd.MarkFlagRequired("url"))
+	return cmd
+}
+
+// walDirectory returns the directory of the WAL given on the command line,
+// which may also be the data directory of the component.
+func walDirectory(directory string) string {
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		fmt.Printf("%s does not exist\n", directory)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Printf("error getting wal: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Check if ./wal is a subdirectory, use that instead.
+	if _, err := os.Stat(filepath.Join(directory, "wal")); err == nil {
+		directory = filepath.Join(directory, "wal")
+	}
+	return directory
+}
+
+// entryFilter selects the entries of the WAL that are dumped or replayed.
+type entryFilter struct {
+	selector string
+	from, to string
+
+	matchers         []*labels.Matcher
+	fromTime, toTime time.Time
+}
+
+func (f *entryFilter) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.selector, "selector", "s", "{}", "stream selector to filter entries by")
+	cmd.Flags().StringVar(&f.from, "from", "", "only include entries at or after this time (RFC3339)")
+	cmd.Flags().StringVar(&f.to, "to", "", "only include entries before this time (RFC3339)")
+}
+
+func (f *entryFilter) parse() error {
+	var err error
+	if f.matchers, err = parser.ParseMetricSelector(f.selector); err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+	if f.from != "" {
+		if f.fromTime, err = time.Parse(time.RFC3339, f.from); err != nil {
+			return fmt.Errorf("invalid from time: %w", err)
+		}
+	}
+	if f.to != "" {
+		if f.toTime, err = time.Parse(time.RFC3339, f.to); err != nil {
+			return fmt.Errorf("invalid to time: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *entryFilter) matches(e loki.Entry) bool {
+	if !f.fromTime.IsZero() && e.Timestamp.Before(f.fromTime) {
+		return false
+	}
+	if !f.toTime.IsZero() && !e.Timestamp.Before(f.toTime) {
+		return false
+	}
+	for _, m := range f.matchers {
+		if !m.Matches(string(e.Labels[model.LabelName(m.Name)])) {
+			return false
+		}
+	}
+	return true
+}
+
+type walStats struct {
+	From, To          time.Time
+	Entries           int
+	Bytes             int
+	InvalidRefs       int
+	FirstSegment      int
+	LastSegment       int
+	LastMarkedSegment int
+	Streams           []*streamStats // Sorted by number of entries.
+}
+
+type streamStats struct {
+	Labels  string
+	Entries int
+	Bytes   int
+}
+
+func calculateWALStats(directory string) (*walStats, error) {
+	var (
+		stats   = &walStats{}
+		streams = make(map[string]*streamStats)
+		err     error
+	)
+
+	stats.FirstSegment, stats.LastSegment, err = wlog.Segments(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidRefs, err := wal.ReadEntries(directory, func(_ int, e loki.Entry) error {
+		if stats.From.IsZero() || e.Timestamp.Before(stats.From) {
+			stats.From = e.Timestamp
+		}
+		if e.Timestamp.After(stats.To) {
+			stats.To = e.Timestamp
+		}
+
+		size := entrySize(e.Entry)
+		stats.Entries++
+		stats.Bytes += size
+
+		lbls := e.Labels.String()
+		s, ok := streams[lbls]
+		if !ok {
+			s = &streamStats{Labels: lbls}
+			streams[lbls] = s
+		}
+		s.Entries++
+		s.Bytes += size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.InvalidRefs = invalidRefs
+
+	if stats.LastMarkedSegment, err = client.LastMarkedSegment(directory); err != nil {
+		return nil, err
+	}
+
+	for _, s := range streams {
+		stats.Streams = append(stats.Streams, s)
+	}
+	sort.Slice(stats.Streams, func(i, j int) bool {
+		if stats.Streams[i].Entries != stats.Streams[j].Entries {
+			return stats.Streams[i].Entries > stats.Streams[j].Entries
+		}
+		return stats.Streams[i].Labels < stats.Streams[j].Labels
+	})
+	return stats, nil
+}
+
+type dumpedEntry struct {
+	Segment            int               `json:"segment"`
+	Labels             model.LabelSet    `json:"labels"`
+	Timestamp          time.Time         `json:"timestamp"`
+	Line               string            `json:"line"`
+	StructuredMetadata map[string]string `json:"structured_metadata,omitempty"`
+}
+
+func dumpWAL(w io.Writer, directory string, filter entryFilter) error {
+	enc := json.NewEncoder(w)
+	_, err := wal.ReadEntries(directory, func(segment int, e loki.Entry) error {
+		if !filter.matches(e) {
+			return nil
+		}
+
+		de := dumpedEntry{
+			Segment:   segment,
+			Labels:    e.Labels,
+			Timestamp: e.Timestamp.UTC(),
+			Line:      e.Line,
+		}
+		if len(e.StructuredMetadata) > 0 {
+			de.StructuredMetadata = make(map[string]string, len(e.StructuredMetadata))
+			for _, l := range e.StructuredMetadata {
+				de.StructuredMetadata[l.Name] = l.Value
+			}
+		}
+		return enc.Encode(de)
+	})
+	return err
+}
+
+type replayOptions struct {
+	url       string
+	tenantID  string
+	batchSize string
+	timeout   time.Duration
+}
+
+type replayResult struct {
+	Entries     int
+	Bytes       int
+	InvalidRefs int
+}
+
+// replayBatch holds the streams of a tenant waiting to be pushed.
+type replayBatch struct {
+	streams map[string]*push.Stream
+	entries int
+	bytes   int
+}
+
+func replayWAL(ctx context.Context, httpClient *http.Client, directory string, filter entryFilter, opts replayOptions) (replayResult, error) {
+	var (
+		res     replayResult
+		batches = make(map[string]*replayBatch) // Keyed by tenant.
+	)
+
+	batchSize, err := units.ParseBase2Bytes(opts.batchSize)
+	if err != nil {
+		return res, fmt.Errorf("invalid batch size: %w", err)
+	}
+
+	send := func(tenantID string, b *replayBatch) error {
+		if err := pushStreams(ctx, httpClient, opts, tenantID, b.streams); err != nil {
+			return err
+		}
+		res.Entries += b.entries
+		res.Bytes += b.bytes
+		delete(batches, tenantID)
+		return nil
+	}
+
+	invalidRefs, err := wal.ReadEntries(directory, func(_ int, e loki.Entry) error {
+		if !filter.matches(e) {
+			return nil
+		}
+
+		tenantID := opts.tenantID
+		if tenantID == "" {
+			tenantID = string(e.Labels[client.ReservedLabelTenantID])
+		}
+		b, ok := batches[tenantID]
+		if !ok {
+			b = &replayBatch{streams: make(map[string]*push.Stream)}
+			batches[tenantID] = b
+		}
+
+		lbls := streamLabels(e.Labels)
+		if s, ok := b.streams[lbls]; ok {
+			s.Entries = append(s.Entries, e.Entry)
+		} else {
+			b.streams[lbls] = &push.Stream{Labels: lbls, Entries: []push.Entry{e.Entry}}
+		}
+		b.entries++
+		b.bytes += entrySize(e.Entry)
+
+		if b.bytes >= int(batchSize) {
+			return send(tenantID, b)
+		}
+		return nil
+	})
+	res.InvalidRefs = invalidRefs
+	if err != nil {
+		return res, err
+	}
+
+	tenants := make([]string, 0, len(batches))
+	for tenantID := range batches {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	for _, tenantID := range tenants {
+		if err := send(tenantID, batches[tenantID]); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func pushStreams(ctx context.Context, httpClient *http.Client, opts replayOptions, tenantID string, streams map[string]*push.Stream) error {
+	req := push.PushRequest{Streams: make([]push.Stream, 0, len(streams))}
+	for _, s := range streams {
+		req.Streams = append(req.Streams, *s)
+	}
+	buf, err := proto.Marshal(&req)
+	if err != nil {
+		return err
+	}
+	buf = snappy.Encode(nil, buf)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.url, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("User-Agent", useragent.Get())
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	if tenantID != "" {
+		httpReq.Header.Set("X-Scope-OrgID", tenantID)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		const maxErrMsgLen = 1024
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+		line := ""
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+		return fmt.Errorf("server returned HTTP status %s (%d): %s", resp.Status, resp.StatusCode, line)
+	}
+	return nil
+}
+
+// streamLabels returns the labels of a stream as sent to Loki, without the
+// internal labels.
+func streamLabels(ls model.LabelSet) string {
+	lbls := make(model.LabelSet, len(ls))
+	for name, value := range ls {
+		if !strings.HasPrefix(string(name), "__") {
+			lbls[name] = value
+		}
+	}
+	return lbls.String()
+}
+
+func entrySize(e push.Entry) int {
+	size := len(e.Line)
+	for _, l := range e.StructuredMetadata {
+		size += l.Size()
+	}
+	return size
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,171 @@
+package write
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/loki"
+	"github.com/grafana/alloy/internal/component/common/loki/wal"
+)
+
+var (
+	testWALStart = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Size of the entries of the app="api" stream, with their structured metadata.
+	testAPIEntrySize = len("request a") + (&push.LabelAdapter{Name: "trace_id", Value: "1234"}).Size()
+)
+
+// writeTestWAL writes entries of two streams to a WAL in a new directory.
+func writeTestWAL(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writer, err := wal.NewWriter(wal.Config{Dir: dir, Enabled: true}, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	writer.Start(wal.DefaultMaxSegmentAge)
+
+	for i := range 3 {
+		writer.Chan() <- loki.Entry{
+			Labels: model.LabelSet{"app": "api", "__tenant_id__": "team-a"},
+			Entry: push.Entry{
+				Timestamp:          testWALStart.Add(time.Duration(i) * time.Minute),
+				Line:               "request " + string(rune('a'+i)),
+				StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "1234"}},
+			},
+		}
+	}
+	writer.Chan() <- loki.Entry{
+		Labels: model.LabelSet{"app": "web"},
+		Entry:  push.Entry{Timestamp: testWALStart.Add(30 * time.Second), Line: "page view"},
+	}
+	writer.Stop()
+	return dir
+}
+
+func TestCalculateWALStats(t *testing.T) {
+	dir := writeTestWAL(t)
+
+	stats, err := calculateWALStats(dir)
+	require.NoError(t, err)
+
+	require.Equal(t, testWALStart, stats.From.UTC())
+	require.Equal(t, testWALStart.Add(2*time.Minute), stats.To.UTC())
+	require.Equal(t, 4, stats.Entries)
+	require.Equal(t, 3*testAPIEntrySize+9, stats.Bytes)
+	require.Equal(t, 0, stats.InvalidRefs)
+	require.Equal(t, 0, stats.FirstSegment)
+	require.Equal(t, 0, stats.LastSegment)
+	require.Equal(t, -1, stats.LastMarkedSegment)
+	require.Equal(t, []*streamStats{
+		{Labels: `{__tenant_id__="team-a", app="api"}`, Entries: 3, Bytes: 3 * testAPIEntrySize},
+		{Labels: `{app="web"}`, Entries: 1, Bytes: 9},
+	}, stats.Streams)
+}
+
+func TestDumpWAL(t *testing.T) {
+	dir := writeTestWAL(t)
+
+	filter := entryFilter{
+		selector: `{app="api"}`,
+		from:     "2025-01-01T10:01:00Z",
+		to:       "2025-01-01T10:02:00Z",
+	}
+	require.NoError(t, filter.parse())
+
+	var buf bytes.Buffer
+	require.NoError(t, dumpWAL(&buf, dir, filter))
+	require.Equal(t, `{"segment":0,"labels":{"__tenant_id__":"team-a","app":"api"},"timestamp":"2025-01-01T10:01:00Z","line":"request b","structured_metadata":{"trace_id":"1234"}}`+"\n", buf.String())
+}
+
+func TestReplayWAL(t *testing.T) {
+	dir := writeTestWAL(t)
+
+	var (
+		mut      sync.Mutex
+		requests = make(map[string][]push.Stream) // Keyed by tenant.
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req push.PushRequest
+		require.NoError(t, proto.Unmarshal(decoded, &req))
+
+		mut.Lock()
+		defer mut.Unlock()
+		requests[r.Header.Get("X-Scope-OrgID")] = append(requests[r.Header.Get("X-Scope-OrgID")], req.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	received := func() map[string][]push.Stream {
+		mut.Lock()
+		defer mut.Unlock()
+		res := maps.Clone(requests)
+		clear(requests)
+		return res
+	}
+
+	filter := entryFilter{selector: "{}"}
+	require.NoError(t, filter.parse())
+
+	t.Run("tenant of the entries", func(t *testing.T) {
+		res, err := replayWAL(t.Context(), srv.Client(), dir, filter, replayOptions{url: srv.URL, batchSize: "1MiB", timeout: time.Second})
+		require.NoError(t, err)
+		require.Equal(t, replayResult{Entries: 4, Bytes: 3*testAPIEntrySize + 9}, res)
+
+		requests := received()
+		require.Len(t, requests, 2)
+		require.Len(t, requests["team-a"], 1)
+		require.Equal(t, `{app="api"}`, requests["team-a"][0].Labels)
+		require.Len(t, requests["team-a"][0].Entries, 3)
+		require.Equal(t, "request a", requests["team-a"][0].Entries[0].Line)
+		require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "1234"}}, requests["team-a"][0].Entries[0].StructuredMetadata)
+		require.Len(t, requests[""], 1)
+		require.Equal(t, `{app="web"}`, requests[""][0].Labels)
+	})
+
+	t.Run("batches of a single tenant", func(t *testing.T) {
+		res, err := replayWAL(t.Context(), srv.Client(), dir, filter, replayOptions{url: srv.URL, tenantID: "restore", batchSize: "20B", timeout: time.Second})
+		require.NoError(t, err)
+		require.Equal(t, 4, res.Entries)
+
+		requests := received()
+		require.Len(t, requests, 1)
+		var lines []string
+		for _, s := range requests["restore"] {
+			for _, e := range s.Entries {
+				lines = append(lines, e.Line)
+			}
+		}
+		require.Equal(t, []string{"request a", "request b", "request c", "page view"}, lines)
+	})
+
+	t.Run("endpoint error", func(t *testing.T) {
+		errSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "too many outstanding requests", http.StatusTooManyRequests)
+		}))
+		defer errSrv.Close()
+
+		res, err := replayWAL(t.Context(), errSrv.Client(), dir, filter, replayOptions{url: errSrv.URL, batchSize: "1MiB", timeout: time.Second})
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), "too many outstanding requests"), err.Error())
+		require.Equal(t, 0, res.Entries)
+	})
+}
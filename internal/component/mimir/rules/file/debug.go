@@ -0,0 +1,50 @@
+package rules
+
+import "sort"
+
+type DebugInfo struct {
+	Error               string                `alloy:"error,attr,optional"`
+	RuleFiles           []DebugRuleFile       `alloy:"rule_file,block,optional"`
+	MimirRuleNamespaces []DebugMimirNamespace `alloy:"mimir_rule_namespace,block,optional"`
+}
+
+type DebugRuleFile struct {
+	Name          string `alloy:"name,attr"`
+	NumRuleGroups int    `alloy:"num_rule_groups,attr"`
+}
+
+type DebugMimirNamespace struct {
+	Name          string `alloy:"name,attr"`
+	NumRuleGroups int    `alloy:"num_rule_groups,attr"`
+}
+
+func (c *Component) DebugInfo() any {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+
+	var output DebugInfo
+	if c.lastErr != nil {
+		output.Error = c.lastErr.Error()
+	}
+
+	for namespace, groups := range c.currentState {
+		output.MimirRuleNamespaces = append(output.MimirRuleNamespaces, DebugMimirNamespace{
+			Name:          namespace,
+			NumRuleGroups: len(groups),
+		})
+	}
+	for name, groups := range c.ruleFiles {
+		output.RuleFiles = append(output.RuleFiles, DebugRuleFile{
+			Name:          name,
+			NumRuleGroups: len(groups),
+		})
+	}
+
+	sort.Slice(output.MimirRuleNamespaces, func(i, j int) bool {
+		return output.MimirRuleNamespaces[i].Name < output.MimirRuleNamespaces[j].Name
+	})
+	sort.Slice(output.RuleFiles, func(i, j int) bool {
+		return output.RuleFiles[i].Name < output.RuleFiles[j].Name
+	})
+	return output
+}
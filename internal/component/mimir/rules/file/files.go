@@ -0,0 +1,142 @@
+package rules
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/grafana/alloy/internal/component/common/kubernetes"
+	"github.com/grafana/alloy/internal/mimir/client"
+)
+
+// ruleFileExtensions holds the extensions of the files loaded from a
+// directory.
+var ruleFileExtensions = []string{".yaml", ".yml"}
+
+// loadRuleFiles reads the rule files found at path and the contents of
+// ruleFiles, and returns their rule groups indexed by rule file name.
+//
+// The name of a file found at path is its path relative to path when path is
+// a directory, or its base name otherwise.
+func loadRuleFiles(path string, ruleFiles []RuleFile) (map[string][]client.MimirRuleGroup, error) {
+	contents := make(map[string][]byte)
+	if path != "" {
+		if err := readRuleFiles(path, contents); err != nil {
+			return nil, err
+		}
+	}
+	for _, rf := range ruleFiles {
+		if _, ok := contents[rf.Name]; ok {
+			return nil, fmt.Errorf("rule file %q is both found in path and set in a rule_file block", rf.Name)
+		}
+		contents[rf.Name] = []byte(rf.Content.Value)
+	}
+
+	out := make(map[string][]client.MimirRuleGroup, len(contents))
+	for name, content := range contents {
+		groups, errs := client.Parse(content)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("failed to parse rule file %q: %w", name, multierror.Append(nil, errs...))
+		}
+		out[name] = groups.Groups
+	}
+	return out, nil
+}
+
+func readRuleFiles(path string, contents map[string][]byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule files: %w", err)
+	}
+	if !fi.IsDir() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		contents[filepath.Base(path)] = b
+		return nil
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read rule files: %w", err)
+		}
+		// Skip hidden files and directories, such as the .git directory of
+		// a repository checkout or the temporary files of editors.
+		if p != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isRuleFile(d.Name()) {
+			return nil
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		contents[filepath.ToSlash(rel)] = b
+		return nil
+	})
+}
+
+func isRuleFile(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range ruleFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// desiredState converts rule files to the Mimir rule groups they should be
+// loaded as, indexed by Mimir namespace.
+func desiredState(prefix string, ruleFiles map[string][]client.MimirRuleGroup, externalLabels map[string]string) kubernetes.MimirRuleGroupsByNamespace {
+	out := make(kubernetes.MimirRuleGroupsByNamespace, len(ruleFiles))
+	for name, groups := range ruleFiles {
+		if len(groups) == 0 {
+			continue
+		}
+
+		if len(externalLabels) > 0 {
+			for _, ruleGroup := range groups {
+				// Refer to the slice element via its index,
+				// to make sure we mutate on the original and not a copy.
+				for i := range ruleGroup.Rules {
+					if ruleGroup.Rules[i].Labels == nil {
+						ruleGroup.Rules[i].Labels = make(map[string]string, len(externalLabels))
+					}
+					maps.Copy(ruleGroup.Rules[i].Labels, externalLabels)
+				}
+			}
+		}
+
+		out[mimirNamespaceForRuleFile(prefix, name)] = groups
+	}
+	return out
+}
+
+// mimirNamespaceForRuleFile returns the namespace that the rule file should
+// be stored in Mimir. This function, along with isManagedMimirNamespace, is
+// used to determine if a rule file is managed by Alloy.
+func mimirNamespaceForRuleFile(prefix, name string) string {
+	return prefix + "/" + name
+}
+
+// isManagedMimirNamespace returns true if the namespace is managed by Alloy.
+// Unmanaged namespaces are left as is by the component.
+func isManagedMimirNamespace(prefix, namespace string) bool {
+	return strings.HasPrefix(namespace, prefix+"/")
+}
@@ -0,0 +1,354 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/ckit/shard"
+	"github.com/grafana/dskit/instrument"
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/kubernetes"
+	"github.com/grafana/alloy/internal/featuregate"
+	mimirClient "github.com/grafana/alloy/internal/mimir/client"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "mimir.rules.file",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   nil,
+		Build: func(o component.Options, c component.Arguments) (component.Component, error) {
+			return New(o, c.(Arguments))
+		},
+	})
+}
+
+// Component loads Prometheus rule files into the Mimir ruler. Each rule file
+// is stored in its own Mimir namespace.
+type Component struct {
+	log     log.Logger
+	opts    component.Options
+	cluster cluster.Cluster
+
+	mut         sync.Mutex
+	args        Arguments
+	mimirClient mimirClient.RulerInterface
+
+	configUpdates  chan struct{}
+	clusterUpdates chan struct{}
+
+	stateMut     sync.RWMutex
+	ruleFiles    map[string][]mimirClient.MimirRuleGroup
+	currentState kubernetes.MimirRuleGroupsByNamespace
+	lastErr      error
+
+	metrics   *metrics
+	healthMut sync.RWMutex
+	health    component.Health
+}
+
+type metrics struct {
+	configUpdatesTotal  prometheus.Counter
+	clusterUpdatesTotal prometheus.Counter
+
+	reconcilesTotal  prometheus.Counter
+	reconcilesFailed prometheus.Counter
+
+	mimirClientTiming *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		configUpdatesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "mimir_rules",
+			Name:      "config_updates_total",
+			Help:      "Total number of times the configuration has been updated.",
+		}),
+		clusterUpdatesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "mimir_rules",
+			Name:      "cluster_updates_total",
+			Help:      "Total number of times the cluster has changed.",
+		}),
+		reconcilesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "mimir_rules",
+			Name:      "reconciles_total",
+			Help:      "Total number of times the rule files have been reconciled with the Mimir ruler.",
+		}),
+		reconcilesFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: "mimir_rules",
+			Name:      "reconciles_failed_total",
+			Help:      "Total number of times the rule files failed to be reconciled with the Mimir ruler.",
+		}),
+		mimirClientTiming: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Subsystem: "mimir_rules",
+			Name:      "mimir_client_request_duration_seconds",
+			Help:      "Duration of requests to the Mimir API.",
+			Buckets:   instrument.DefBuckets,
+		}, instrument.HistogramCollectorBuckets),
+	}
+}
+
+func (m *metrics) register(r prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{
+		m.configUpdatesTotal,
+		m.clusterUpdatesTotal,
+		m.reconcilesTotal,
+		m.reconcilesFailed,
+		m.mimirClientTiming,
+	} {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _ component.Component = (*Component)(nil)
+var _ component.DebugComponent = (*Component)(nil)
+var _ component.HealthComponent = (*Component)(nil)
+var _ cluster.Component = (*Component)(nil)
+
+// New creates a new Component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	m := newMetrics()
+	if err := m.register(o.Registerer); err != nil {
+		return nil, fmt.Errorf("registering metrics failed: %w", err)
+	}
+
+	clusterSvc, err := o.GetServiceData(cluster.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("getting cluster service failed: %w", err)
+	}
+
+	c := &Component{
+		log:            o.Logger,
+		opts:           o,
+		cluster:        clusterSvc.(cluster.Cluster),
+		args:           args,
+		configUpdates:  make(chan struct{}, 1),
+		clusterUpdates: make(chan struct{}, 1),
+		metrics:        m,
+	}
+	c.mimirClient, err = c.newMimirClient(args)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Component) Run(ctx context.Context) error {
+	args := c.getArgs()
+	syncTicker := time.NewTicker(args.SyncInterval)
+	defer syncTicker.Stop()
+	pollTicker := time.NewTicker(args.PollFrequency)
+	defer pollTicker.Stop()
+
+	c.reconcile(ctx, true)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.configUpdates:
+			c.metrics.configUpdatesTotal.Inc()
+			args = c.getArgs()
+			syncTicker.Reset(args.SyncInterval)
+			pollTicker.Reset(args.PollFrequency)
+			c.reconcile(ctx, true)
+		case <-c.clusterUpdates:
+			c.metrics.clusterUpdatesTotal.Inc()
+			c.reconcile(ctx, true)
+		case <-syncTicker.C:
+			c.reconcile(ctx, true)
+		case <-pollTicker.C:
+			c.reconcile(ctx, false)
+		}
+	}
+}
+
+func (c *Component) Update(newConfig component.Arguments) error {
+	args := newConfig.(Arguments)
+
+	client, err := c.newMimirClient(args)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	c.args = args
+	c.mimirClient = client
+	c.mut.Unlock()
+
+	select {
+	case c.configUpdates <- struct{}{}:
+	default: // update already scheduled
+	}
+	return nil
+}
+
+func (c *Component) newMimirClient(args Arguments) (*mimirClient.MimirClient, error) {
+	return mimirClient.New(c.log, mimirClient.Config{
+		ID:                   args.TenantID,
+		Address:              args.Address,
+		UseLegacyRoutes:      args.UseLegacyRoutes,
+		PrometheusHTTPPrefix: args.PrometheusHTTPPrefix,
+		HTTPClientConfig:     *args.HTTPClientConfig.Convert(),
+	}, c.metrics.mimirClientTiming)
+}
+
+func (c *Component) NotifyClusterChange() {
+	select {
+	case c.clusterUpdates <- struct{}{}:
+	default: // update already scheduled
+	}
+}
+
+func (c *Component) getArgs() Arguments {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.args
+}
+
+// reconcile loads the rule files and applies their changes to the Mimir
+// ruler. The state of the ruler is first synced when syncMimir is true, or
+// when it was never synced before.
+func (c *Component) reconcile(ctx context.Context, syncMimir bool) {
+	c.metrics.reconcilesTotal.Inc()
+
+	err := c.reconcileState(ctx, syncMimir)
+
+	c.stateMut.Lock()
+	c.lastErr = err
+	c.stateMut.Unlock()
+
+	if err != nil {
+		c.metrics.reconcilesFailed.Inc()
+		level.Error(c.log).Log("msg", "failed to reconcile rule files", "err", err)
+		c.reportUnhealthy(err)
+		return
+	}
+	c.reportHealthy()
+}
+
+func (c *Component) reconcileState(ctx context.Context, syncMimir bool) error {
+	c.mut.Lock()
+	args, client := c.args, c.mimirClient
+	c.mut.Unlock()
+
+	ruleFiles, err := loadRuleFiles(args.Path, args.RuleFiles)
+	if err != nil {
+		return err
+	}
+	c.stateMut.Lock()
+	c.ruleFiles = ruleFiles
+	c.stateMut.Unlock()
+
+	// NOTE that we use cluster updates and ownership of a particular key to
+	// implement our own leadership election, so that only a single instance
+	// of the cluster updates the Mimir ruler.
+	if leader, err := c.isLeader(); err != nil {
+		return err
+	} else if !leader {
+		level.Debug(c.log).Log("msg", "skipping reconciliation because we are not the leader")
+		return nil
+	}
+
+	if syncMimir || c.getMimirState() == nil {
+		if err := c.syncMimir(ctx, client, args.MimirNameSpacePrefix); err != nil {
+			return err
+		}
+	}
+
+	desired := desiredState(args.MimirNameSpacePrefix, ruleFiles, args.ExternalLabels)
+	diffs := kubernetes.DiffMimirRuleGroupState(desired, c.getMimirState())
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	var errs error
+	for ns, diff := range diffs {
+		if err := c.applyChanges(ctx, client, ns, diff); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	// resync mimir state after applying changes
+	if err := c.syncMimir(ctx, client, args.MimirNameSpacePrefix); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	return errs
+}
+
+func (c *Component) isLeader() (bool, error) {
+	// NOTE: since this is leader election, it is okay to NOT check if cluster is ready.
+	peers, err := c.cluster.Lookup(shard.StringKey(c.opts.ID), 1, shard.OpReadWrite)
+	if err != nil {
+		return false, fmt.Errorf("unable to determine leader for %s: %w", c.opts.ID, err)
+	}
+	if len(peers) != 1 {
+		return false, fmt.Errorf("unexpected peers from leadership check: %+v", peers)
+	}
+	return peers[0].Self, nil
+}
+
+func (c *Component) syncMimir(ctx context.Context, client mimirClient.RulerInterface, prefix string) error {
+	rulesByNamespace, err := client.ListRules(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list rules from mimir: %w", err)
+	}
+
+	for ns := range rulesByNamespace {
+		if !isManagedMimirNamespace(prefix, ns) {
+			delete(rulesByNamespace, ns)
+		}
+	}
+
+	c.stateMut.Lock()
+	c.currentState = rulesByNamespace
+	c.stateMut.Unlock()
+	return nil
+}
+
+// getMimirState returns the cached Mimir ruler state, rule groups indexed by Mimir namespace.
+func (c *Component) getMimirState() kubernetes.MimirRuleGroupsByNamespace {
+	c.stateMut.RLock()
+	defer c.stateMut.RUnlock()
+	return c.currentState
+}
+
+func (c *Component) applyChanges(ctx context.Context, client mimirClient.RulerInterface, namespace string, diffs []kubernetes.MimirRuleGroupDiff) error {
+	for _, diff := range diffs {
+		switch diff.Kind {
+		case kubernetes.RuleGroupDiffKindAdd:
+			err := client.CreateRuleGroup(ctx, namespace, diff.Desired)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "added rule group", "namespace", namespace, "group", diff.Desired.Name)
+		case kubernetes.RuleGroupDiffKindRemove:
+			err := client.DeleteRuleGroup(ctx, namespace, diff.Actual.Name)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "removed rule group", "namespace", namespace, "group", diff.Actual.Name)
+		case kubernetes.RuleGroupDiffKindUpdate:
+			err := client.CreateRuleGroup(ctx, namespace, diff.Desired)
+			if err != nil {
+				return err
+			}
+			level.Info(c.log).Log("msg", "updated rule group", "namespace", namespace, "group", diff.Desired.Name)
+		default:
+			level.Error(c.log).Log("msg", "unknown rule group diff kind", "kind", diff.Kind)
+		}
+	}
+	return nil
+}
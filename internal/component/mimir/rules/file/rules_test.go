@@ -0,0 +1,286 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/mimir/client"
+	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func TestAlloyConfigs(t *testing.T) {
+	var testCases = []struct {
+		name                  string
+		config                string
+		expectedErrorContains string
+	}{
+		{
+			name: "path",
+			config: `
+	address = "GRAFANA_CLOUD_METRICS_URL"
+	path    = "/etc/alloy/rules"`,
+		},
+		{
+			name: "rule files",
+			config: `
+	address = "GRAFANA_CLOUD_METRICS_URL"
+	rule_file {
+		name    = "team-a"
+		content = "groups: []"
+	}
+	rule_file {
+		name    = "team-b"
+		content = "groups: []"
+	}`,
+		},
+		{
+			name: "no rules",
+			config: `
+	address = "GRAFANA_CLOUD_METRICS_URL"`,
+			expectedErrorContains: "at least one of path or rule_file must be provided",
+		},
+		{
+			name: "duplicate rule file",
+			config: `
+	address = "GRAFANA_CLOUD_METRICS_URL"
+	rule_file {
+		name    = "team-a"
+		content = "groups: []"
+	}
+	rule_file {
+		name    = "team-a"
+		content = "groups: []"
+	}`,
+			expectedErrorContains: `rule_file name "team-a" is used more than once`,
+		},
+		{
+			name: "invalid poll frequency",
+			config: `
+	address        = "GRAFANA_CLOUD_METRICS_URL"
+	path           = "/etc/alloy/rules"
+	poll_frequency = "0s"`,
+			expectedErrorContains: "poll_frequency must be greater than 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tc.config), &args)
+			if tc.expectedErrorContains == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErrorContains)
+			}
+		})
+	}
+}
+
+func TestLoadRuleFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "team-a.yaml"), testRules("team_a"))
+	writeFile(t, filepath.Join(dir, "nested", "team-b.yml"), testRules("team_b"))
+	writeFile(t, filepath.Join(dir, "README.md"), "not a rule file")
+	writeFile(t, filepath.Join(dir, ".git", "config.yaml"), "not a rule file")
+
+	ruleFiles, err := loadRuleFiles(dir, []RuleFile{
+		{Name: "team-c", Content: alloytypes.OptionalSecret{Value: testRules("team_c")}},
+	})
+	require.NoError(t, err)
+	require.Len(t, ruleFiles, 3)
+	require.Equal(t, "team_a", ruleFiles["team-a.yaml"][0].Name)
+	require.Equal(t, "team_b", ruleFiles["nested/team-b.yml"][0].Name)
+	require.Equal(t, "team_c", ruleFiles["team-c"][0].Name)
+
+	// A single file is loaded under its base name.
+	ruleFiles, err = loadRuleFiles(filepath.Join(dir, "team-a.yaml"), nil)
+	require.NoError(t, err)
+	require.Len(t, ruleFiles, 1)
+	require.Contains(t, ruleFiles, "team-a.yaml")
+
+	// Invalid rule files are rejected.
+	_, err = loadRuleFiles("", []RuleFile{
+		{Name: "invalid", Content: alloytypes.OptionalSecret{Value: "groups:\n- rules: []\n"}},
+	})
+	require.ErrorContains(t, err, `failed to parse rule file "invalid"`)
+}
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "team-a.yaml"), testRules("team_a"))
+
+	args := DefaultArguments
+	args.Address = "http://localhost:8080"
+	args.Path = dir
+	args.ExternalLabels = map[string]string{"cluster": "prod"}
+
+	c := newComponentForTesting(t, args, true)
+	fake := c.mimirClient.(*fakeMimirClient)
+	// Namespaces not managed by the component must be left as is.
+	fake.rules["unmanaged"] = []client.MimirRuleGroup{{}}
+
+	c.reconcile(t.Context(), true)
+	require.NoError(t, c.lastErr)
+	rules, _ := fake.ListRules(t.Context(), "")
+	require.Len(t, rules, 2)
+	require.Len(t, rules["alloy-file/team-a.yaml"], 1)
+	require.Equal(t, "prod", rules["alloy-file/team-a.yaml"][0].Rules[0].Labels["cluster"])
+
+	// New files are picked up when the files are polled.
+	writeFile(t, filepath.Join(dir, "team-b.yaml"), testRules("team_b"))
+	c.reconcile(t.Context(), false)
+	require.NoError(t, c.lastErr)
+	rules, _ = fake.ListRules(t.Context(), "")
+	require.Len(t, rules, 3)
+	require.Contains(t, rules, "alloy-file/team-b.yaml")
+
+	// Removed files are removed from the ruler.
+	require.NoError(t, os.Remove(filepath.Join(dir, "team-a.yaml")))
+	c.reconcile(t.Context(), false)
+	require.NoError(t, c.lastErr)
+	rules, _ = fake.ListRules(t.Context(), "")
+	require.Len(t, rules, 2)
+	require.Contains(t, rules, "unmanaged")
+	require.Contains(t, rules, "alloy-file/team-b.yaml")
+
+	// Invalid rule files make the component unhealthy and leave the ruler as is.
+	writeFile(t, filepath.Join(dir, "team-b.yaml"), "groups: [")
+	c.reconcile(t.Context(), false)
+	require.Error(t, c.lastErr)
+	require.Equal(t, component.HealthTypeUnhealthy, c.CurrentHealth().Health)
+	rules, _ = fake.ListRules(t.Context(), "")
+	require.Len(t, rules, 2)
+
+	debugInfo := c.DebugInfo().(DebugInfo)
+	require.NotEmpty(t, debugInfo.Error)
+	require.Equal(t, []DebugMimirNamespace{{Name: "alloy-file/team-b.yaml", NumRuleGroups: 1}}, debugInfo.MimirRuleNamespaces)
+}
+
+func TestReconcile_NotLeader(t *testing.T) {
+	args := DefaultArguments
+	args.Address = "http://localhost:8080"
+	args.RuleFiles = []RuleFile{{Name: "team-a", Content: alloytypes.OptionalSecret{Value: testRules("team_a")}}}
+
+	c := newComponentForTesting(t, args, false)
+	c.reconcile(t.Context(), true)
+	require.NoError(t, c.lastErr)
+
+	rules, _ := c.mimirClient.ListRules(t.Context(), "")
+	require.Empty(t, rules)
+}
+
+func newComponentForTesting(t *testing.T, args Arguments, leader bool) *Component {
+	opts := component.Options{
+		ID:         "mimir.rules.file",
+		Logger:     util.TestLogger(t),
+		Registerer: prometheus.NewRegistry(),
+		GetServiceData: func(name string) (any, error) {
+			if name == cluster.ServiceName {
+				return &fakeCluster{leader: leader}, nil
+			}
+
+			panic(fmt.Sprintf("unexpected service name %s", name))
+		},
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+	c.mimirClient = newFakeMimirClient()
+	return c
+}
+
+func testRules(group string) string {
+	return fmt.Sprintf(`groups:
+- name: %s
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`, group)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+type fakeCluster struct {
+	leader bool
+}
+
+func (f fakeCluster) Lookup(shard.Key, int, shard.Op) ([]peer.Peer, error) {
+	return []peer.Peer{{Self: f.leader}}, nil
+}
+
+func (f fakeCluster) Peers() []peer.Peer {
+	return nil
+}
+
+func (f fakeCluster) Ready() bool {
+	return true
+}
+
+type fakeMimirClient struct {
+	rulesMut sync.RWMutex
+	rules    map[string][]client.MimirRuleGroup
+}
+
+var _ client.RulerInterface = &fakeMimirClient{}
+
+func newFakeMimirClient() *fakeMimirClient {
+	return &fakeMimirClient{
+		rules: make(map[string][]client.MimirRuleGroup),
+	}
+}
+
+func (m *fakeMimirClient) CreateRuleGroup(_ context.Context, namespace string, rule client.MimirRuleGroup) error {
+	m.rulesMut.Lock()
+	defer m.rulesMut.Unlock()
+	m.deleteLocked(namespace, rule.Name)
+	m.rules[namespace] = append(m.rules[namespace], rule)
+	return nil
+}
+
+func (m *fakeMimirClient) DeleteRuleGroup(_ context.Context, namespace, group string) error {
+	m.rulesMut.Lock()
+	defer m.rulesMut.Unlock()
+	m.deleteLocked(namespace, group)
+	return nil
+}
+
+func (m *fakeMimirClient) deleteLocked(namespace, group string) {
+	for i, g := range m.rules[namespace] {
+		if g.Name == group {
+			m.rules[namespace] = append(m.rules[namespace][:i], m.rules[namespace][i+1:]...)
+			if len(m.rules[namespace]) == 0 {
+				delete(m.rules, namespace)
+			}
+			return
+		}
+	}
+}
+
+func (m *fakeMimirClient) ListRules(_ context.Context, namespace string) (map[string][]client.MimirRuleGroup, error) {
+	m.rulesMut.RLock()
+	defer m.rulesMut.RUnlock()
+	output := make(map[string][]client.MimirRuleGroup)
+	for ns, v := range m.rules {
+		if namespace != "" && namespace != ns {
+			continue
+		}
+		output[ns] = v
+	}
+	return output, nil
+}
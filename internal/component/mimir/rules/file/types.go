@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+type Arguments struct {
+	Address              string                  `alloy:"address,attr"`
+	TenantID             string                  `alloy:"tenant_id,attr,optional"`
+	UseLegacyRoutes      bool                    `alloy:"use_legacy_routes,attr,optional"`
+	PrometheusHTTPPrefix string                  `alloy:"prometheus_http_prefix,attr,optional"`
+	HTTPClientConfig     config.HTTPClientConfig `alloy:",squash"`
+	SyncInterval         time.Duration           `alloy:"sync_interval,attr,optional"`
+	MimirNameSpacePrefix string                  `alloy:"mimir_namespace_prefix,attr,optional"`
+	ExternalLabels       map[string]string       `alloy:"external_labels,attr,optional"`
+
+	Path          string        `alloy:"path,attr,optional"`
+	PollFrequency time.Duration `alloy:"poll_frequency,attr,optional"`
+	RuleFiles     []RuleFile    `alloy:"rule_file,block,optional"`
+}
+
+// RuleFile holds the contents of a Prometheus rule file which is loaded
+// into its own Mimir namespace.
+type RuleFile struct {
+	Name    string                    `alloy:"name,attr"`
+	Content alloytypes.OptionalSecret `alloy:"content,attr"`
+}
+
+var DefaultArguments = Arguments{
+	SyncInterval:         5 * time.Minute,
+	PollFrequency:        time.Minute,
+	MimirNameSpacePrefix: "alloy-file",
+	HTTPClientConfig:     config.DefaultHTTPClientConfig,
+	PrometheusHTTPPrefix: "/prometheus",
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.SyncInterval <= 0 {
+		return fmt.Errorf("sync_interval must be greater than 0")
+	}
+	if args.PollFrequency <= 0 {
+		return fmt.Errorf("poll_frequency must be greater than 0")
+	}
+	if args.MimirNameSpacePrefix == "" {
+		return fmt.Errorf("mimir_namespace_prefix must not be empty")
+	}
+	if args.Path == "" && len(args.RuleFiles) == 0 {
+		return fmt.Errorf("at least one of path or rule_file must be provided")
+	}
+
+	names := make(map[string]struct{}, len(args.RuleFiles))
+	for _, rf := range args.RuleFiles {
+		if rf.Name == "" {
+			return fmt.Errorf("rule_file name must not be empty")
+		}
+		if _, ok := names[rf.Name]; ok {
+			return fmt.Errorf("rule_file name %q is used more than once", rf.Name)
+		}
+		names[rf.Name] = struct{}{}
+	}
+
+	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
+	return args.HTTPClientConfig.Validate()
+}
@@ -0,0 +1,207 @@
+// Package bridge passes telemetry in-process between the pipelines of the
+// OTel Engine and the pipelines of the Default Engine, when both run in the
+// same process through the alloyengine extension.
+//
+// Receiving ends of the bridge register their consumers under a name, and
+// sending ends forward data to the consumers registered under the name they
+// were configured with: an otelcol.exporter.alloyengine component sends to
+// the alloyengine receiver of the collector pipeline with the same name, and
+// the other way around. Each direction has its own names, so a collector
+// receiver and an otelcol.receiver.alloyengine component can use the same
+// name.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Direction is the engine which receives the telemetry crossing the bridge.
+type Direction int
+
+const (
+	// ToDefaultEngine sends telemetry from the OTel Engine to the Default
+	// Engine.
+	ToDefaultEngine Direction = iota
+	// ToOTelEngine sends telemetry from the Default Engine to the OTel Engine.
+	ToOTelEngine
+)
+
+// String returns the name of the engine receiving telemetry in direction d.
+func (d Direction) String() string {
+	switch d {
+	case ToDefaultEngine:
+		return "the Default Engine"
+	case ToOTelEngine:
+		return "the OTel Engine"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// registries holds the consumers registered for a direction.
+type registries struct {
+	traces  *registry[otelconsumer.Traces]
+	metrics *registry[otelconsumer.Metrics]
+	logs    *registry[otelconsumer.Logs]
+}
+
+func newRegistries(dir Direction) *registries {
+	return &registries{
+		traces:  newRegistry[otelconsumer.Traces](dir, "traces"),
+		metrics: newRegistry[otelconsumer.Metrics](dir, "metrics"),
+		logs:    newRegistry[otelconsumer.Logs](dir, "logs"),
+	}
+}
+
+var directions = map[Direction]*registries{
+	ToDefaultEngine: newRegistries(ToDefaultEngine),
+	ToOTelEngine:    newRegistries(ToOTelEngine),
+}
+
+// RegisterTraces registers c as the consumer of the traces sent to name in
+// direction dir. It returns an error if another consumer is already
+// registered for it. The returned function unregisters c.
+func RegisterTraces(dir Direction, name string, c otelconsumer.Traces) (func(), error) {
+	return directions[dir].traces.register(name, c)
+}
+
+// RegisterMetrics registers c as the consumer of the metrics sent to name in
+// direction dir. It returns an error if another consumer is already
+// registered for it. The returned function unregisters c.
+func RegisterMetrics(dir Direction, name string, c otelconsumer.Metrics) (func(), error) {
+	return directions[dir].metrics.register(name, c)
+}
+
+// RegisterLogs registers c as the consumer of the logs sent to name in
+// direction dir. It returns an error if another consumer is already
+// registered for it. The returned function unregisters c.
+func RegisterLogs(dir Direction, name string, c otelconsumer.Logs) (func(), error) {
+	return directions[dir].logs.register(name, c)
+}
+
+type registration[T any] struct {
+	consumer T
+}
+
+type registry[T any] struct {
+	dir    Direction
+	signal string
+
+	mut           sync.RWMutex
+	registrations map[string]*registration[T]
+}
+
+func newRegistry[T any](dir Direction, signal string) *registry[T] {
+	return &registry[T]{
+		dir:           dir,
+		signal:        signal,
+		registrations: make(map[string]*registration[T]),
+	}
+}
+
+func (r *registry[T]) register(name string, c T) (func(), error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if _, ok := r.registrations[name]; ok {
+		return nil, fmt.Errorf("a receiver of %s is already registered in %s with the name %q", r.signal, r.dir, name)
+	}
+	reg := &registration[T]{consumer: c}
+	r.registrations[name] = reg
+
+	return func() {
+		r.mut.Lock()
+		defer r.mut.Unlock()
+
+		// Only remove the registration if it hasn't been replaced since.
+		if r.registrations[name] == reg {
+			delete(r.registrations, name)
+		}
+	}, nil
+}
+
+func (r *registry[T]) get(name string) (T, error) {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	reg, ok := r.registrations[name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("no receiver of %s is registered in %s with the name %q", r.signal, r.dir, name)
+	}
+	return reg.consumer, nil
+}
+
+// Sender forwards the telemetry it consumes to the consumers registered
+// under its name in its direction at the time the telemetry is consumed.
+type Sender struct {
+	registries *registries
+	name       string
+}
+
+var (
+	_ otelconsumer.Traces  = (*Sender)(nil)
+	_ otelconsumer.Metrics = (*Sender)(nil)
+	_ otelconsumer.Logs    = (*Sender)(nil)
+)
+
+// NewSender creates a Sender to the consumers registered under name in
+// direction dir.
+func NewSender(dir Direction, name string) *Sender {
+	return &Sender{registries: directions[dir], name: name}
+}
+
+// Capabilities implements otelconsumer.baseConsumer.
+func (s *Sender) Capabilities() otelconsumer.Capabilities {
+	// The Sender copies the data it forwards to consumers which mutate it.
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeTraces implements otelconsumer.Traces.
+func (s *Sender) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	c, err := s.registries.traces.get(s.name)
+	if err != nil {
+		return err
+	}
+	if c.Capabilities().MutatesData {
+		newTraces := ptrace.NewTraces()
+		td.CopyTo(newTraces)
+		td = newTraces
+	}
+	return c.ConsumeTraces(ctx, td)
+}
+
+// ConsumeMetrics implements otelconsumer.Metrics.
+func (s *Sender) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	c, err := s.registries.metrics.get(s.name)
+	if err != nil {
+		return err
+	}
+	if c.Capabilities().MutatesData {
+		newMetrics := pmetric.NewMetrics()
+		md.CopyTo(newMetrics)
+		md = newMetrics
+	}
+	return c.ConsumeMetrics(ctx, md)
+}
+
+// ConsumeLogs implements otelconsumer.Logs.
+func (s *Sender) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	c, err := s.registries.logs.get(s.name)
+	if err != nil {
+		return err
+	}
+	if c.Capabilities().MutatesData {
+		newLogs := plog.NewLogs()
+		ld.CopyTo(newLogs)
+		ld = newLogs
+	}
+	return c.ConsumeLogs(ctx, ld)
+}
@@ -0,0 +1,111 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+)
+
+func TestSender(t *testing.T) {
+	var received []plog.Logs
+	unregister, err := RegisterLogs(ToDefaultEngine, "test-sender", &fakeconsumer.Consumer{
+		CapabilitiesFunc: func() otelconsumer.Capabilities { return otelconsumer.Capabilities{MutatesData: false} },
+		ConsumeLogsFunc: func(_ context.Context, ld plog.Logs) error {
+			received = append(received, ld)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+
+	sender := NewSender(ToDefaultEngine, "test-sender")
+	require.NoError(t, sender.ConsumeLogs(t.Context(), logs))
+	require.Len(t, received, 1)
+	require.Equal(t, 1, received[0].LogRecordCount())
+
+	// Only logs were registered under the name.
+	require.EqualError(t, sender.ConsumeTraces(t.Context(), ptrace.NewTraces()), `no receiver of traces is registered in the Default Engine with the name "test-sender"`)
+
+	unregister()
+	require.EqualError(t, sender.ConsumeLogs(t.Context(), logs), `no receiver of logs is registered in the Default Engine with the name "test-sender"`)
+}
+
+func TestSender_CopiesDataForMutatingConsumers(t *testing.T) {
+	unregister, err := RegisterTraces(ToDefaultEngine, "test-mutating", &fakeconsumer.Consumer{
+		ConsumeTracesFunc: func(_ context.Context, td ptrace.Traces) error {
+			td.ResourceSpans().At(0).Resource().Attributes().PutStr("mutated", "true")
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	defer unregister()
+
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty()
+
+	require.NoError(t, NewSender(ToDefaultEngine, "test-mutating").ConsumeTraces(t.Context(), traces))
+	_, mutated := traces.ResourceSpans().At(0).Resource().Attributes().Get("mutated")
+	require.False(t, mutated)
+}
+
+func TestRegister_DuplicateName(t *testing.T) {
+	unregister, err := RegisterMetrics(ToDefaultEngine, "test-duplicate", &fakeconsumer.Consumer{})
+	require.NoError(t, err)
+
+	_, err = RegisterMetrics(ToDefaultEngine, "test-duplicate", &fakeconsumer.Consumer{})
+	require.EqualError(t, err, `a receiver of metrics is already registered in the Default Engine with the name "test-duplicate"`)
+
+	// Other signals can use the same name.
+	unregisterLogs, err := RegisterLogs(ToDefaultEngine, "test-duplicate", &fakeconsumer.Consumer{})
+	require.NoError(t, err)
+	defer unregisterLogs()
+
+	unregister()
+	unregisterNew, err := RegisterMetrics(ToDefaultEngine, "test-duplicate", &fakeconsumer.Consumer{})
+	require.NoError(t, err)
+	defer unregisterNew()
+
+	// A stale unregister function doesn't remove the new registration.
+	unregister()
+	_, err = RegisterMetrics(ToDefaultEngine, "test-duplicate", &fakeconsumer.Consumer{})
+	require.Error(t, err)
+}
+
+func TestRegister_Directions(t *testing.T) {
+	var received []Direction
+	consumerFor := func(dir Direction) *fakeconsumer.Consumer {
+		return &fakeconsumer.Consumer{
+			ConsumeLogsFunc: func(context.Context, plog.Logs) error {
+				received = append(received, dir)
+				return nil
+			},
+		}
+	}
+
+	// Receivers of both engines can use the same name.
+	unregisterDefault, err := RegisterLogs(ToDefaultEngine, "test-directions", consumerFor(ToDefaultEngine))
+	require.NoError(t, err)
+	defer unregisterDefault()
+	unregisterOTel, err := RegisterLogs(ToOTelEngine, "test-directions", consumerFor(ToOTelEngine))
+	require.NoError(t, err)
+
+	// Senders only reach the receiver of their direction.
+	require.NoError(t, NewSender(ToOTelEngine, "test-directions").ConsumeLogs(t.Context(), plog.NewLogs()))
+	require.NoError(t, NewSender(ToDefaultEngine, "test-directions").ConsumeLogs(t.Context(), plog.NewLogs()))
+	require.Equal(t, []Direction{ToOTelEngine, ToDefaultEngine}, received)
+
+	_, err = RegisterLogs(ToOTelEngine, "test-directions", &fakeconsumer.Consumer{})
+	require.EqualError(t, err, `a receiver of logs is already registered in the OTel Engine with the name "test-directions"`)
+
+	unregisterOTel()
+	require.EqualError(t, NewSender(ToOTelEngine, "test-directions").ConsumeLogs(t.Context(), plog.NewLogs()), `no receiver of logs is registered in the OTel Engine with the name "test-directions"`)
+	require.NoError(t, NewSender(ToDefaultEngine, "test-directions").ConsumeLogs(t.Context(), plog.NewLogs()))
+}
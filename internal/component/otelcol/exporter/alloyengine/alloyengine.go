@@ -0,0 +1,77 @@
+// Package alloyengine provides an otelcol.exporter.alloyengine component.
+package alloyengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.exporter.alloyengine",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.exporter.alloyengine component.
+type Arguments struct {
+	// Name of the alloyengine receiver of the collector pipeline to send
+	// telemetry to.
+	Name string `alloy:"name,attr"`
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	return nil
+}
+
+// Component is the otelcol.exporter.alloyengine component.
+type Component struct {
+	export *lazyconsumer.Consumer
+}
+
+var _ component.Component = (*Component)(nil)
+
+// New creates a new otelcol.exporter.alloyengine component.
+func New(o component.Options, c Arguments) (*Component, error) {
+	// The exported consumer remains the same throughout the component's
+	// lifetime; updates only change where it forwards telemetry to.
+	res := &Component{
+		export: lazyconsumer.New(context.Background(), o.ID),
+	}
+	if err := res.Update(c); err != nil {
+		return nil, err
+	}
+	o.OnStateChange(otelcol.ConsumerExports{Input: res.export})
+
+	return res, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements Component.
+func (c *Component) Update(newConfig component.Arguments) error {
+	cfg := newConfig.(Arguments)
+	sender := bridge.NewSender(bridge.ToOTelEngine, cfg.Name)
+	c.export.SetConsumers(sender, sender, sender)
+	return nil
+}
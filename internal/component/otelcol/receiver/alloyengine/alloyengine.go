@@ -0,0 +1,193 @@
+// Package alloyengine provides an otelcol.receiver.alloyengine component.
+package alloyengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/interceptconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/livedebuggingpublisher"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.receiver.alloyengine",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.alloyengine component.
+type Arguments struct {
+	// Name that the alloyengine exporters of the collector pipeline send
+	// telemetry to.
+	Name string `alloy:"name,attr"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	return nil
+}
+
+// Component is the otelcol.receiver.alloyengine component.
+type Component struct {
+	opts component.Options
+
+	// consumer is registered in the bridge while the component runs, and
+	// forwards telemetry to the outputs of the latest arguments.
+	consumer           *lazyconsumer.Consumer
+	debugDataPublisher livedebugging.DebugDataPublisher
+
+	mut              sync.Mutex
+	args             Arguments
+	running          bool
+	unregisterBridge func()
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new otelcol.receiver.alloyengine component.
+func New(o component.Options, c Arguments) (*Component, error) {
+	debugDataPublisher, err := o.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Component{
+		opts:               o,
+		consumer:           lazyconsumer.New(context.Background(), o.ID),
+		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+	}
+	if err := res.Update(c); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	c.mut.Lock()
+	if err := c.register(c.args.Name); err != nil {
+		c.mut.Unlock()
+		return err
+	}
+	c.running = true
+	c.mut.Unlock()
+
+	defer func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		c.running = false
+		c.unregister()
+	}()
+
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements Component.
+func (c *Component) Update(newConfig component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	args := newConfig.(Arguments)
+
+	var (
+		nextTraces  = args.Output.Traces
+		nextMetrics = args.Output.Metrics
+		nextLogs    = args.Output.Logs
+
+		tracesFanout  = fanoutconsumer.Traces(nextTraces)
+		metricsFanout = fanoutconsumer.Metrics(nextMetrics)
+		logsFanout    = fanoutconsumer.Logs(nextLogs)
+	)
+	c.consumer.SetConsumers(
+		interceptconsumer.Traces(tracesFanout, func(ctx context.Context, td ptrace.Traces) error {
+			livedebuggingpublisher.PublishTracesIfActive(c.debugDataPublisher, c.opts.ID, td, otelcol.GetComponentMetadata(nextTraces))
+			return tracesFanout.ConsumeTraces(ctx, td)
+		}),
+		interceptconsumer.Metrics(metricsFanout, func(ctx context.Context, md pmetric.Metrics) error {
+			livedebuggingpublisher.PublishMetricsIfActive(c.debugDataPublisher, c.opts.ID, md, otelcol.GetComponentMetadata(nextMetrics))
+			return metricsFanout.ConsumeMetrics(ctx, md)
+		}),
+		interceptconsumer.Logs(logsFanout, func(ctx context.Context, ld plog.Logs) error {
+			livedebuggingpublisher.PublishLogsIfActive(c.debugDataPublisher, c.opts.ID, ld, otelcol.GetComponentMetadata(nextLogs))
+			return logsFanout.ConsumeLogs(ctx, ld)
+		}),
+	)
+
+	nameChanged := args.Name != c.args.Name
+	c.args = args
+
+	// The component is only registered while it runs, so that a component
+	// which is never started doesn't hold on to its name.
+	if c.running && nameChanged {
+		c.unregister()
+		return c.register(args.Name)
+	}
+	return nil
+}
+
+// register registers the consumer of the component under name for all
+// signals. c.mut must be held when calling register.
+func (c *Component) register(name string) error {
+	var unregisterFuncs []func()
+	unregisterAll := func() {
+		for _, unregister := range unregisterFuncs {
+			unregister()
+		}
+	}
+
+	for _, register := range []func() (func(), error){
+		func() (func(), error) { return bridge.RegisterTraces(bridge.ToDefaultEngine, name, c.consumer) },
+		func() (func(), error) { return bridge.RegisterMetrics(bridge.ToDefaultEngine, name, c.consumer) },
+		func() (func(), error) { return bridge.RegisterLogs(bridge.ToDefaultEngine, name, c.consumer) },
+	} {
+		unregister, err := register()
+		if err != nil {
+			unregisterAll()
+			return err
+		}
+		unregisterFuncs = append(unregisterFuncs, unregister)
+	}
+
+	c.unregisterBridge = unregisterAll
+	return nil
+}
+
+// unregister removes the consumer of the component from the bridge, if it's
+// registered. c.mut must be held when calling unregister.
+func (c *Component) unregister() {
+	if c.unregisterBridge != nil {
+		c.unregisterBridge()
+		c.unregisterBridge = nil
+	}
+}
+
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
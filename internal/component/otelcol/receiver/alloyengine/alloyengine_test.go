@@ -0,0 +1,80 @@
+package alloyengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/bridge"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func Test(t *testing.T) {
+	ctx, cancel := context.WithCancel(componenttest.TestContext(t))
+	defer cancel()
+
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.receiver.alloyengine")
+	require.NoError(t, err)
+
+	cfg := `
+		name = "test-receiver"
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	logCh := make(chan plog.Logs, 1)
+	args.Output = &otelcol.ConsumerArguments{
+		Logs: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeLogsFunc: func(ctx context.Context, ld plog.Logs) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case logCh <- ld:
+					return nil
+				}
+			},
+		}},
+	}
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- ctrl.Run(ctx, args)
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+
+	sender := bridge.NewSender(bridge.ToDefaultEngine, "test-receiver")
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+
+	// The component registers itself once it runs.
+	require.Eventually(t, func() bool {
+		return sender.ConsumeLogs(ctx, logs) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	received := <-logCh
+	require.Equal(t, "hello", received.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	// The name is released when the component stops.
+	cancel()
+	require.NoError(t, <-runErr)
+	require.Error(t, sender.ConsumeLogs(t.Context(), logs))
+}
+
+func TestArguments_Validate(t *testing.T) {
+	var args Arguments
+	err := syntax.Unmarshal([]byte(`
+		name = ""
+		output {}
+	`), &args)
+	require.EqualError(t, err, "name must not be empty")
+}
@@ -0,0 +1,218 @@
+package pyroscope
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// debugTopFrames is the number of frames listed in the summary of a profile.
+const debugTopFrames = 5
+
+// DebugDataPublisher publishes profiles to live debugging. count is the
+// number of profiles and dataFunc returns their description; it is only
+// called if someone is consuming the data.
+type DebugDataPublisher func(count uint64, dataFunc func() string)
+
+// Publish publishes the profiles if p is set.
+func (p DebugDataPublisher) Publish(count uint64, dataFunc func() string) {
+	if p != nil {
+		p(count, dataFunc)
+	}
+}
+
+// SamplesDebugString returns a human-readable summary of the samples of a
+// series for live debugging. name is the name of the profile, as set in the
+// __name__ label.
+func SamplesDebugString(name string, samples []*RawSample) string {
+	var sb strings.Builder
+	for i, s := range samples {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		writePprofSummary(&sb, name, s.RawProfile)
+	}
+	return sb.String()
+}
+
+// IncomingProfileDebugString returns a human-readable summary of a profile
+// received on the ingest API for live debugging. Only pprof and folded
+// profiles are decoded, other formats are only described by their size.
+func IncomingProfileDebugString(p *IncomingProfile) string {
+	var sb strings.Builder
+
+	format := "folded"
+	if p.URL != nil && p.URL.Query().Get("format") != "" {
+		format = p.URL.Query().Get("format")
+	}
+	fmt.Fprintf(&sb, "format: %s", format)
+	if len(p.ContentType) > 0 {
+		fmt.Fprintf(&sb, ", content type: %s", strings.Join(p.ContentType, ", "))
+	}
+	sb.WriteByte('\n')
+
+	switch format {
+	case "pprof":
+		data, err := pprofFromIngestBody(p.RawBody, p.ContentType)
+		if err != nil {
+			fmt.Fprintf(&sb, "size: %d bytes\ninvalid pprof profile: %s", len(p.RawBody), err)
+			return sb.String()
+		}
+		writePprofSummary(&sb, p.Labels.Get(LabelName), data)
+	case "folded":
+		writeFoldedSummary(&sb, p.RawBody)
+	default:
+		fmt.Fprintf(&sb, "size: %d bytes", len(p.RawBody))
+	}
+	return sb.String()
+}
+
+// pprofFromIngestBody returns the pprof profile of an ingest request, which
+// is either sent as is or in the "profile" field of a multipart form.
+func pprofFromIngestBody(body []byte, contentType []string) ([]byte, error) {
+	if len(contentType) == 0 {
+		return body, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType[0])
+	if err != nil || mediaType != "multipart/form-data" {
+		return body, nil
+	}
+
+	r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return nil, fmt.Errorf("missing profile field in multipart form")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "profile" {
+			return io.ReadAll(part)
+		}
+	}
+}
+
+func writePprofSummary(sb *strings.Builder, name string, data []byte) {
+	fmt.Fprintf(sb, "size: %d bytes\n", len(data))
+
+	p, err := profile.ParseData(data)
+	if err != nil {
+		fmt.Fprintf(sb, "invalid pprof profile: %s", err)
+		return
+	}
+
+	if len(p.SampleType) == 0 {
+		fmt.Fprintf(sb, "samples: %d", len(p.Sample))
+		return
+	}
+
+	idx := len(p.SampleType) - 1
+	for i, st := range p.SampleType {
+		if st.Type == p.DefaultSampleType {
+			idx = i
+		}
+	}
+
+	st := p.SampleType[idx]
+	profileType := fmt.Sprintf("%s:%s:%s", name, st.Type, st.Unit)
+	if p.PeriodType != nil {
+		profileType += fmt.Sprintf(":%s:%s", p.PeriodType.Type, p.PeriodType.Unit)
+	}
+	fmt.Fprintf(sb, "profile type: %s\n", profileType)
+
+	values := make(map[string]int64)
+	var total int64
+	for _, s := range p.Sample {
+		if idx >= len(s.Value) {
+			continue
+		}
+		v := s.Value[idx]
+		total += v
+		values[leafFrame(s)] += v
+	}
+	fmt.Fprintf(sb, "samples: %d, total %s: %d", len(p.Sample), st.Unit, total)
+	writeTopFrames(sb, values, total)
+}
+
+// leafFrame returns the name of the function a sample was taken in.
+func leafFrame(s *profile.Sample) string {
+	if len(s.Location) == 0 {
+		return "<unknown>"
+	}
+	loc := s.Location[0]
+	// The first line is the innermost inlined function.
+	if len(loc.Line) > 0 && loc.Line[0].Function != nil {
+		return loc.Line[0].Function.Name
+	}
+	return fmt.Sprintf("0x%x", loc.Address)
+}
+
+// writeFoldedSummary describes a profile in the folded format, where each
+// line holds a stack with frames separated by ";" and a value.
+func writeFoldedSummary(sb *strings.Builder, data []byte) {
+	fmt.Fprintf(sb, "size: %d bytes\n", len(data))
+
+	var (
+		values  = make(map[string]int64)
+		total   int64
+		samples int
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		sep := strings.LastIndexByte(line, ' ')
+		if sep < 0 {
+			continue
+		}
+		v, err := strconv.ParseInt(line[sep+1:], 10, 64)
+		if err != nil {
+			continue
+		}
+		stack := line[:sep]
+		values[stack[strings.LastIndexByte(stack, ';')+1:]] += v
+		total += v
+		samples++
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(sb, "invalid folded profile: %s", err)
+		return
+	}
+
+	fmt.Fprintf(sb, "samples: %d, total: %d", samples, total)
+	writeTopFrames(sb, values, total)
+}
+
+func writeTopFrames(sb *strings.Builder, values map[string]int64, total int64) {
+	if len(values) == 0 || total == 0 {
+		return
+	}
+
+	frames := make([]string, 0, len(values))
+	for frame := range values {
+		frames = append(frames, frame)
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		if values[frames[i]] != values[frames[j]] {
+			return values[frames[i]] > values[frames[j]]
+		}
+		return frames[i] < frames[j]
+	})
+	if len(frames) > debugTopFrames {
+		frames = frames[:debugTopFrames]
+	}
+
+	sb.WriteString("\ntop frames:")
+	for _, frame := range frames {
+		fmt.Fprintf(sb, "\n  %6.2f%% %s", float64(values[frame])*100/float64(total), frame)
+	}
+}
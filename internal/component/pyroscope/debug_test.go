@@ -0,0 +1,114 @@
+package pyroscope
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func testPprofProfile(t *testing.T) []byte {
+	t.Helper()
+
+	var (
+		mainFn   = &profile.Function{ID: 1, Name: "main.main"}
+		workFn   = &profile.Function{ID: 2, Name: "main.work"}
+		mallocFn = &profile.Function{ID: 3, Name: "runtime.mallocgc"}
+		mainLoc  = &profile.Location{ID: 1, Line: []profile.Line{{Function: mainFn}}}
+		workLoc  = &profile.Location{ID: 2, Line: []profile.Line{{Function: workFn}}}
+		// mallocgc is inlined into work.
+		mallocLoc = &profile.Location{ID: 3, Line: []profile.Line{{Function: mallocFn}, {Function: workFn}}}
+	)
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     10000000,
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{workLoc, mainLoc}, Value: []int64{3, 300}},
+			{Location: []*profile.Location{mallocLoc, mainLoc}, Value: []int64{1, 100}},
+			{Location: []*profile.Location{mainLoc}, Value: []int64{6, 600}},
+		},
+		Location: []*profile.Location{mainLoc, workLoc, mallocLoc},
+		Function: []*profile.Function{mainFn, workFn, mallocFn},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestSamplesDebugString(t *testing.T) {
+	data := testPprofProfile(t)
+
+	actual := SamplesDebugString("process_cpu", []*RawSample{{RawProfile: data}, {RawProfile: []byte("invalid")}})
+	require.Equal(t, `size: `+strconv.Itoa(len(data))+` bytes
+profile type: process_cpu:cpu:nanoseconds:cpu:nanoseconds
+samples: 3, total nanoseconds: 1000
+top frames:
+   60.00% main.main
+   30.00% main.work
+   10.00% runtime.mallocgc
+size: 7 bytes
+invalid pprof profile: parsing profile: unrecognized profile format`, actual)
+}
+
+func TestIncomingProfileDebugString(t *testing.T) {
+	t.Run("pprof", func(t *testing.T) {
+		data := testPprofProfile(t)
+
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		fw, err := w.CreateFormFile("profile", "profile.pprof")
+		require.NoError(t, err)
+		_, err = fw.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		actual := IncomingProfileDebugString(&IncomingProfile{
+			RawBody:     body.Bytes(),
+			ContentType: []string{w.FormDataContentType()},
+			URL:         &url.URL{Path: "/ingest", RawQuery: "format=pprof&name=app"},
+			Labels:      labels.FromStrings(LabelName, "process_cpu"),
+		})
+		require.Equal(t, `format: pprof, content type: `+w.FormDataContentType()+`
+size: `+strconv.Itoa(len(data))+` bytes
+profile type: process_cpu:cpu:nanoseconds:cpu:nanoseconds
+samples: 3, total nanoseconds: 1000
+top frames:
+   60.00% main.main
+   30.00% main.work
+   10.00% runtime.mallocgc`, actual)
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		body := []byte("main;work 3\nmain;work;malloc 1\nmain 6\n")
+		actual := IncomingProfileDebugString(&IncomingProfile{
+			RawBody: body,
+			URL:     &url.URL{Path: "/ingest", RawQuery: "name=app"},
+		})
+		require.Equal(t, `format: folded
+size: 38 bytes
+samples: 3, total: 10
+top frames:
+   60.00% main
+   30.00% work
+   10.00% malloc`, actual)
+	})
+
+	t.Run("other formats", func(t *testing.T) {
+		actual := IncomingProfileDebugString(&IncomingProfile{
+			RawBody:     []byte("jfr"),
+			ContentType: []string{"application/octet-stream"},
+			URL:         &url.URL{Path: "/ingest", RawQuery: "format=jfr"},
+		})
+		require.Equal(t, "format: jfr, content type: application/octet-stream\nsize: 3 bytes", actual)
+	})
+}
@@ -3,12 +3,14 @@ package enrich
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	debuginfogrpc "buf.build/gen/go/parca-dev/parca/grpc/go/parca/debuginfo/v1alpha1/debuginfov1alpha1grpc"
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/component/pyroscope"
+	"github.com/grafana/alloy/internal/component/pyroscope/util/glue"
 	"github.com/grafana/alloy/internal/component/pyroscope/write/debuginfo"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/prometheus/prometheus/model/labels"
@@ -59,14 +61,27 @@ type Component struct {
 	cacheMutex   sync.RWMutex
 
 	fanout *pyroscope.Fanout
+
+	debugDataPublisher pyroscope.DebugDataPublisher
 }
 
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
 func New(opts component.Options, args Arguments) (*Component, error) {
+	debugDataPublisher, err := glue.NewDebugDataPublisher(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Component{
-		opts:         opts,
-		args:         args,
-		targetsCache: make(map[string]labels.Labels),
-		fanout:       pyroscope.NewFanout(args.ForwardTo, opts.ID, opts.Registerer),
+		opts:               opts,
+		args:               args,
+		targetsCache:       make(map[string]labels.Labels),
+		fanout:             pyroscope.NewFanout(args.ForwardTo, opts.ID, opts.Registerer),
+		debugDataPublisher: debugDataPublisher,
 	}
 
 	// Initialize the cache with provided targets
@@ -91,6 +106,9 @@ func (e *enrichAppendable) Appender() pyroscope.Appender {
 
 func (e *enrichAppendable) Append(ctx context.Context, lbls labels.Labels, samples []*pyroscope.RawSample) error {
 	enrichedLabels := e.component.enrichLabels(lbls)
+	e.component.publishDebugData(lbls, enrichedLabels, uint64(len(samples)), func() string {
+		return pyroscope.SamplesDebugString(enrichedLabels.Get(pyroscope.LabelName), samples)
+	})
 	return e.component.fanout.Appender().Append(ctx, enrichedLabels, samples)
 }
 
@@ -104,10 +122,20 @@ func (e *enrichAppendable) AppendIngest(ctx context.Context, profile *pyroscope.
 		URL:         profile.URL,
 		Labels:      enrichedLabels,
 	}
+	e.component.publishDebugData(profile.Labels, enrichedLabels, 1, func() string {
+		return pyroscope.IncomingProfileDebugString(enrichedProfile)
+	})
 
 	return e.component.fanout.Appender().AppendIngest(ctx, enrichedProfile)
 }
 
+// publishDebugData publishes the enriched profiles to live debugging.
+func (c *Component) publishDebugData(lbls, enrichedLabels labels.Labels, count uint64, dataFunc func() string) {
+	c.debugDataPublisher.Publish(count, func() string {
+		return fmt.Sprintf("labels: %s => %s\n%s", lbls.String(), enrichedLabels.String(), dataFunc())
+	})
+}
+
 // Run implements component.Component.
 func (c *Component) Run(ctx context.Context) error {
 	<-ctx.Done()
@@ -202,6 +230,9 @@ func (c *Component) Exports() component.Exports {
 	return &c.exports
 }
 
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
+
 func (e *enrichAppendable) Upload(j debuginfo.UploadJob) {
 	e.component.fanout.Upload(j)
 }
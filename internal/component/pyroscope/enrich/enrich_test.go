@@ -2,6 +2,7 @@ package enrich
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/go-kit/log"
@@ -12,14 +13,16 @@ import (
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/component/pyroscope"
+	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
 func TestEnricher(t *testing.T) {
 	// Create basic component options
 	opts := component.Options{
-		Logger:        log.NewNopLogger(),
-		OnStateChange: func(e component.Exports) {},
-		Registerer:    prometheus.NewRegistry(),
+		Logger:         log.NewNopLogger(),
+		OnStateChange:  func(e component.Exports) {},
+		Registerer:     prometheus.NewRegistry(),
+		GetServiceData: getServiceData,
 	}
 
 	tests := []struct {
@@ -165,9 +168,10 @@ func TestUpdate(t *testing.T) {
 	})
 
 	comp, err := New(component.Options{
-		Logger:        log.NewNopLogger(),
-		OnStateChange: func(e component.Exports) {},
-		Registerer:    prometheus.NewRegistry(),
+		Logger:         log.NewNopLogger(),
+		OnStateChange:  func(e component.Exports) {},
+		Registerer:     prometheus.NewRegistry(),
+		GetServiceData: getServiceData,
 	}, Arguments{
 		ForwardTo: []pyroscope.Appendable{testAppendable},
 	})
@@ -197,12 +201,22 @@ func TestName(t *testing.T) {
 	})
 
 	comp, err := New(component.Options{
-		Logger:        log.NewNopLogger(),
-		OnStateChange: func(e component.Exports) {},
-		Registerer:    prometheus.NewRegistry(),
+		Logger:         log.NewNopLogger(),
+		OnStateChange:  func(e component.Exports) {},
+		Registerer:     prometheus.NewRegistry(),
+		GetServiceData: getServiceData,
 	}, Arguments{
 		ForwardTo: []pyroscope.Appendable{testAppendable},
 	})
 	require.NoError(t, err)
 	require.Equal(t, "pyroscope.enrich", comp.Name())
 }
+
+func getServiceData(name string) (any, error) {
+	switch name {
+	case livedebugging.ServiceName:
+		return livedebugging.NewLiveDebugging(), nil
+	default:
+		return nil, fmt.Errorf("service not found %s", name)
+	}
+}
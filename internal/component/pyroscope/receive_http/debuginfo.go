@@ -17,7 +17,9 @@ var errNoDebugInfoClient = status.Error(codes.Unavailable, "no debug info client
 
 //nolint:unused
 func (c *Component) mountDebugInfo(router *mux.Router) {
-	c.grpcServer = NewGrpcServer(c.server.Config())
+	if c.grpcServer == nil {
+		c.grpcServer = NewGrpcServer(c.server.Config())
+	}
 	debuginfogrpc.RegisterDebuginfoServiceServer(c.grpcServer, c)
 	const (
 		DebuginfoService_Upload_FullMethodName               = "/parca.debuginfo.v1alpha1.DebuginfoService/Upload"
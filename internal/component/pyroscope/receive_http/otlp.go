@@ -0,0 +1,181 @@
+package receive_http
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/alloy/internal/component/pyroscope"
+	pyroutil "github.com/grafana/alloy/internal/component/pyroscope/util"
+	"github.com/grafana/alloy/internal/component/pyroscope/write"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	// otlpProfilesHTTPPath is the path of the OTLP/HTTP profiles endpoint.
+	otlpProfilesHTTPPath = "/v1development/profiles"
+	// otlpProfilesGRPCMethod is the full name of the OTLP/gRPC profiles
+	// export method.
+	otlpProfilesGRPCMethod = "/opentelemetry.proto.collector.profiles.v1development.ProfilesService/Export"
+
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// errOTLPInvalidProfiles is returned when an export request can't be
+// converted to pprof.
+var errOTLPInvalidProfiles = errors.New("invalid OTLP profiles")
+
+// otlpProfilesServer implements the OTLP/gRPC profiles service.
+type otlpProfilesServer struct {
+	colprofilespb.UnimplementedProfilesServiceServer
+
+	c *Component
+}
+
+// Export implements colprofilespb.ProfilesServiceServer.
+func (s *otlpProfilesServer) Export(ctx context.Context, req *colprofilespb.ExportProfilesServiceRequest) (*colprofilespb.ExportProfilesServiceResponse, error) {
+	err := s.c.exportOTLP(ctx, req)
+	switch {
+	case errors.Is(err, errOTLPInvalidProfiles):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &colprofilespb.ExportProfilesServiceResponse{}, nil
+}
+
+// mountOTLP mounts the OTLP/gRPC and OTLP/HTTP profiles endpoints. gRPC
+// requests are served over HTTP/2 on the same port as the other endpoints.
+func (c *Component) mountOTLP(router *mux.Router) {
+	if c.grpcServer == nil {
+		c.grpcServer = NewGrpcServer(c.server.Config())
+	}
+	colprofilespb.RegisterProfilesServiceServer(c.grpcServer, &otlpProfilesServer{c: c})
+	router.PathPrefix(otlpProfilesGRPCMethod).Handler(c.grpcServer)
+
+	router.HandleFunc(otlpProfilesHTTPPath, c.handleOTLPHTTP).Methods(http.MethodPost)
+}
+
+func (c *Component) handleOTLPHTTP(w http.ResponseWriter, r *http.Request) {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get(pyroscope.HeaderContentType))
+	if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "failed to decompress request body", http.StatusBadRequest)
+			return
+		}
+		defer gr.Close()
+		body = gr
+	}
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		req  colprofilespb.ExportProfilesServiceRequest
+		resp colprofilespb.ExportProfilesServiceResponse
+	)
+	marshal := proto.Marshal
+	if contentType == contentTypeJSON {
+		marshal = protojson.Marshal
+		err = protojson.Unmarshal(buf, &req)
+	} else {
+		err = proto.Unmarshal(buf, &req)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.exportOTLP(r.Context(), &req); err != nil {
+		var writeErr *write.PyroscopeWriteError
+		switch {
+		case errors.Is(err, errOTLPInvalidProfiles):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.As(err, &writeErr):
+			http.Error(w, http.StatusText(writeErr.StatusCode), writeErr.StatusCode)
+		default:
+			http.Error(w, "Failed to process request", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	out, err := marshal(&resp)
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(pyroscope.HeaderContentType, contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out)
+}
+
+// exportOTLP converts the profiles of an OTLP export request to pprof and
+// forwards them to the appendables.
+func (c *Component) exportOTLP(ctx context.Context, req *colprofilespb.ExportProfilesServiceRequest) error {
+	appendables := c.getAppendables()
+
+	ctx, sp := c.tracer.Start(ctx, otlpProfilesGRPCMethod)
+	defer sp.End()
+	l := pyroutil.TraceLog(c.logger, sp)
+
+	series, err := convertOTLPProfiles(req)
+	if err != nil {
+		level.Warn(l).Log("msg", "Failed to convert OTLP profiles", "err", err)
+		return fmt.Errorf("%w: %w", errOTLPInvalidProfiles, err)
+	}
+
+	for _, s := range series {
+		c.debugDataPublisher.Publish(uint64(len(s.samples)), func() string {
+			return fmt.Sprintf("labels: %s\n%s", s.labels.String(), pyroscope.SamplesDebugString(s.labels.Get(pyroscope.LabelName), s.samples))
+		})
+	}
+
+	var wg sync.WaitGroup
+	var errs error
+	var errorMut sync.Mutex
+
+	for i := range appendables {
+		appendable := appendables[i].Appender()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, s := range series {
+				if err := appendable.Append(ctx, s.labels, s.samples); err != nil {
+					pyroutil.ErrorsJoinConcurrent(
+						&errs,
+						fmt.Errorf("unable to append series %s to appendable %d: %w", s.labels.String(), i, err),
+						&errorMut,
+					)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if errs != nil {
+		level.Warn(l).Log("msg", "Failed to forward OTLP profiles", "err", errs)
+		return errs
+	}
+	return nil
+}
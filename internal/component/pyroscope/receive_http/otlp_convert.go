@@ -0,0 +1,354 @@
+package receive_http
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/google/pprof/profile"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/util/strutil"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	profilespb "go.opentelemetry.io/proto/otlp/profiles/v1development"
+
+	"github.com/grafana/alloy/internal/component/pyroscope"
+)
+
+const (
+	otlpServiceNameAttribute = "service.name"
+	otlpUnknownServiceName   = "unknown_service"
+)
+
+// otlpBuildIDAttributes are the mapping attributes which hold the build ID of
+// an executable, in order of preference.
+var otlpBuildIDAttributes = []string{
+	"process.executable.build_id.gnu",
+	"process.executable.build_id.go",
+	"process.executable.build_id.htlhash",
+}
+
+// otlpSeries is an OTLP profile converted to pprof, together with the labels
+// of the series it belongs to.
+type otlpSeries struct {
+	labels  labels.Labels
+	samples []*pyroscope.RawSample
+}
+
+// convertOTLPProfiles converts the profiles of an OTLP export request to
+// gzipped pprof profiles.
+func convertOTLPProfiles(req *colprofilespb.ExportProfilesServiceRequest) ([]otlpSeries, error) {
+	var series []otlpSeries
+
+	for _, rp := range req.GetResourceProfiles() {
+		lb := labels.NewBuilder(labels.EmptyLabels())
+		lb.Set(pyroscope.LabelServiceName, otlpUnknownServiceName)
+		for _, attr := range rp.GetResource().GetAttributes() {
+			value := otlpAnyValueString(attr.GetValue())
+			if value == "" {
+				continue
+			}
+			if attr.GetKey() == otlpServiceNameAttribute {
+				lb.Set(pyroscope.LabelServiceName, value)
+				continue
+			}
+			lb.Set(strutil.SanitizeLabelName(attr.GetKey()), value)
+		}
+		// OTLP profiles cover a single collection window, so the server must
+		// not compute deltas.
+		lb.Set(pyroscope.LabelNameDelta, "false")
+
+		for _, sp := range rp.GetScopeProfiles() {
+			for _, p := range sp.GetProfiles() {
+				c := newOTLPConverter(req.GetDictionary())
+				converted, err := c.convert(p)
+				if err != nil {
+					return nil, err
+				}
+
+				var buf bytes.Buffer
+				if err := converted.Write(&buf); err != nil {
+					return nil, fmt.Errorf("failed to encode profile: %w", err)
+				}
+
+				lb.Set(pyroscope.LabelName, otlpProfileName(converted.SampleType[0].Type))
+				series = append(series, otlpSeries{
+					labels:  lb.Labels(),
+					samples: []*pyroscope.RawSample{{RawProfile: buf.Bytes()}},
+				})
+			}
+		}
+	}
+
+	return series, nil
+}
+
+// otlpProfileName returns the name of the profile, which Pyroscope uses to
+// identify its type, based on the type of its samples.
+func otlpProfileName(sampleType string) string {
+	switch sampleType {
+	case "", "cpu", "samples":
+		return "process_cpu"
+	case "alloc_objects", "alloc_space", "inuse_objects", "inuse_space":
+		return "memory"
+	default:
+		return sampleType
+	}
+}
+
+// otlpConverter converts a single OTLP profile to pprof. The tables of the
+// dictionary are shared by all the profiles of a request, so the converter
+// only copies the entries which are referenced by the profile.
+type otlpConverter struct {
+	dict *profilespb.ProfilesDictionary
+	out  *profile.Profile
+
+	mappings  map[int32]*profile.Mapping
+	functions map[int32]*profile.Function
+	locations map[int32]*profile.Location
+}
+
+func newOTLPConverter(dict *profilespb.ProfilesDictionary) *otlpConverter {
+	return &otlpConverter{
+		dict:      dict,
+		out:       &profile.Profile{},
+		mappings:  make(map[int32]*profile.Mapping),
+		functions: make(map[int32]*profile.Function),
+		locations: make(map[int32]*profile.Location),
+	}
+}
+
+func (c *otlpConverter) convert(p *profilespb.Profile) (*profile.Profile, error) {
+	sampleType, err := c.valueType(p.GetSampleType())
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample type: %w", err)
+	}
+	c.out.SampleType = []*profile.ValueType{sampleType}
+	if p.GetPeriodType() != nil {
+		if c.out.PeriodType, err = c.valueType(p.GetPeriodType()); err != nil {
+			return nil, fmt.Errorf("invalid period type: %w", err)
+		}
+	}
+	c.out.Period = p.GetPeriod()
+	c.out.TimeNanos = int64(p.GetTimeUnixNano())
+	c.out.DurationNanos = int64(p.GetDurationNano())
+
+	for _, s := range p.GetSamples() {
+		sample, err := c.sample(s)
+		if err != nil {
+			return nil, err
+		}
+		c.out.Sample = append(c.out.Sample, sample)
+	}
+
+	if err := c.out.CheckValid(); err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	return c.out, nil
+}
+
+func (c *otlpConverter) sample(s *profilespb.Sample) (*profile.Sample, error) {
+	out := &profile.Sample{}
+
+	switch values := s.GetValues(); {
+	case len(values) == 1:
+		out.Value = []int64{values[0]}
+	case len(values) == 0:
+		// Samples without values are counted once per timestamp.
+		out.Value = []int64{int64(len(s.GetTimestampsUnixNano()))}
+	default:
+		return nil, fmt.Errorf("sample has %d values, expected 1", len(values))
+	}
+
+	stacks := c.dict.GetStackTable()
+	if s.GetStackIndex() < 0 || int(s.GetStackIndex()) >= len(stacks) {
+		return nil, fmt.Errorf("stack index %d out of range", s.GetStackIndex())
+	}
+	for _, idx := range stacks[s.GetStackIndex()].GetLocationIndices() {
+		loc, err := c.location(idx)
+		if err != nil {
+			return nil, err
+		}
+		out.Location = append(out.Location, loc)
+	}
+
+	attrs := c.dict.GetAttributeTable()
+	for _, idx := range s.GetAttributeIndices() {
+		if idx < 0 || int(idx) >= len(attrs) {
+			return nil, fmt.Errorf("attribute index %d out of range", idx)
+		}
+		key, err := c.str(attrs[idx].GetKeyStrindex())
+		if err != nil {
+			return nil, err
+		}
+		value := otlpAnyValueString(attrs[idx].GetValue())
+		if key == "" || value == "" {
+			continue
+		}
+		if out.Label == nil {
+			out.Label = make(map[string][]string)
+		}
+		out.Label[key] = append(out.Label[key], value)
+	}
+
+	return out, nil
+}
+
+func (c *otlpConverter) location(idx int32) (*profile.Location, error) {
+	if loc, ok := c.locations[idx]; ok {
+		return loc, nil
+	}
+	locs := c.dict.GetLocationTable()
+	if idx < 0 || int(idx) >= len(locs) {
+		return nil, fmt.Errorf("location index %d out of range", idx)
+	}
+	in := locs[idx]
+
+	loc := &profile.Location{
+		ID:      uint64(len(c.out.Location) + 1),
+		Address: in.GetAddress(),
+	}
+	// Index 0 of the mapping table is the zero value, which is used for
+	// locations without a mapping.
+	if in.GetMappingIndex() > 0 {
+		m, err := c.mapping(in.GetMappingIndex())
+		if err != nil {
+			return nil, err
+		}
+		loc.Mapping = m
+	}
+	for _, line := range in.GetLines() {
+		fn, err := c.function(line.GetFunctionIndex())
+		if err != nil {
+			return nil, err
+		}
+		loc.Line = append(loc.Line, profile.Line{
+			Function: fn,
+			Line:     line.GetLine(),
+			Column:   line.GetColumn(),
+		})
+	}
+
+	c.locations[idx] = loc
+	c.out.Location = append(c.out.Location, loc)
+	return loc, nil
+}
+
+func (c *otlpConverter) mapping(idx int32) (*profile.Mapping, error) {
+	if m, ok := c.mappings[idx]; ok {
+		return m, nil
+	}
+	mappings := c.dict.GetMappingTable()
+	if int(idx) >= len(mappings) {
+		return nil, fmt.Errorf("mapping index %d out of range", idx)
+	}
+	in := mappings[idx]
+
+	file, err := c.str(in.GetFilenameStrindex())
+	if err != nil {
+		return nil, err
+	}
+	m := &profile.Mapping{
+		ID:     uint64(len(c.out.Mapping) + 1),
+		Start:  in.GetMemoryStart(),
+		Limit:  in.GetMemoryLimit(),
+		Offset: in.GetFileOffset(),
+		File:   file,
+	}
+
+	attrs := c.dict.GetAttributeTable()
+	buildIDs := make(map[string]string)
+	for _, attrIdx := range in.GetAttributeIndices() {
+		if attrIdx < 0 || int(attrIdx) >= len(attrs) {
+			return nil, fmt.Errorf("attribute index %d out of range", attrIdx)
+		}
+		key, err := c.str(attrs[attrIdx].GetKeyStrindex())
+		if err != nil {
+			return nil, err
+		}
+		buildIDs[key] = attrs[attrIdx].GetValue().GetStringValue()
+	}
+	for _, key := range otlpBuildIDAttributes {
+		if buildIDs[key] != "" {
+			m.BuildID = buildIDs[key]
+			break
+		}
+	}
+
+	c.mappings[idx] = m
+	c.out.Mapping = append(c.out.Mapping, m)
+	return m, nil
+}
+
+func (c *otlpConverter) function(idx int32) (*profile.Function, error) {
+	if fn, ok := c.functions[idx]; ok {
+		return fn, nil
+	}
+	functions := c.dict.GetFunctionTable()
+	if idx < 0 || int(idx) >= len(functions) {
+		return nil, fmt.Errorf("function index %d out of range", idx)
+	}
+	in := functions[idx]
+
+	name, err := c.str(in.GetNameStrindex())
+	if err != nil {
+		return nil, err
+	}
+	systemName, err := c.str(in.GetSystemNameStrindex())
+	if err != nil {
+		return nil, err
+	}
+	filename, err := c.str(in.GetFilenameStrindex())
+	if err != nil {
+		return nil, err
+	}
+	fn := &profile.Function{
+		ID:         uint64(len(c.out.Function) + 1),
+		Name:       name,
+		SystemName: systemName,
+		Filename:   filename,
+		StartLine:  in.GetStartLine(),
+	}
+
+	c.functions[idx] = fn
+	c.out.Function = append(c.out.Function, fn)
+	return fn, nil
+}
+
+func (c *otlpConverter) valueType(vt *profilespb.ValueType) (*profile.ValueType, error) {
+	typ, err := c.str(vt.GetTypeStrindex())
+	if err != nil {
+		return nil, err
+	}
+	unit, err := c.str(vt.GetUnitStrindex())
+	if err != nil {
+		return nil, err
+	}
+	return &profile.ValueType{Type: typ, Unit: unit}, nil
+}
+
+func (c *otlpConverter) str(idx int32) (string, error) {
+	strs := c.dict.GetStringTable()
+	if idx == 0 && len(strs) == 0 {
+		return "", nil
+	}
+	if idx < 0 || int(idx) >= len(strs) {
+		return "", fmt.Errorf("string index %d out of range", idx)
+	}
+	return strs[idx], nil
+}
+
+func otlpAnyValueString(v *commonpb.AnyValue) string {
+	switch v := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
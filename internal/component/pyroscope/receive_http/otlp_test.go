@@ -0,0 +1,171 @@
+package receive_http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	profilespb "go.opentelemetry.io/proto/otlp/profiles/v1development"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestForwardsProfilesOTLP(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		marshal     func(proto.Message) ([]byte, error)
+	}{
+		{
+			name:        "protobuf",
+			contentType: "application/x-protobuf",
+			marshal:     proto.Marshal,
+		},
+		{
+			name:        "json",
+			contentType: "application/json",
+			marshal:     protojson.Marshal,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			appendables := createTestAppendables([]error{nil, nil})
+			port := startComponent(t, appendables)
+
+			body, err := tc.marshal(testOTLPRequest())
+			require.NoError(t, err)
+
+			resp, err := http.Post(
+				fmt.Sprintf("http://localhost:%d%s", port, otlpProfilesHTTPPath),
+				tc.contentType,
+				bytes.NewReader(body),
+			)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+
+			for _, app := range appendables {
+				a := app.(*testAppender)
+				require.Equal(t, []string{
+					`{__delta__="false", __name__="process_cpu", k8s_pod_name="pod-1", service_name="checkout"}`,
+				}, a.series())
+				require.Equal(t, 1, a.samples())
+				requireTestOTLPProfile(t, a.pushedSamples[0][0].RawProfile)
+			}
+		})
+	}
+}
+
+func TestForwardsProfilesOTLP_Errors(t *testing.T) {
+	appendables := createTestAppendables([]error{nil})
+	port := startComponent(t, appendables)
+	url := fmt.Sprintf("http://localhost:%d%s", port, otlpProfilesHTTPPath)
+
+	resp, err := http.Post(url, "text/plain", bytes.NewReader([]byte("hello")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	req := testOTLPRequest()
+	req.ResourceProfiles[0].ScopeProfiles[0].Profiles[0].Samples[0].StackIndex = 42
+	body, err := proto.Marshal(req)
+	require.NoError(t, err)
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Empty(t, appendables[0].(*testAppender).series())
+}
+
+func TestConvertOTLPProfiles_DefaultServiceName(t *testing.T) {
+	req := testOTLPRequest()
+	req.ResourceProfiles[0].Resource = nil
+
+	series, err := convertOTLPProfiles(req)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Equal(t, `{__delta__="false", __name__="process_cpu", service_name="unknown_service"}`, series[0].labels.String())
+}
+
+func testOTLPRequest() *colprofilespb.ExportProfilesServiceRequest {
+	return &colprofilespb.ExportProfilesServiceRequest{
+		Dictionary: &profilespb.ProfilesDictionary{
+			StringTable: []string{"", "samples", "count", "cpu", "nanoseconds", "main", "main.go", "/bin/app", "thread.name"},
+			MappingTable: []*profilespb.Mapping{
+				{},
+				{MemoryStart: 0x1000, MemoryLimit: 0x2000, FilenameStrindex: 7},
+			},
+			FunctionTable: []*profilespb.Function{
+				{},
+				{NameStrindex: 5, FilenameStrindex: 6},
+			},
+			LocationTable: []*profilespb.Location{
+				{},
+				{MappingIndex: 1, Address: 0x1100, Lines: []*profilespb.Line{{FunctionIndex: 1, Line: 10}}},
+			},
+			StackTable: []*profilespb.Stack{
+				{},
+				{LocationIndices: []int32{1}},
+			},
+			AttributeTable: []*profilespb.KeyValueAndUnit{
+				{},
+				{KeyStrindex: 8, Value: stringValue("worker")},
+			},
+		},
+		ResourceProfiles: []*profilespb.ResourceProfiles{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					{Key: "service.name", Value: stringValue("checkout")},
+					{Key: "k8s.pod.name", Value: stringValue("pod-1")},
+				},
+			},
+			ScopeProfiles: []*profilespb.ScopeProfiles{{
+				Profiles: []*profilespb.Profile{{
+					SampleType:   &profilespb.ValueType{TypeStrindex: 1, UnitStrindex: 2},
+					PeriodType:   &profilespb.ValueType{TypeStrindex: 3, UnitStrindex: 4},
+					Period:       10_000_000,
+					TimeUnixNano: 1_700_000_000_000_000_000,
+					DurationNano: 1_000_000_000,
+					Samples: []*profilespb.Sample{
+						{StackIndex: 1, Values: []int64{5}, AttributeIndices: []int32{1}},
+					},
+				}},
+			}},
+		}},
+	}
+}
+
+func requireTestOTLPProfile(t *testing.T, raw []byte) {
+	t.Helper()
+
+	p, err := profile.ParseData(raw)
+	require.NoError(t, err)
+
+	require.Equal(t, []*profile.ValueType{{Type: "samples", Unit: "count"}}, p.SampleType)
+	require.Equal(t, &profile.ValueType{Type: "cpu", Unit: "nanoseconds"}, p.PeriodType)
+	require.Equal(t, int64(10_000_000), p.Period)
+	require.Equal(t, int64(1_700_000_000_000_000_000), p.TimeNanos)
+
+	require.Len(t, p.Sample, 1)
+	s := p.Sample[0]
+	require.Equal(t, []int64{5}, s.Value)
+	require.Equal(t, map[string][]string{"thread.name": {"worker"}}, s.Label)
+	require.Len(t, s.Location, 1)
+	require.Equal(t, uint64(0x1100), s.Location[0].Address)
+	require.Equal(t, "/bin/app", s.Location[0].Mapping.File)
+	require.Len(t, s.Location[0].Line, 1)
+	require.Equal(t, "main", s.Location[0].Line[0].Function.Name)
+	require.Equal(t, "main.go", s.Location[0].Line[0].Function.Filename)
+	require.Equal(t, int64(10), s.Location[0].Line[0].Line)
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
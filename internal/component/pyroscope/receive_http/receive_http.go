@@ -17,6 +17,7 @@ import (
 	fnet "github.com/grafana/alloy/internal/component/common/net"
 	"github.com/grafana/alloy/internal/component/pyroscope"
 	pyroutil "github.com/grafana/alloy/internal/component/pyroscope/util"
+	"github.com/grafana/alloy/internal/component/pyroscope/util/glue"
 	"github.com/grafana/alloy/internal/component/pyroscope/write"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -38,7 +39,11 @@ func init() {
 		Args:      Arguments{},
 		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
 			tracer := opts.Tracer.Tracer("pyroscope.receive_http")
-			return New(opts.Logger, tracer, opts.Registerer, args.(Arguments))
+			debugDataPublisher, err := glue.NewDebugDataPublisher(opts)
+			if err != nil {
+				return nil, err
+			}
+			return New(opts.Logger, tracer, opts.Registerer, debugDataPublisher, args.(Arguments))
 		},
 	})
 }
@@ -65,9 +70,12 @@ type Component struct {
 	mut                sync.Mutex
 	logger             log.Logger
 	tracer             trace.Tracer
+	debugDataPublisher pyroscope.DebugDataPublisher
 }
 
-func New(logger log.Logger, tracer trace.Tracer, reg prometheus.Registerer, args Arguments) (*Component, error) {
+var _ component.LiveDebugging = (*Component)(nil)
+
+func New(logger log.Logger, tracer trace.Tracer, reg prometheus.Registerer, debugDataPublisher pyroscope.DebugDataPublisher, args Arguments) (*Component, error) {
 	uncheckedCollector := util.NewUncheckedCollector(nil)
 	reg.MustRegister(uncheckedCollector)
 
@@ -76,6 +84,7 @@ func New(logger log.Logger, tracer trace.Tracer, reg prometheus.Registerer, args
 		tracer:             tracer,
 		uncheckedCollector: uncheckedCollector,
 		appendables:        args.ForwardTo,
+		debugDataPublisher: debugDataPublisher,
 	}
 
 	if err := c.Update(args); err != nil {
@@ -147,6 +156,9 @@ func (c *Component) update(args component.Arguments) (bool, error) {
 		// mount connect go pushv1
 		pathPush, handlePush := pushv1connect.NewPusherServiceHandler(c)
 		router.PathPrefix(pathPush).Handler(handlePush).Methods(http.MethodPost)
+
+		// mount the OTLP profiles endpoints over gRPC and HTTP
+		c.mountOTLP(router)
 	})
 }
 
@@ -178,6 +190,15 @@ func (c *Component) Push(ctx context.Context, req *connect.Request[pushv1.PushRe
 	defer sp.End()
 	l := pyroutil.TraceLog(c.logger, sp)
 
+	for _, series := range req.Msg.Series {
+		c.debugDataPublisher.Publish(uint64(len(series.Samples)), func() string {
+			lb := labels.NewBuilder(labels.EmptyLabels())
+			setLabelBuilderFromAPI(lb, series.Labels)
+			lbls := ensureServiceName(lb.Labels())
+			return fmt.Sprintf("labels: %s\n%s", lbls.String(), pyroscope.SamplesDebugString(lbls.Get(pyroscope.LabelName), apiToAlloySamples(series.Samples)))
+		})
+	}
+
 	var wg sync.WaitGroup
 	var errs error
 	var errorMut sync.Mutex
@@ -265,6 +286,15 @@ func (c *Component) handleIngest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c.debugDataPublisher.Publish(1, func() string {
+		return fmt.Sprintf("labels: %s\n%s", lbls.String(), pyroscope.IncomingProfileDebugString(&pyroscope.IncomingProfile{
+			RawBody:     buf.Bytes(),
+			ContentType: r.Header.Values(pyroscope.HeaderContentType),
+			URL:         r.URL,
+			Labels:      lbls,
+		}))
+	})
+
 	var wg sync.WaitGroup
 	var errs error
 	var errorMut sync.Mutex
@@ -303,6 +333,9 @@ func (c *Component) handleIngest(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
+
 func (c *Component) shutdownServer() {
 	if c.grpcServer != nil {
 		c.grpcServer.GracefulStop()
@@ -431,6 +431,7 @@ func startComponent(t *testing.T, appendables []pyroscope.Appendable) int {
 		util.TestAlloyLogger(t),
 		noop.Tracer{},
 		prometheus.NewRegistry(),
+		nil,
 		args,
 	)
 	require.NoError(t, err)
@@ -576,6 +577,7 @@ func TestUpdateArgs(t *testing.T) {
 		util.TestAlloyLogger(t),
 		noop.Tracer{},
 		prometheus.NewRegistry(),
+		nil,
 		args,
 	)
 	require.NoError(t, err)
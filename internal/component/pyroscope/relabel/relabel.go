@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/alloy/internal/component"
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/pyroscope"
+	"github.com/grafana/alloy/internal/component/pyroscope/util/glue"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 
@@ -81,10 +82,13 @@ type Component struct {
 	cache        *lru.Cache[model.Fingerprint, []cacheItem]
 	maxCacheSize int
 	exited       atomic.Bool
+
+	debugDataPublisher pyroscope.DebugDataPublisher
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
 )
 
 // New creates a new pyroscope.relabel component.
@@ -94,11 +98,17 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		return nil, err
 	}
 
+	debugDataPublisher, err := glue.NewDebugDataPublisher(o)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Component{
-		opts:         o,
-		metrics:      newMetrics(o.Registerer),
-		cache:        cache,
-		maxCacheSize: args.MaxCacheSize,
+		opts:               o,
+		metrics:            newMetrics(o.Registerer),
+		cache:              cache,
+		maxCacheSize:       args.MaxCacheSize,
+		debugDataPublisher: debugDataPublisher,
 	}
 
 	c.fanout = pyroscope.NewFanout(args.ForwardTo, o.ID, o.Registerer)
@@ -166,6 +176,9 @@ func (c *Component) Append(ctx context.Context, lbls labels.Labels, samples []*p
 
 	if lbls.IsEmpty() {
 		c.metrics.profilesOutgoing.Inc()
+		c.publishDebugData(lbls, lbls, uint64(len(samples)), func() string {
+			return pyroscope.SamplesDebugString(lbls.Get(pyroscope.LabelName), samples)
+		})
 		return c.fanout.Appender().Append(ctx, lbls, samples)
 	}
 
@@ -173,10 +186,14 @@ func (c *Component) Append(ctx context.Context, lbls labels.Labels, samples []*p
 	if !keep {
 		c.metrics.profilesDropped.Inc()
 		level.Debug(c.opts.Logger).Log("msg", "profile dropped by relabel rules", "labels", lbls.String())
+		c.publishDebugData(lbls, newLabels, 0, nil)
 		return nil
 	}
 
 	c.metrics.profilesOutgoing.Inc()
+	c.publishDebugData(lbls, newLabels, uint64(len(samples)), func() string {
+		return pyroscope.SamplesDebugString(newLabels.Get(pyroscope.LabelName), samples)
+	})
 	return c.fanout.Appender().Append(ctx, newLabels, samples)
 }
 
@@ -192,21 +209,41 @@ func (c *Component) AppendIngest(ctx context.Context, profile *pyroscope.Incomin
 
 	if profile.Labels.IsEmpty() {
 		c.metrics.profilesOutgoing.Inc()
+		c.publishDebugData(profile.Labels, profile.Labels, 1, func() string {
+			return pyroscope.IncomingProfileDebugString(profile)
+		})
 		return c.fanout.Appender().AppendIngest(ctx, profile)
 	}
 
+	originalLabels := profile.Labels
 	newLabels, keep := c.relabel(profile.Labels)
 	if !keep {
 		c.metrics.profilesDropped.Inc()
 		level.Debug(c.opts.Logger).Log("msg", "profile dropped by relabel rules")
+		c.publishDebugData(originalLabels, newLabels, 0, nil)
 		return nil
 	}
 
 	profile.Labels = newLabels
 	c.metrics.profilesOutgoing.Inc()
+	c.publishDebugData(originalLabels, newLabels, 1, func() string {
+		return pyroscope.IncomingProfileDebugString(profile)
+	})
 	return c.fanout.Appender().AppendIngest(ctx, profile)
 }
 
+// publishDebugData publishes the relabeled profiles to live debugging. The
+// summary of the profiles is only computed if dataFunc is not nil.
+func (c *Component) publishDebugData(lbls, newLabels labels.Labels, count uint64, dataFunc func() string) {
+	c.debugDataPublisher.Publish(count, func() string {
+		res := fmt.Sprintf("labels: %s => %s", lbls.String(), newLabels.String())
+		if dataFunc != nil {
+			res += "\n" + dataFunc()
+		}
+		return res
+	})
+}
+
 func (c *Component) Appender() pyroscope.Appender {
 	return c
 }
@@ -305,6 +342,9 @@ func toLabelsLabels(ls model.LabelSet) labels.Labels {
 	return result.Labels()
 }
 
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
+
 func (c *Component) Upload(j debuginfo.UploadJob) {
 	c.fanout.Upload(j)
 }
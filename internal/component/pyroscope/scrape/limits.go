@@ -0,0 +1,63 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+var errBodySizeLimit = errors.New("body size limit exceeded")
+
+// scrapeLimits holds the limits applied to each scrape of a target.
+type scrapeLimits struct {
+	bodySizeLimit         int64
+	labelLimit            int
+	labelNameLengthLimit  int
+	labelValueLengthLimit int
+}
+
+func newScrapeLimits(cfg Arguments) scrapeLimits {
+	return scrapeLimits{
+		bodySizeLimit:         int64(cfg.BodySizeLimit),
+		labelLimit:            int(cfg.LabelLimit),
+		labelNameLengthLimit:  int(cfg.LabelNameLengthLimit),
+		labelValueLengthLimit: int(cfg.LabelValueLengthLimit),
+	}
+}
+
+// verifyLabelLimits checks the labels sent along with the profiles of a
+// target against the limits. Private labels are not sent, with the exception
+// of the profile name, so they don't count towards the limits.
+func verifyLabelLimits(lset labels.Labels, limits scrapeLimits) error {
+	var (
+		name    = lset.Get(model.MetricNameLabel)
+		nLabels int
+	)
+	err := lset.Validate(func(l labels.Label) error {
+		if strings.HasPrefix(l.Name, model.ReservedLabelPrefix) && l.Name != model.MetricNameLabel {
+			return nil
+		}
+		nLabels++
+
+		if limits.labelNameLengthLimit > 0 && len(l.Name) > limits.labelNameLengthLimit {
+			return fmt.Errorf("label_name_length_limit exceeded (profile: %.50s, label name: %.50s, length: %d, limit: %d)",
+				name, l.Name, len(l.Name), limits.labelNameLengthLimit)
+		}
+		if limits.labelValueLengthLimit > 0 && len(l.Value) > limits.labelValueLengthLimit {
+			return fmt.Errorf("label_value_length_limit exceeded (profile: %.50s, label name: %.50s, value: %.50q, length: %d, limit: %d)",
+				name, l.Name, l.Value, len(l.Value), limits.labelValueLengthLimit)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if limits.labelLimit > 0 && nLabels > limits.labelLimit {
+		return fmt.Errorf("label_limit exceeded (profile: %.50s, number of labels: %d, limit: %d)", name, nLabels, limits.labelLimit)
+	}
+	return nil
+}
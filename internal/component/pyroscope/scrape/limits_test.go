@@ -0,0 +1,66 @@
+package scrape
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyLabelLimits(t *testing.T) {
+	lset := labels.FromStrings(
+		model.MetricNameLabel, pprofMemory,
+		model.AddressLabel, "localhost:9090",
+		model.SchemeLabel, "http",
+		ProfilePath, "/debug/pprof/allocs",
+		"instance", "localhost:9090",
+		"service_name", "my-service",
+	)
+
+	for _, tt := range []struct {
+		name        string
+		limits      scrapeLimits
+		expectedErr string
+	}{
+		{
+			name:   "no limits",
+			limits: scrapeLimits{},
+		},
+		{
+			name:   "within limits",
+			limits: scrapeLimits{labelLimit: 3, labelNameLengthLimit: 12, labelValueLengthLimit: 14},
+		},
+		{
+			name:        "label limit",
+			limits:      scrapeLimits{labelLimit: 2},
+			expectedErr: "label_limit exceeded (profile: memory, number of labels: 3, limit: 2)",
+		},
+		{
+			name:        "label name length limit",
+			limits:      scrapeLimits{labelNameLengthLimit: 8},
+			expectedErr: "label_name_length_limit exceeded (profile: memory, label name: service_name, length: 12, limit: 8)",
+		},
+		{
+			name:        "label value length limit",
+			limits:      scrapeLimits{labelValueLengthLimit: 10},
+			expectedErr: `label_value_length_limit exceeded (profile: memory, label name: instance, value: "localhost:9090", length: 14, limit: 10)`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyLabelLimits(lset, tt.limits)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("long values are truncated", func(t *testing.T) {
+		lset := labels.FromStrings(model.MetricNameLabel, pprofMemory, "foo", strings.Repeat("a", 100))
+		err := verifyLabelLimits(lset, scrapeLimits{labelValueLengthLimit: 10})
+		require.EqualError(t, err, `label_value_length_limit exceeded (profile: memory, label name: foo, value: "`+strings.Repeat("a", 50)+`", length: 100, limit: 10)`)
+	})
+}
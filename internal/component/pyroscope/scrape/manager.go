@@ -8,6 +8,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/component/pyroscope"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/prometheus/client_golang/prometheus"
 	config_util "github.com/prometheus/common/config"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 )
@@ -17,6 +18,8 @@ var reloadInterval = 5 * time.Second
 type Options struct {
 	// Optional HTTP client options to use when scraping.
 	HTTPClientOptions []config_util.HTTPClientOption
+	// Optional registerer for the scrape metrics.
+	Registerer prometheus.Registerer
 }
 
 type Manager struct {
@@ -43,6 +46,7 @@ func NewManager(o Options, config Arguments, appendable pyroscope.Appendable, lo
 		o.HTTPClientOptions,
 		config,
 		appendable,
+		newMetrics(o.Registerer),
 		logger,
 	)
 	if err != nil {
@@ -0,0 +1,37 @@
+package scrape
+
+import (
+	pyrometricsutil "github.com/grafana/alloy/internal/component/pyroscope/util/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	exceededBodySizeLimit prometheus.Counter
+	exceededTargetLimit   prometheus.Counter
+	exceededLabelLimits   prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		exceededBodySizeLimit: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pyroscope_scrape_exceeded_body_size_limit_total",
+			Help: "Total number of scrapes that failed because the response body exceeded body_size_limit.",
+		}),
+		exceededTargetLimit: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pyroscope_scrape_exceeded_target_limit_total",
+			Help: "Total number of scrapes that failed because the number of targets exceeded target_limit.",
+		}),
+		exceededLabelLimits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pyroscope_scrape_exceeded_label_limits_total",
+			Help: "Total number of scrapes that failed because the target labels exceeded label_limit, label_name_length_limit or label_value_length_limit.",
+		}),
+	}
+
+	if reg != nil {
+		m.exceededBodySizeLimit = pyrometricsutil.MustRegisterOrGet(reg, m.exceededBodySizeLimit).(prometheus.Counter)
+		m.exceededTargetLimit = pyrometricsutil.MustRegisterOrGet(reg, m.exceededTargetLimit).(prometheus.Counter)
+		m.exceededLabelLimits = pyrometricsutil.MustRegisterOrGet(reg, m.exceededLabelLimits).(prometheus.Counter)
+	}
+
+	return m
+}
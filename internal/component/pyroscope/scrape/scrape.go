@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/alecthomas/units"
 	config_util "github.com/prometheus/common/config"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 
@@ -67,22 +68,20 @@ type Arguments struct {
 	// The duration for a profile to be scrapped.
 	DeltaProfilingDuration time.Duration `alloy:"delta_profiling_duration,attr,optional"`
 
-	// todo(ctovena): add support for limits.
-	// // An uncompressed response body larger than this many bytes will cause the
-	// // scrape to fail. 0 means no limit.
-	// BodySizeLimit units.Base2Bytes `alloy:"body_size_limit,attr,optional"`
-	// // More than this many targets after the target relabeling will cause the
-	// // scrapes to fail.
-	// TargetLimit uint `alloy:"target_limit,attr,optional"`
-	// // More than this many labels post metric-relabeling will cause the scrape
-	// // to fail.
-	// LabelLimit uint `alloy:"label_limit,attr,optional"`
-	// // More than this label name length post metric-relabeling will cause the
-	// // scrape to fail.
-	// LabelNameLengthLimit uint `alloy:"label_name_length_limit,attr,optional"`
-	// // More than this label value length post metric-relabeling will cause the
-	// // scrape to fail.
-	// LabelValueLengthLimit uint `alloy:"label_value_length_limit,attr,optional"`
+	// An uncompressed response body larger than this many bytes will cause the
+	// scrape to fail. 0 means no limit.
+	BodySizeLimit units.Base2Bytes `alloy:"body_size_limit,attr,optional"`
+	// More than this many targets after the target relabeling will cause the
+	// scrapes to fail.
+	TargetLimit uint `alloy:"target_limit,attr,optional"`
+	// More than this many labels on a target will cause the scrape to fail.
+	LabelLimit uint `alloy:"label_limit,attr,optional"`
+	// More than this label name length on a target will cause the scrape to
+	// fail.
+	LabelNameLengthLimit uint `alloy:"label_name_length_limit,attr,optional"`
+	// More than this label value length on a target will cause the scrape to
+	// fail.
+	LabelValueLengthLimit uint `alloy:"label_value_length_limit,attr,optional"`
 
 	HTTPClientConfig component_config.HTTPClientConfig `alloy:",squash"`
 
@@ -217,6 +216,9 @@ func (arg *Arguments) Validate() error {
 	if arg.ScrapeTimeout.Seconds() <= 0 {
 		return fmt.Errorf("scrape_timeout must be greater than 0")
 	}
+	if arg.BodySizeLimit < 0 {
+		return fmt.Errorf("body_size_limit must be greater than or equal to 0")
+	}
 
 	// ScrapeInterval must be at least 2 seconds, because if
 	// ProfilingTarget.Delta is true the ScrapeInterval - 1s is propagated in
@@ -273,6 +275,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		HTTPClientOptions: []config_util.HTTPClientOption{
 			config_util.WithDialContextFunc(httpData.DialFunc),
 		},
+		Registerer: o.Registerer,
 	}
 	scraper, err := NewManager(scrapeHttpOptions, args, alloyAppendable, o.Logger)
 	if err != nil {
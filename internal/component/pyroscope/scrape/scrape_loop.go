@@ -3,6 +3,7 @@ package scrape
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -31,12 +32,13 @@ type scrapePool struct {
 	logger       log.Logger
 	scrapeClient *http.Client
 	appendable   pyroscope.Appendable
+	metrics      *metrics
 
 	mtx           sync.RWMutex
 	activeTargets map[uint64]*scrapeLoop
 }
 
-func newScrapePool(hco []commonconfig.HTTPClientOption, cfg Arguments, appendable pyroscope.Appendable, logger log.Logger) (*scrapePool, error) {
+func newScrapePool(hco []commonconfig.HTTPClientOption, cfg Arguments, appendable pyroscope.Appendable, metrics *metrics, logger log.Logger) (*scrapePool, error) {
 	scrapeClient, err := commonconfig.NewClientFromConfig(*cfg.HTTPClientConfig.Convert(), cfg.JobName, hco...)
 	if err != nil {
 		return nil, err
@@ -47,6 +49,7 @@ func newScrapePool(hco []commonconfig.HTTPClientOption, cfg Arguments, appendabl
 		logger:        logger,
 		scrapeClient:  scrapeClient,
 		appendable:    appendable,
+		metrics:       metrics,
 		activeTargets: map[uint64]*scrapeLoop{},
 	}, nil
 }
@@ -72,7 +75,7 @@ func (tg *scrapePool) sync(groups []*targetgroup.Group) {
 
 	for _, t := range actives {
 		if _, ok := tg.activeTargets[t.Hash()]; !ok {
-			loop := newScrapeLoop(t, tg.scrapeClient, tg.appendable, tg.config.ScrapeInterval, tg.config.ScrapeTimeout, tg.logger)
+			loop := tg.newScrapeLoop(t)
 			tg.activeTargets[t.Hash()] = loop
 			loop.start()
 		}
@@ -89,6 +92,24 @@ Outer:
 		t.stop(false)
 		delete(tg.activeTargets, h)
 	}
+
+	tg.refreshTargetLimitErr()
+}
+
+func (tg *scrapePool) newScrapeLoop(t *Target) *scrapeLoop {
+	return newScrapeLoop(t, tg.scrapeClient, tg.appendable, tg.config.ScrapeInterval, tg.config.ScrapeTimeout, newScrapeLimits(tg.config), tg.metrics, tg.logger)
+}
+
+// refreshTargetLimitErr makes all the scrapes fail while the number of
+// active targets exceeds the target limit.
+func (tg *scrapePool) refreshTargetLimitErr() {
+	var err error
+	if limit := tg.config.TargetLimit; limit > 0 && len(tg.activeTargets) > int(limit) {
+		err = fmt.Errorf("target_limit exceeded (number of targets: %d, limit: %d)", len(tg.activeTargets), limit)
+	}
+	for _, loop := range tg.activeTargets {
+		loop.setForcedError(err)
+	}
 }
 
 func (tg *scrapePool) reload(cfg Arguments) error {
@@ -97,9 +118,11 @@ func (tg *scrapePool) reload(cfg Arguments) error {
 
 	if tg.config.ScrapeInterval == cfg.ScrapeInterval &&
 		tg.config.ScrapeTimeout == cfg.ScrapeTimeout &&
+		newScrapeLimits(tg.config) == newScrapeLimits(cfg) &&
 		reflect.DeepEqual(tg.config.HTTPClientConfig, cfg.HTTPClientConfig) {
 
 		tg.config = cfg
+		tg.refreshTargetLimitErr()
 		return nil
 	}
 	tg.config = cfg
@@ -112,10 +135,11 @@ func (tg *scrapePool) reload(cfg Arguments) error {
 	for hash, t := range tg.activeTargets {
 		// restart the loop with the new configuration
 		t.stop(false)
-		loop := newScrapeLoop(t.Target, tg.scrapeClient, tg.appendable, tg.config.ScrapeInterval, tg.config.ScrapeTimeout, tg.logger)
+		loop := tg.newScrapeLoop(t.Target)
 		tg.activeTargets[hash] = loop
 		loop.start()
 	}
+	tg.refreshTargetLimitErr()
 	return nil
 }
 
@@ -151,6 +175,14 @@ type scrapeLoop struct {
 
 	scrapeClient *http.Client
 	appender     pyroscope.Appender
+	limits       scrapeLimits
+	metrics      *metrics
+
+	// labelLimitErr is set if the labels of the target exceed the limits.
+	labelLimitErr error
+
+	forcedErrMtx sync.Mutex
+	forcedErr    error
 
 	req               *http.Request
 	logger            log.Logger
@@ -160,7 +192,7 @@ type scrapeLoop struct {
 	wg                sync.WaitGroup
 }
 
-func newScrapeLoop(t *Target, scrapeClient *http.Client, appendable pyroscope.Appendable, interval, timeout time.Duration, logger log.Logger) *scrapeLoop {
+func newScrapeLoop(t *Target, scrapeClient *http.Client, appendable pyroscope.Appendable, interval, timeout time.Duration, limits scrapeLimits, metrics *metrics, logger log.Logger) *scrapeLoop {
 	// if the URL parameter have a seconds parameter, then the collection will
 	// take at least scrape_duration - 1 second, as the HTTP request will block
 	// until the profile is collected.
@@ -173,15 +205,32 @@ func newScrapeLoop(t *Target, scrapeClient *http.Client, appendable pyroscope.Ap
 		appender = NewDeltaAppender(appender, t.allLabels)
 	}
 	return &scrapeLoop{
-		Target:       t,
-		logger:       logger,
-		scrapeClient: scrapeClient,
-		appender:     appender,
-		interval:     interval,
-		timeout:      timeout,
+		Target:        t,
+		logger:        logger,
+		scrapeClient:  scrapeClient,
+		appender:      appender,
+		limits:        limits,
+		metrics:       metrics,
+		labelLimitErr: verifyLabelLimits(t.allLabels, limits),
+		interval:      interval,
+		timeout:       timeout,
 	}
 }
 
+// setForcedError makes the scrapes of the target fail with err until it is
+// reset with a nil error.
+func (t *scrapeLoop) setForcedError(err error) {
+	t.forcedErrMtx.Lock()
+	defer t.forcedErrMtx.Unlock()
+	t.forcedErr = err
+}
+
+func (t *scrapeLoop) getForcedError() error {
+	t.forcedErrMtx.Lock()
+	defer t.forcedErrMtx.Unlock()
+	return t.forcedErr
+}
+
 func (t *scrapeLoop) start() {
 	t.graceShut = make(chan struct{})
 	t.once = sync.Once{}
@@ -221,8 +270,24 @@ func (t *scrapeLoop) scrape() {
 
 	profileType = t.allLabels.Get(ProfileName)
 
+	if err := t.getForcedError(); err != nil {
+		level.Error(t.logger).Log("msg", "scrape skipped", "target", t, "err", err)
+		t.metrics.exceededTargetLimit.Inc()
+		t.updateTargetStatus(start, err)
+		return
+	}
+	if t.labelLimitErr != nil {
+		level.Error(t.logger).Log("msg", "scrape skipped", "target", t, "err", t.labelLimitErr)
+		t.metrics.exceededLabelLimits.Inc()
+		t.updateTargetStatus(start, t.labelLimitErr)
+		return
+	}
+
 	if err := t.fetchProfile(scrapeCtx, profileType, buf); err != nil {
 		level.Error(t.logger).Log("msg", "fetch profile failed", "target", t, "err", err)
+		if errors.Is(err, errBodySizeLimit) {
+			t.metrics.exceededBodySizeLimit.Inc()
+		}
 		t.updateTargetStatus(start, err)
 		return
 	}
@@ -270,10 +335,23 @@ func (t *scrapeLoop) fetchProfile(ctx context.Context, profileType string, buf i
 	}
 	defer resp.Body.Close()
 
-	b, err := io.ReadAll(io.TeeReader(resp.Body, buf))
+	bodySizeLimit := t.limits.bodySizeLimit
+	if bodySizeLimit > 0 && resp.ContentLength > bodySizeLimit {
+		return fmt.Errorf("%w (content length: %d bytes, limit: %d bytes)", errBodySizeLimit, resp.ContentLength, bodySizeLimit)
+	}
+
+	body := io.Reader(resp.Body)
+	if bodySizeLimit > 0 {
+		// Read one more byte to find out whether the body exceeds the limit.
+		body = io.LimitReader(resp.Body, bodySizeLimit+1)
+	}
+	b, err := io.ReadAll(io.TeeReader(body, buf))
 	if err != nil {
 		return fmt.Errorf("failed to read body: %w", err)
 	}
+	if bodySizeLimit > 0 && int64(len(b)) > bodySizeLimit {
+		return fmt.Errorf("%w (limit: %d bytes)", errBodySizeLimit, bodySizeLimit)
+	}
 
 	if resp.StatusCode/100 != 2 {
 		if len(b) > 0 {
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/component/pyroscope"
 	"github.com/grafana/alloy/internal/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	config_util "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
@@ -40,6 +41,7 @@ func TestScrapePool(t *testing.T) {
 		func(ctx context.Context, labels labels.Labels, samples []*pyroscope.RawSample) error {
 			return nil
 		}),
+		newMetrics(nil),
 		util.TestLogger(t))
 	require.NoError(t, err)
 
@@ -158,7 +160,7 @@ func TestScrapeLoop(t *testing.T) {
 			require.Equal(t, []byte{0x0A, 0x02, 0x6F, 0x6B}, samples[0].RawProfile)
 			return nil
 		}),
-		200*time.Millisecond, 30*time.Second, util.TestLogger(t))
+		200*time.Millisecond, 30*time.Second, scrapeLimits{}, newMetrics(nil), util.TestLogger(t))
 	defer loop.stop(true)
 
 	require.Equal(t, HealthUnknown, loop.Health())
@@ -176,6 +178,144 @@ func TestScrapeLoop(t *testing.T) {
 	require.NotEmpty(t, loop.LastScrapeDuration())
 }
 
+func TestScrapeLoop_BodySizeLimit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"))
+
+	profile := []byte{0x0A, 0x02, 0x6F, 0x6B}
+	chunked := atomic.NewBool(false)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if chunked.Load() {
+			// Flushing before writing the body removes the Content-Length header.
+			w.(http.Flusher).Flush()
+		}
+		w.Write(profile)
+	}))
+	defer server.Close()
+
+	var (
+		appendTotal = atomic.NewInt64(0)
+		m           = newMetrics(nil)
+	)
+	newLoop := func(limit int64) *scrapeLoop {
+		return newScrapeLoop(
+			NewTarget(labels.FromStrings(
+				model.SchemeLabel, "http",
+				model.AddressLabel, strings.TrimPrefix(server.URL, "http://"),
+				ProfilePath, "/debug/pprof/allocs",
+			), url.Values{}),
+			server.Client(),
+			pyroscope.AppendableFunc(func(_ context.Context, labels labels.Labels, samples []*pyroscope.RawSample) error {
+				appendTotal.Inc()
+				return nil
+			}),
+			time.Second, time.Second, scrapeLimits{bodySizeLimit: limit}, m, util.TestLogger(t))
+	}
+
+	for _, isChunked := range []bool{false, true} {
+		chunked.Store(isChunked)
+
+		loop := newLoop(int64(len(profile)) - 1)
+		loop.scrape()
+		require.Equal(t, HealthBad, loop.Health())
+		require.ErrorIs(t, loop.LastError(), errBodySizeLimit)
+
+		loop = newLoop(int64(len(profile)))
+		loop.scrape()
+		require.Equal(t, HealthGood, loop.Health())
+	}
+
+	require.Equal(t, int64(2), appendTotal.Load())
+	require.Equal(t, 2.0, testutil.ToFloat64(m.exceededBodySizeLimit))
+}
+
+func TestScrapeLoop_LabelLimits(t *testing.T) {
+	target := NewTarget(labels.FromStrings(
+		model.MetricNameLabel, pprofMemory,
+		model.SchemeLabel, "http",
+		model.AddressLabel, "127.0.0.1:239",
+		ProfilePath, "/debug/pprof/allocs",
+		"service_name", "my-service",
+	), url.Values{})
+
+	m := newMetrics(nil)
+	loop := newScrapeLoop(
+		target,
+		&http.Client{},
+		pyroscope.AppendableFunc(func(_ context.Context, labels labels.Labels, samples []*pyroscope.RawSample) error {
+			return nil
+		}),
+		time.Second, time.Second, scrapeLimits{labelLimit: 1}, m, util.TestLogger(t))
+
+	loop.scrape()
+	require.Equal(t, HealthBad, loop.Health())
+	require.EqualError(t, loop.LastError(), "label_limit exceeded (profile: memory, number of labels: 2, limit: 1)")
+	require.Equal(t, 1.0, testutil.ToFloat64(m.exceededLabelLimits))
+}
+
+func TestScrapePool_TargetLimit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("go.opencensus.io/stats/view.(*worker).start"))
+
+	args := NewDefaultArguments()
+	args.ProfilingConfig.Block.Enabled = false
+	args.ProfilingConfig.Goroutine.Enabled = false
+	args.ProfilingConfig.Memory.Enabled = false
+	args.ProfilingConfig.ProcessCPU.Enabled = false
+	args.TargetLimit = 1
+
+	m := newMetrics(nil)
+	p, err := newScrapePool([]config_util.HTTPClientOption{}, args, pyroscope.AppendableFunc(
+		func(ctx context.Context, labels labels.Labels, samples []*pyroscope.RawSample) error {
+			return nil
+		}),
+		m,
+		util.TestLogger(t))
+	require.NoError(t, err)
+	defer p.stop()
+
+	forcedErrors := func() []error {
+		p.mtx.RLock()
+		defer p.mtx.RUnlock()
+
+		var errs []error
+		for _, loop := range p.activeTargets {
+			errs = append(errs, loop.getForcedError())
+		}
+		return errs
+	}
+
+	p.sync([]*targetgroup.Group{{
+		Targets: []model.LabelSet{
+			{model.AddressLabel: "localhost:9090", serviceNameLabel: "s"},
+			{model.AddressLabel: "localhost:8080", serviceNameLabel: "s"},
+		},
+	}})
+	errs := forcedErrors()
+	require.Len(t, errs, 2)
+	for _, err := range errs {
+		require.EqualError(t, err, "target_limit exceeded (number of targets: 2, limit: 1)")
+	}
+
+	// Raising the limit resets the error.
+	args.TargetLimit = 2
+	require.NoError(t, p.reload(args))
+	for _, err := range forcedErrors() {
+		require.NoError(t, err)
+	}
+
+	// Removing targets resets the error.
+	args.TargetLimit = 1
+	require.NoError(t, p.reload(args))
+	p.sync([]*targetgroup.Group{{
+		Targets: []model.LabelSet{
+			{model.AddressLabel: "localhost:9090", serviceNameLabel: "s"},
+		},
+	}})
+	errs = forcedErrors()
+	require.Len(t, errs, 1)
+	require.NoError(t, errs[0])
+}
+
 func TestGodeltaprofLoopAppender(t *testing.T) {
 	target := NewTarget(labels.FromStrings(
 		model.MetricNameLabel, pprofGoDeltaProfMemory,
@@ -192,7 +332,7 @@ func TestGodeltaprofLoopAppender(t *testing.T) {
 		target,
 		&http.Client{},
 		a,
-		200*time.Millisecond, 30*time.Second, util.TestLogger(t))
+		200*time.Millisecond, 30*time.Second, scrapeLimits{}, newMetrics(nil), util.TestLogger(t))
 	_, da := loop.appender.(*deltaAppender)
 	assert.False(t, da)
 }
@@ -205,6 +345,7 @@ func BenchmarkSync(b *testing.B) {
 		func(ctx context.Context, labels labels.Labels, samples []*pyroscope.RawSample) error {
 			return nil
 		}),
+		newMetrics(nil),
 		log.NewNopLogger())
 	require.NoError(b, err)
 	groups1 := []*targetgroup.Group{
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
@@ -152,6 +153,27 @@ func TestUnmarshalConfig(t *testing.T) {
 				return r
 			},
 		},
+		"limits": {
+			in: `
+			targets    = []
+			forward_to = null
+			body_size_limit          = "10MiB"
+			target_limit             = 100
+			label_limit              = 20
+			label_name_length_limit  = 64
+			label_value_length_limit = 256
+			`,
+			expected: func() Arguments {
+				r := NewDefaultArguments()
+				r.Targets = make([]discovery.Target, 0)
+				r.BodySizeLimit = 10 * units.MiB
+				r.TargetLimit = 100
+				r.LabelLimit = 20
+				r.LabelNameLengthLimit = 64
+				r.LabelValueLengthLimit = 256
+				return r
+			},
+		},
 		"invalid cpu scrape_interval": {
 			in: `
 			targets    = []
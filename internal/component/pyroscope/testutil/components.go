@@ -32,6 +32,7 @@ func CreateWriteComponent(l log.Logger, reg prometheus.Registerer, endpoint stri
 		"test",
 		"",
 		dataPath,
+		nil,
 		write.Arguments{Endpoints: []*write.EndpointOptions{&e}},
 	)
 	if err != nil {
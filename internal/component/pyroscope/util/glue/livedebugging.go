@@ -0,0 +1,31 @@
+package glue
+
+import (
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/pyroscope"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+// NewDebugDataPublisher returns a pyroscope.DebugDataPublisher which
+// publishes profiles to the live debugging service for the component.
+func NewDebugDataPublisher(o component.Options) (pyroscope.DebugDataPublisher, error) {
+	data, err := o.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	publisher := data.(livedebugging.DebugDataPublisher)
+	componentID := livedebugging.ComponentID(o.ID)
+
+	return func(count uint64, dataFunc func() string) {
+		publisher.PublishIfActive(livedebugging.NewData(componentID, livedebugging.PyroscopeProfile, count, dataFunc))
+	}, nil
+}
+
+// LiveDebuggingComponentGlue is a GenericComponentGlue for components which
+// publish data to live debugging.
+type LiveDebuggingComponentGlue[ARGS any] struct {
+	GenericComponentGlue[ARGS]
+}
+
+// LiveDebugging implements component.LiveDebugging.
+func (c *LiveDebuggingComponentGlue[ARGS]) LiveDebugging() {}
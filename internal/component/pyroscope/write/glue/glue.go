@@ -20,6 +20,10 @@ func init() {
 			args := c.(write.Arguments)
 			userAgent := useragent.Get()
 			uid := alloyseed.Get().UID
+			debugDataPublisher, err := glue.NewDebugDataPublisher(o)
+			if err != nil {
+				return nil, err
+			}
 
 			gc, err := write.New(
 				o.Logger,
@@ -31,12 +35,15 @@ func init() {
 				userAgent,
 				uid,
 				o.DataPath,
+				debugDataPublisher,
 				args,
 			)
 			if err != nil {
 				return nil, err
 			}
-			return &glue.GenericComponentGlue[write.Arguments]{Impl: gc}, nil
+			return &glue.LiveDebuggingComponentGlue[write.Arguments]{
+				GenericComponentGlue: glue.GenericComponentGlue[write.Arguments]{Impl: gc},
+			}, nil
 		},
 	})
 }
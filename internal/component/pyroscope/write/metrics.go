@@ -13,6 +13,11 @@ type metrics struct {
 	retries              *prometheus.CounterVec
 	latency              *prometheus.HistogramVec
 	debugInfoUploadBytes prometheus.Counter
+
+	walPendingRequests *prometheus.GaugeVec
+	walPendingBytes    *prometheus.GaugeVec
+	walDroppedRequests *prometheus.CounterVec
+	walDroppedBytes    *prometheus.CounterVec
 }
 
 func newMetrics(reg prometheus.Registerer) *metrics {
@@ -45,6 +50,22 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Name: "pyroscope_ebpf_debug_info_upload_bytes_total",
 			Help: "Total number of bytes uploaded to the debug info endpoint",
 		}),
+		walPendingRequests: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "pyroscope_write_wal_pending_requests",
+			Help: "Number of requests stored in the WAL which weren't sent yet.",
+		}, []string{"endpoint"}),
+		walPendingBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "pyroscope_write_wal_pending_bytes",
+			Help: "Size in bytes of the requests stored in the WAL which weren't sent yet.",
+		}, []string{"endpoint"}),
+		walDroppedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pyroscope_write_wal_dropped_requests_total",
+			Help: "Total number of requests dropped from the WAL because it was full or corrupted.",
+		}, []string{"endpoint"}),
+		walDroppedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pyroscope_write_wal_dropped_bytes_total",
+			Help: "Total number of bytes dropped from the WAL because it was full or corrupted.",
+		}, []string{"endpoint"}),
 	}
 
 	if reg != nil {
@@ -55,6 +76,10 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 		m.retries = pyrometricsutil.MustRegisterOrGet(reg, m.retries).(*prometheus.CounterVec)
 		m.latency = pyrometricsutil.MustRegisterOrGet(reg, m.latency).(*prometheus.HistogramVec)
 		m.debugInfoUploadBytes = pyrometricsutil.MustRegisterOrGet(reg, m.debugInfoUploadBytes).(prometheus.Counter)
+		m.walPendingRequests = pyrometricsutil.MustRegisterOrGet(reg, m.walPendingRequests).(*prometheus.GaugeVec)
+		m.walPendingBytes = pyrometricsutil.MustRegisterOrGet(reg, m.walPendingBytes).(*prometheus.GaugeVec)
+		m.walDroppedRequests = pyrometricsutil.MustRegisterOrGet(reg, m.walDroppedRequests).(*prometheus.CounterVec)
+		m.walDroppedBytes = pyrometricsutil.MustRegisterOrGet(reg, m.walDroppedBytes).(*prometheus.CounterVec)
 	}
 
 	return m
@@ -0,0 +1,392 @@
+package write
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"connectrpc.com/connect"
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/alloy/internal/component/pyroscope"
+	"github.com/grafana/alloy/internal/component/pyroscope/write/wal"
+	pushv1 "github.com/grafana/pyroscope/api/gen/proto/go/push/v1"
+)
+
+// WalArguments holds the settings for spooling profiles to disk before they
+// are sent to the endpoints.
+type WalArguments struct {
+	Enabled        bool             `alloy:"enabled,attr,optional"`
+	MaxSize        units.Base2Bytes `alloy:"max_size,attr,optional"`
+	MaxSegmentSize units.Base2Bytes `alloy:"max_segment_size,attr,optional"`
+}
+
+// DefaultWalArguments holds the default settings of the WAL.
+var DefaultWalArguments = WalArguments{
+	Enabled:        false,
+	MaxSize:        1 * units.GiB,
+	MaxSegmentSize: 64 * units.MiB,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (wa *WalArguments) SetToDefault() {
+	*wa = DefaultWalArguments
+}
+
+// Validate implements syntax.Validator.
+func (wa *WalArguments) Validate() error {
+	if wa.MaxSegmentSize <= 0 {
+		return errors.New("WAL max segment size must be greater than 0")
+	}
+	if wa.MaxSegmentSize >= wa.MaxSize {
+		return errors.New("WAL max segment size should be lower than max size")
+	}
+	return nil
+}
+
+func (wa WalArguments) queueOptions() wal.Options {
+	return wal.Options{
+		MaxSize:        int64(wa.MaxSize),
+		MaxSegmentSize: int64(wa.MaxSegmentSize),
+	}
+}
+
+// walQueue is the WAL of a single endpoint.
+type walQueue struct {
+	*wal.Queue
+
+	dir  string
+	url  string
+	opts wal.Options
+}
+
+// walQueues keeps the WAL of the endpoints open across updates of the
+// component, so that new profiles are appended after the ones which
+// weren't sent yet.
+type walQueues struct {
+	logger   log.Logger
+	metrics  *metrics
+	dataPath string
+
+	queues map[string]*walQueue // Keyed by directory.
+}
+
+func newWalQueues(logger log.Logger, metrics *metrics, dataPath string) *walQueues {
+	return &walQueues{
+		logger:   logger,
+		metrics:  metrics,
+		dataPath: dataPath,
+		queues:   make(map[string]*walQueue),
+	}
+}
+
+// get returns the WAL of each endpoint of args, and drops the WAL of
+// endpoints which are no longer configured. A nil slice is returned if the WAL
+// is disabled.
+func (w *walQueues) get(args Arguments) ([]*walQueue, error) {
+	if !args.WAL.Enabled {
+		return nil, nil
+	}
+
+	opts := args.WAL.queueOptions()
+	res := make([]*walQueue, 0, len(args.Endpoints))
+	inUse := make(map[string]struct{}, len(args.Endpoints))
+	for _, endpoint := range args.Endpoints {
+		dir := w.walDir(endpoint)
+		inUse[dir] = struct{}{}
+
+		if q, ok := w.queues[dir]; ok {
+			if q.opts.MaxSize == opts.MaxSize && q.opts.MaxSegmentSize == opts.MaxSegmentSize {
+				res = append(res, q)
+				continue
+			}
+			// The queue must be closed before opening the same directory again.
+			w.close(q)
+		}
+
+		queueOpts := opts
+		queueOpts.OnDrop = func(records int, bytes int64) {
+			w.metrics.walDroppedRequests.WithLabelValues(endpoint.URL).Add(float64(records))
+			w.metrics.walDroppedBytes.WithLabelValues(endpoint.URL).Add(float64(bytes))
+		}
+		queue, err := wal.Open(dir, queueOpts, log.With(w.logger, "endpoint", endpoint.URL))
+		if err != nil {
+			return nil, fmt.Errorf("opening WAL of endpoint %s: %w", endpoint.URL, err)
+		}
+
+		q := &walQueue{Queue: queue, dir: dir, url: endpoint.URL, opts: opts}
+		w.queues[dir] = q
+		w.updateMetrics(q)
+		res = append(res, q)
+	}
+
+	w.removeStale(inUse)
+	return res, nil
+}
+
+// walDir returns the directory of the WAL of endpoint. It's derived from the
+// URL, name and tenant of the endpoint rather than its position, so that
+// stored profiles are never sent to another endpoint when the endpoints are
+// reordered or changed.
+func (w *walQueues) walDir(endpoint *EndpointOptions) string {
+	var tenant string
+	for k, v := range endpoint.Headers {
+		if http.CanonicalHeaderKey(k) == "X-Scope-Orgid" {
+			tenant = v
+		}
+	}
+
+	h := sha256.New()
+	for _, s := range []string{endpoint.URL, endpoint.Name, tenant} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(w.dataPath, "wal", "endpoint-"+hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// removeStale drops the WAL directories which don't belong to any of the
+// configured endpoints, including the ones left over from previous runs.
+func (w *walQueues) removeStale(inUse map[string]struct{}) {
+	root := filepath.Join(w.dataPath, "wal")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			level.Warn(w.logger).Log("msg", "failed to list WAL directories", "err", err)
+		}
+		return
+	}
+
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		if _, ok := inUse[dir]; ok || !e.IsDir() {
+			continue
+		}
+		if q, ok := w.queues[dir]; ok {
+			w.metrics.walDroppedRequests.WithLabelValues(q.url).Add(float64(q.Len()))
+			w.metrics.walDroppedBytes.WithLabelValues(q.url).Add(float64(q.Size()))
+			w.close(q)
+		}
+		level.Info(w.logger).Log("msg", "dropping WAL of an endpoint which is no longer configured", "dir", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			level.Warn(w.logger).Log("msg", "failed to remove WAL directory", "dir", dir, "err", err)
+		}
+	}
+}
+
+// retain closes the WAL which aren't part of inUse.
+func (w *walQueues) retain(inUse []*walQueue) {
+	keep := make(map[*walQueue]struct{}, len(inUse))
+	for _, q := range inUse {
+		keep[q] = struct{}{}
+	}
+	for _, q := range w.queues {
+		if _, ok := keep[q]; !ok {
+			w.close(q)
+		}
+	}
+}
+
+func (w *walQueues) close(q *walQueue) {
+	if err := q.Close(); err != nil {
+		level.Warn(w.logger).Log("msg", "failed to close WAL", "endpoint", q.url, "err", err)
+	}
+	w.metrics.walPendingRequests.DeleteLabelValues(q.url)
+	w.metrics.walPendingBytes.DeleteLabelValues(q.url)
+	delete(w.queues, q.dir)
+}
+
+func (w *walQueues) updateMetrics(q *walQueue) {
+	w.metrics.walPendingRequests.WithLabelValues(q.url).Set(float64(q.Len()))
+	w.metrics.walPendingBytes.WithLabelValues(q.url).Set(float64(q.Size()))
+}
+
+// Kinds of the records stored in the WAL.
+const (
+	walRecordPush byte = iota + 1
+	walRecordIngest
+)
+
+// walRecord is a request stored in the WAL. Exactly one of push and ingest
+// is set.
+type walRecord struct {
+	push *pushv1.PushRequest
+
+	ingest      *pyroscope.IncomingProfile
+	ingestQuery url.Values
+}
+
+func (r *walRecord) marshal() ([]byte, error) {
+	if r.push != nil {
+		data, err := r.push.MarshalVT()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{walRecordPush}, data...), nil
+	}
+
+	buf := []byte{walRecordIngest}
+	buf = appendBytes(buf, []byte(r.ingest.URL.Path))
+	buf = appendBytes(buf, []byte(r.ingestQuery.Encode()))
+	buf = binary.AppendUvarint(buf, uint64(len(r.ingest.ContentType)))
+	for _, ct := range r.ingest.ContentType {
+		buf = appendBytes(buf, []byte(ct))
+	}
+	buf = appendBytes(buf, r.ingest.RawBody)
+	return buf, nil
+}
+
+func unmarshalWalRecord(data []byte) (*walRecord, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty WAL record")
+	}
+
+	switch data[0] {
+	case walRecordPush:
+		req := &pushv1.PushRequest{}
+		if err := req.UnmarshalVT(data[1:]); err != nil {
+			return nil, err
+		}
+		return &walRecord{push: req}, nil
+
+	case walRecordIngest:
+		var (
+			d        = recordDecoder{data: data[1:]}
+			path     = d.bytes()
+			rawQuery = d.bytes()
+			n        = d.uvarint()
+			profile  = &pyroscope.IncomingProfile{}
+		)
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			profile.ContentType = append(profile.ContentType, string(d.bytes()))
+		}
+		profile.RawBody = d.bytes()
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		query, err := url.ParseQuery(string(rawQuery))
+		if err != nil {
+			return nil, err
+		}
+		profile.URL = &url.URL{Path: string(path)}
+		return &walRecord{ingest: profile, ingestQuery: query}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown WAL record kind %d", data[0])
+	}
+}
+
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// recordDecoder reads the fields written by walRecord.marshal. The first
+// error is kept and returned by err.
+type recordDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *recordDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errors.New("invalid WAL record")
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *recordDecoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.data)) < n {
+		d.err = errors.New("invalid WAL record")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// enqueue appends a request to the WAL of every endpoint.
+func (f *fanOutClient) enqueue(rec *walRecord) error {
+	data, err := rec.marshal()
+	if err != nil {
+		return fmt.Errorf("encoding WAL record: %w", err)
+	}
+
+	var errs []error
+	for _, q := range f.queues {
+		if err := q.Append(data); err != nil {
+			f.metrics.walDroppedRequests.WithLabelValues(q.url).Inc()
+			f.metrics.walDroppedBytes.WithLabelValues(q.url).Add(float64(len(data)))
+			errs = append(errs, fmt.Errorf("failed to append to WAL of endpoint %s: %w", q.url, err))
+			continue
+		}
+		f.walQueues.updateMetrics(q)
+	}
+	return errors.Join(errs...)
+}
+
+// replay sends the requests stored in the WAL of an endpoint, in the order
+// they were received. Requests which fail with a retryable error are retried
+// until they succeed or ctx is canceled, in which case they are sent again
+// the next time replay is called.
+func (f *fanOutClient) replay(ctx context.Context, i int) {
+	q := f.queues[i]
+	for {
+		rec, err := q.Next(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, wal.ErrClosed) {
+				level.Error(f.logger).Log("msg", "failed to read from WAL", "endpoint", q.url, "err", err)
+			}
+			return
+		}
+
+		r, err := unmarshalWalRecord(rec.Data)
+		if err != nil {
+			level.Error(f.logger).Log("msg", "dropping invalid WAL record", "endpoint", q.url, "err", err)
+			f.metrics.walDroppedRequests.WithLabelValues(q.url).Inc()
+			f.metrics.walDroppedBytes.WithLabelValues(q.url).Add(float64(len(rec.Data)))
+		} else {
+			// Retry until the request is sent or ctx is canceled.
+			var size, profiles int64
+			if r.push != nil {
+				req := connect.NewRequest(r.push)
+				size, profiles = requestSize(req)
+				err = f.pushToEndpoint(ctx, i, req, 0, f.logger)
+			} else {
+				size, profiles = int64(len(r.ingest.RawBody)), 1
+				err = f.ingestToEndpoint(ctx, i, r.ingest, r.ingestQuery, 0, f.logger)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				level.Warn(f.logger).Log("msg", "dropping request from WAL", "endpoint", q.url, "err", err)
+				f.metrics.droppedBytes.WithLabelValues(q.url).Add(float64(size))
+				f.metrics.droppedProfiles.WithLabelValues(q.url).Add(float64(profiles))
+			}
+		}
+
+		if err := q.Ack(rec); err != nil && !errors.Is(err, wal.ErrClosed) {
+			level.Error(f.logger).Log("msg", "failed to acknowledge WAL record", "endpoint", q.url, "err", err)
+		}
+		f.walQueues.updateMetrics(q)
+	}
+}
@@ -0,0 +1,561 @@
+// Package wal implements a disk-backed FIFO queue used by pyroscope.write to
+// spool profiles while an endpoint is unavailable.
+//
+// Records are appended to numbered segment files inside a directory. A
+// position file keeps track of the first record which hasn't been
+// acknowledged yet, so that records are replayed in order after a restart.
+package wal
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+const (
+	segmentExt   = ".seg"
+	positionFile = "position"
+
+	// headerSize is the size of the header written before each record. It
+	// holds the length of the record followed by its CRC32 checksum.
+	headerSize = 8
+)
+
+var (
+	// ErrClosed is returned when using a closed queue.
+	ErrClosed = errors.New("queue closed")
+	// ErrTooLarge is returned when appending a record which doesn't fit in
+	// the queue.
+	ErrTooLarge = errors.New("record larger than the maximum segment size")
+
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Options configures a Queue.
+type Options struct {
+	// MaxSize is the maximum number of bytes used on disk by the queue. The
+	// oldest records are dropped when appending a record would exceed it.
+	MaxSize int64
+	// MaxSegmentSize is the size after which a new segment file is started.
+	// It must be lower than MaxSize.
+	MaxSegmentSize int64
+	// OnDrop is called with the number of records and bytes dropped, either
+	// because the queue is full or because a segment is corrupted.
+	OnDrop func(records int, bytes int64)
+}
+
+// Record is a record read from the queue.
+type Record struct {
+	Data []byte
+
+	segment int
+	next    int64
+}
+
+// Queue is a disk-backed FIFO queue. It is safe for concurrent use, but
+// records must be consumed by a single reader.
+type Queue struct {
+	dir    string
+	opts   Options
+	logger log.Logger
+
+	mut      sync.Mutex
+	notify   chan struct{}
+	closed   bool
+	segments []*segment // Ordered from the oldest to the newest.
+	writer   *os.File   // Writes to the last segment.
+	reader   *os.File   // Reads from the first segment.
+
+	// readOffset and readRecords track the records already acknowledged in
+	// the first segment.
+	readOffset  int64
+	readRecords int
+}
+
+type segment struct {
+	index   int
+	size    int64
+	records int
+}
+
+// Open opens the queue stored in dir, creating it if it doesn't exist.
+// Records which weren't acknowledged before the queue was last closed are
+// read again.
+func Open(dir string, opts Options, logger log.Logger) (*Queue, error) {
+	if opts.MaxSegmentSize <= 0 || opts.MaxSegmentSize >= opts.MaxSize {
+		return nil, fmt.Errorf("max segment size must be greater than 0 and lower than max size")
+	}
+	if opts.OnDrop == nil {
+		opts.OnDrop = func(int, int64) {}
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating queue directory: %w", err)
+	}
+
+	q := &Queue{
+		dir:    dir,
+		opts:   opts,
+		logger: logger,
+		notify: make(chan struct{}, 1),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	if err := q.startSegment(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load reads the existing segments and the position of the first record
+// which wasn't acknowledged.
+func (q *Queue) load() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("reading queue directory: %w", err)
+	}
+
+	var indexes []int
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		index, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+
+	posSegment, posOffset, err := q.readPosition()
+	if err != nil {
+		level.Warn(q.logger).Log("msg", "failed to read queue position, replaying all segments", "err", err)
+		posSegment, posOffset = 0, 0
+	}
+
+	for _, index := range indexes {
+		if index < posSegment {
+			// The segment was fully acknowledged but not removed yet.
+			if err := os.Remove(q.segmentPath(index)); err != nil {
+				return fmt.Errorf("removing acknowledged segment: %w", err)
+			}
+			continue
+		}
+
+		seg, err := q.scanSegment(index)
+		if err != nil {
+			return err
+		}
+		if len(q.segments) == 0 && index == posSegment {
+			q.readOffset, q.readRecords = q.recordsBefore(seg, posOffset)
+		}
+		q.segments = append(q.segments, seg)
+	}
+
+	if len(q.segments) > 0 {
+		if err := q.openReader(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanSegment reads a segment to count its records. Corrupted records at the
+// end of a segment, for example because of a partial write, are truncated.
+func (q *Queue) scanSegment(index int) (*segment, error) {
+	path := q.segmentPath(index)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening segment: %w", err)
+	}
+	defer f.Close()
+
+	seg := &segment{index: index}
+	for {
+		n, err := readRecordAt(f, seg.size, nil)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			level.Warn(q.logger).Log("msg", "truncating corrupted queue segment", "segment", path, "offset", seg.size, "err", err)
+			if err := f.Truncate(seg.size); err != nil {
+				return nil, fmt.Errorf("truncating segment: %w", err)
+			}
+			break
+		}
+		seg.size += n
+		seg.records++
+	}
+	return seg, nil
+}
+
+// recordsBefore returns the offset of the last record boundary before offset
+// and the number of records before it.
+func (q *Queue) recordsBefore(seg *segment, offset int64) (int64, int) {
+	if offset <= 0 {
+		return 0, 0
+	}
+
+	f, err := os.Open(q.segmentPath(seg.index))
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+
+	var (
+		pos     int64
+		records int
+	)
+	for pos < offset && records < seg.records {
+		n, err := readRecordAt(f, pos, nil)
+		if err != nil || pos+n > offset {
+			break
+		}
+		pos += n
+		records++
+	}
+	return pos, records
+}
+
+// Append adds a record at the end of the queue. The oldest records are
+// dropped if there isn't enough space left.
+func (q *Queue) Append(data []byte) error {
+	size := int64(len(data)) + headerSize
+	if size > q.opts.MaxSegmentSize {
+		return ErrTooLarge
+	}
+
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	last := q.segments[len(q.segments)-1]
+	if last.size > 0 && last.size+size > q.opts.MaxSegmentSize {
+		if err := q.startSegment(); err != nil {
+			return err
+		}
+		last = q.segments[len(q.segments)-1]
+	}
+
+	for q.diskSize()+size > q.opts.MaxSize && len(q.segments) > 1 {
+		if err := q.dropOldestSegment(); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, size)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(data, castagnoli))
+	copy(buf[headerSize:], data)
+
+	if _, err := q.writer.Write(buf); err != nil {
+		// Discard the partial write so that the next records can be read.
+		_ = q.writer.Truncate(last.size)
+		_, _ = q.writer.Seek(last.size, io.SeekStart)
+		return fmt.Errorf("writing record: %w", err)
+	}
+	last.size += size
+	last.records++
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Next returns the oldest record which wasn't acknowledged. It blocks until a
+// record is available, the queue is closed or ctx is canceled. Next returns
+// the same record until it's acknowledged with Ack.
+func (q *Queue) Next(ctx context.Context) (*Record, error) {
+	for {
+		q.mut.Lock()
+		if q.closed {
+			q.mut.Unlock()
+			return nil, ErrClosed
+		}
+
+		if q.readRecords < q.segments[0].records {
+			rec, err := q.readNext()
+			q.mut.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			if rec != nil {
+				return rec, nil
+			}
+			continue
+		}
+
+		// The first segment was fully read and isn't written to anymore.
+		if len(q.segments) > 1 {
+			err := q.removeFirstSegment()
+			q.mut.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		q.mut.Unlock()
+
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// readNext reads the record at the current read position. A nil record is
+// returned if the rest of the segment is corrupted and was dropped.
+func (q *Queue) readNext() (*Record, error) {
+	seg := q.segments[0]
+
+	var data []byte
+	n, err := readRecordAt(q.reader, q.readOffset, &data)
+	if err != nil {
+		level.Warn(q.logger).Log("msg", "dropping corrupted records from queue segment", "segment", q.segmentPath(seg.index), "offset", q.readOffset, "err", err)
+		q.opts.OnDrop(seg.records-q.readRecords, seg.size-q.readOffset)
+		// Skip the rest of the segment.
+		seg.records, seg.size = q.readRecords, q.readOffset
+		if len(q.segments) == 1 {
+			// New records are written after the corrupted ones so a new
+			// segment is started to keep them readable.
+			if err := q.startSegment(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	return &Record{
+		Data:    data,
+		segment: seg.index,
+		next:    q.readOffset + n,
+	}, nil
+}
+
+// Ack acknowledges a record returned by Next. The record won't be returned
+// again, including after a restart.
+func (q *Queue) Ack(rec *Record) error {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	// The record may have been dropped in the meantime if the queue was full.
+	if q.segments[0].index != rec.segment || rec.next <= q.readOffset {
+		return nil
+	}
+
+	q.readOffset = rec.next
+	q.readRecords++
+
+	// Remove the first segment as soon as it was fully read, unless it's
+	// still written to.
+	if q.readRecords == q.segments[0].records && len(q.segments) > 1 {
+		return q.removeFirstSegment()
+	}
+	return q.writePosition()
+}
+
+// Len returns the number of records which weren't acknowledged.
+func (q *Queue) Len() int {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	records := -q.readRecords
+	for _, seg := range q.segments {
+		records += seg.records
+	}
+	return records
+}
+
+// Size returns the number of bytes of the records which weren't acknowledged.
+func (q *Queue) Size() int64 {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	return q.diskSize() - q.readOffset
+}
+
+// Close closes the queue. Records which weren't acknowledged are kept on disk.
+func (q *Queue) Close() error {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	var errs []error
+	if q.writer != nil {
+		errs = append(errs, q.writer.Close())
+	}
+	if q.reader != nil {
+		errs = append(errs, q.reader.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (q *Queue) diskSize() int64 {
+	var size int64
+	for _, seg := range q.segments {
+		size += seg.size
+	}
+	return size
+}
+
+// startSegment creates a new segment and uses it for the next writes.
+func (q *Queue) startSegment() error {
+	index := 0
+	if len(q.segments) > 0 {
+		index = q.segments[len(q.segments)-1].index + 1
+	}
+
+	f, err := os.OpenFile(q.segmentPath(index), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+	if q.writer != nil {
+		if err := q.writer.Close(); err != nil {
+			level.Warn(q.logger).Log("msg", "failed to close queue segment", "err", err)
+		}
+	}
+	q.writer = f
+	q.segments = append(q.segments, &segment{index: index})
+
+	if len(q.segments) == 1 {
+		return q.openReader()
+	}
+	return nil
+}
+
+// dropOldestSegment removes the first segment, including the records which
+// weren't acknowledged yet.
+func (q *Queue) dropOldestSegment() error {
+	seg := q.segments[0]
+	records, size := seg.records-q.readRecords, seg.size-q.readOffset
+	if records > 0 {
+		level.Warn(q.logger).Log("msg", "queue is full, dropping oldest records", "records", records, "bytes", size)
+		q.opts.OnDrop(records, size)
+	}
+	return q.removeFirstSegment()
+}
+
+// removeFirstSegment deletes the first segment and moves the read position
+// to the start of the next one.
+func (q *Queue) removeFirstSegment() error {
+	seg := q.segments[0]
+	q.segments = q.segments[1:]
+	q.readOffset, q.readRecords = 0, 0
+
+	if err := q.reader.Close(); err != nil {
+		level.Warn(q.logger).Log("msg", "failed to close queue segment", "err", err)
+	}
+	if err := q.openReader(); err != nil {
+		return err
+	}
+	if err := q.writePosition(); err != nil {
+		return err
+	}
+	if err := os.Remove(q.segmentPath(seg.index)); err != nil {
+		return fmt.Errorf("removing segment: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) openReader() error {
+	f, err := os.Open(q.segmentPath(q.segments[0].index))
+	if err != nil {
+		return fmt.Errorf("opening segment: %w", err)
+	}
+	q.reader = f
+	return nil
+}
+
+func (q *Queue) segmentPath(index int) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%08d%s", index, segmentExt))
+}
+
+func (q *Queue) readPosition() (int, int64, error) {
+	content, err := os.ReadFile(filepath.Join(q.dir, positionFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	} else if err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		index  int
+		offset int64
+	)
+	if _, err := fmt.Sscanf(string(content), "%d %d", &index, &offset); err != nil {
+		return 0, 0, fmt.Errorf("parsing position file: %w", err)
+	}
+	return index, offset, nil
+}
+
+// writePosition persists the read position. The file is replaced atomically
+// so that a crash never leaves a partial position behind.
+func (q *Queue) writePosition() error {
+	path := filepath.Join(q.dir, positionFile)
+	content := fmt.Sprintf("%d %d\n", q.segments[0].index, q.readOffset)
+	if err := os.WriteFile(path+".tmp", []byte(content), 0o640); err != nil {
+		return fmt.Errorf("writing queue position: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("writing queue position: %w", err)
+	}
+	return nil
+}
+
+// readRecordAt reads the record starting at offset and returns its size,
+// including the header. The content of the record is stored in data if it
+// isn't nil. io.EOF is returned if there is no record at offset.
+func readRecordAt(r io.ReaderAt, offset int64, data *[]byte) (int64, error) {
+	var header [headerSize]byte
+	n, err := r.ReadAt(header[:], offset)
+	if n == 0 && errors.Is(err, io.EOF) {
+		return 0, io.EOF
+	} else if n < headerSize {
+		return 0, fmt.Errorf("reading record header: %w", io.ErrUnexpectedEOF)
+	}
+
+	var (
+		length   = binary.BigEndian.Uint32(header[0:4])
+		checksum = binary.BigEndian.Uint32(header[4:8])
+		buf      = make([]byte, length)
+	)
+	if _, err := r.ReadAt(buf, offset+headerSize); err != nil {
+		return 0, fmt.Errorf("reading record: %w", err)
+	}
+	if crc32.Checksum(buf, castagnoli) != checksum {
+		return 0, errors.New("record checksum mismatch")
+	}
+
+	if data != nil {
+		*data = buf
+	}
+	return int64(length) + headerSize, nil
+}
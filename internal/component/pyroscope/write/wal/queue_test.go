@@ -0,0 +1,217 @@
+package wal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func openQueue(t *testing.T, dir string, opts Options) *Queue {
+	t.Helper()
+
+	q, err := Open(dir, opts, log.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func readRecord(t *testing.T, q *Queue) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	rec, err := q.Next(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Ack(rec))
+	return string(rec.Data)
+}
+
+func TestQueue(t *testing.T) {
+	q := openQueue(t, t.TempDir(), Options{MaxSize: 1024, MaxSegmentSize: 64})
+
+	for i := range 10 {
+		require.NoError(t, q.Append(fmt.Appendf(nil, "record-%d", i)))
+	}
+	require.Equal(t, 10, q.Len())
+	require.Equal(t, int64(10*(8+headerSize)), q.Size())
+
+	for i := range 10 {
+		require.Equal(t, fmt.Sprintf("record-%d", i), readRecord(t, q))
+	}
+	require.Equal(t, 0, q.Len())
+	require.Equal(t, int64(0), q.Size())
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	_, err := q.Next(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestQueue_NextWithoutAck(t *testing.T) {
+	q := openQueue(t, t.TempDir(), Options{MaxSize: 1024, MaxSegmentSize: 64})
+	require.NoError(t, q.Append([]byte("first")))
+	require.NoError(t, q.Append([]byte("second")))
+
+	rec, err := q.Next(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "first", string(rec.Data))
+
+	rec, err = q.Next(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "first", string(rec.Data))
+
+	require.NoError(t, q.Ack(rec))
+	require.Equal(t, "second", readRecord(t, q))
+}
+
+func TestQueue_WaitForAppend(t *testing.T) {
+	q := openQueue(t, t.TempDir(), Options{MaxSize: 1024, MaxSegmentSize: 64})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = q.Append([]byte("late"))
+	}()
+	require.Equal(t, "late", readRecord(t, q))
+}
+
+func TestQueue_Replay(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{MaxSize: 1024, MaxSegmentSize: 64}
+
+	q, err := Open(dir, opts, log.NewNopLogger())
+	require.NoError(t, err)
+	for i := range 10 {
+		require.NoError(t, q.Append(fmt.Appendf(nil, "record-%d", i)))
+	}
+	for i := range 4 {
+		require.Equal(t, fmt.Sprintf("record-%d", i), readRecord(t, q))
+	}
+	require.NoError(t, q.Close())
+
+	q = openQueue(t, dir, opts)
+	require.Equal(t, 6, q.Len())
+	for i := 4; i < 10; i++ {
+		require.Equal(t, fmt.Sprintf("record-%d", i), readRecord(t, q))
+	}
+
+	// Acknowledged segments are removed.
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+}
+
+func TestQueue_MaxSize(t *testing.T) {
+	var (
+		droppedRecords int
+		droppedBytes   int64
+	)
+	q := openQueue(t, t.TempDir(), Options{
+		MaxSize:        64,
+		MaxSegmentSize: 32,
+		OnDrop: func(records int, bytes int64) {
+			droppedRecords += records
+			droppedBytes += bytes
+		},
+	})
+
+	// Each record uses 16 bytes so two records fit in a segment.
+	for i := range 6 {
+		require.NoError(t, q.Append(fmt.Appendf(nil, "record-%d", i)))
+	}
+	require.Equal(t, 2, droppedRecords)
+	require.Equal(t, int64(32), droppedBytes)
+	require.Equal(t, 4, q.Len())
+
+	for i := 2; i < 6; i++ {
+		require.Equal(t, fmt.Sprintf("record-%d", i), readRecord(t, q))
+	}
+
+	require.ErrorIs(t, q.Append(make([]byte, 32)), ErrTooLarge)
+}
+
+func TestQueue_DropWhileReading(t *testing.T) {
+	q := openQueue(t, t.TempDir(), Options{MaxSize: 64, MaxSegmentSize: 32})
+	for i := range 4 {
+		require.NoError(t, q.Append(fmt.Appendf(nil, "record-%d", i)))
+	}
+
+	rec, err := q.Next(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "record-0", string(rec.Data))
+
+	// The segment holding the record being sent is dropped.
+	require.NoError(t, q.Append([]byte("record-4")))
+	require.NoError(t, q.Ack(rec))
+
+	for i := 2; i < 5; i++ {
+		require.Equal(t, fmt.Sprintf("record-%d", i), readRecord(t, q))
+	}
+}
+
+func TestQueue_TruncatedSegment(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{MaxSize: 1024, MaxSegmentSize: 256}
+
+	q, err := Open(dir, opts, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, q.Append([]byte("complete")))
+	require.NoError(t, q.Append([]byte("partial")))
+	require.NoError(t, q.Close())
+
+	// Simulate a partial write of the last record.
+	path := q.segmentPath(0)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3))
+
+	q = openQueue(t, dir, opts)
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, "complete", readRecord(t, q))
+
+	require.NoError(t, q.Append([]byte("next")))
+	require.Equal(t, "next", readRecord(t, q))
+}
+
+func TestQueue_CorruptedRecord(t *testing.T) {
+	var droppedRecords int
+	opts := Options{
+		MaxSize:        1024,
+		MaxSegmentSize: 256,
+		OnDrop:         func(records int, _ int64) { droppedRecords += records },
+	}
+	q := openQueue(t, t.TempDir(), opts)
+	require.NoError(t, q.Append([]byte("first")))
+	require.NoError(t, q.Append([]byte("second")))
+	require.NoError(t, q.Append([]byte("third")))
+
+	// Corrupt the content of the second record.
+	f, err := os.OpenFile(q.segmentPath(0), os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("X"), int64(len("first")+2*headerSize))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.Equal(t, "first", readRecord(t, q))
+
+	// The records following the corrupted one are dropped.
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	_, err = q.Next(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 2, droppedRecords)
+
+	require.NoError(t, q.Append([]byte("fourth")))
+	require.Equal(t, "fourth", readRecord(t, q))
+}
+
+func TestQueue_InvalidOptions(t *testing.T) {
+	_, err := Open(t.TempDir(), Options{MaxSize: 64, MaxSegmentSize: 64}, log.NewNopLogger())
+	require.Error(t, err)
+}
@@ -45,6 +45,7 @@ var (
 				JaegerPropagator:       true,
 				TraceContextPropagator: true,
 			},
+			WAL: DefaultWalArguments,
 		}
 	}
 )
@@ -55,6 +56,7 @@ type Arguments struct {
 	ExternalLabels map[string]string  `alloy:"external_labels,attr,optional"`
 	Endpoints      []*EndpointOptions `alloy:"endpoint,block,optional"`
 	Tracing        TracingOptions     `alloy:"tracing,block,optional"`
+	WAL            WalArguments       `alloy:"wal,block,optional"`
 }
 
 type TracingOptions struct {
@@ -117,6 +119,9 @@ type Component struct {
 	userAgent     string
 	uid           string
 	dataPath      string
+	walQueues     *walQueues
+
+	debugDataPublisher pyroscope.DebugDataPublisher
 
 	mu             sync.Mutex
 	receiver       *fanOutClient
@@ -137,12 +142,15 @@ func New(
 	onStateChange func(Exports),
 	userAgent, uid string,
 	dataPath string,
+	debugDataPublisher pyroscope.DebugDataPublisher,
 	c Arguments,
 ) (*Component, error) {
 
 	m := newMetrics(reg)
-	receiver, err := newFanOut(logger, tracer, c, m, userAgent, uid, dataPath)
+	walQueues := newWalQueues(logger, m, dataPath)
+	receiver, err := newFanOut(logger, tracer, c, m, userAgent, uid, dataPath, walQueues, debugDataPublisher)
 	if err != nil {
+		walQueues.retain(nil)
 		return nil, err
 	}
 	// Immediately export the receiver
@@ -157,7 +165,10 @@ func New(
 		userAgent:     userAgent,
 		uid:           uid,
 		dataPath:      dataPath,
+		walQueues:     walQueues,
 		receiver:      receiver,
+
+		debugDataPublisher: debugDataPublisher,
 	}, nil
 }
 
@@ -178,6 +189,7 @@ func (c *Component) Run(ctx context.Context) error {
 	c.receiver.Wait()
 	c.runCtx = nil
 	c.receiverCancel = nil
+	c.walQueues.retain(nil)
 	c.mu.Unlock()
 
 	return ctx.Err()
@@ -185,19 +197,20 @@ func (c *Component) Run(ctx context.Context) error {
 
 // Update implements Component.
 func (c *Component) Update(newConfig Arguments) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.cfg = newConfig
-	receiver, err := newFanOut(c.logger, c.tracer, newConfig, c.metrics, c.userAgent, c.uid, c.dataPath)
+	receiver, err := newFanOut(c.logger, c.tracer, newConfig, c.metrics, c.userAgent, c.uid, c.dataPath, c.walQueues, c.debugDataPublisher)
 	if err != nil {
 		return err
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if c.receiverCancel != nil {
 		c.receiverCancel()
 		c.receiver.Wait()
 	}
+	c.walQueues.retain(receiver.queues)
 
 	c.receiver = receiver
 	c.onStateChange(Exports{Receiver: receiver})
@@ -223,7 +236,14 @@ type fanOutClient struct {
 	tracer        trace.Tracer
 	logger        log.Logger
 
+	// queues holds the WAL of each endpoint. It is nil if the WAL is disabled.
+	queues    []*walQueue
+	walQueues *walQueues
+
+	debugDataPublisher pyroscope.DebugDataPublisher
+
 	uploaderWg sync.WaitGroup
+	replayWg   sync.WaitGroup
 }
 
 func (f *fanOutClient) Client() debuginfogrpc.DebuginfoServiceClient {
@@ -243,6 +263,13 @@ func (f *fanOutClient) Upload(j debuginfo.UploadJob) {
 }
 
 func (f *fanOutClient) Start(ctx context.Context) {
+	for i := range f.queues {
+		f.replayWg.Add(1)
+		go func() {
+			defer f.replayWg.Done()
+			f.replay(ctx, i)
+		}()
+	}
 	for _, u := range f.debugInfos {
 		f.uploaderWg.Add(1)
 		go func(c *debuginfo.Client) {
@@ -256,10 +283,11 @@ func (f *fanOutClient) Start(ctx context.Context) {
 
 func (f *fanOutClient) Wait() {
 	f.uploaderWg.Wait()
+	f.replayWg.Wait()
 }
 
 // newFanOut creates a new fan out client that will fan out to all endpoints.
-func newFanOut(logger log.Logger, tracer trace.Tracer, config Arguments, metrics *metrics, userAgent string, uid string, dataPath string) (*fanOutClient, error) {
+func newFanOut(logger log.Logger, tracer trace.Tracer, config Arguments, metrics *metrics, userAgent string, uid string, dataPath string, walQueues *walQueues, debugDataPublisher pyroscope.DebugDataPublisher) (*fanOutClient, error) {
 	pushClients := make([]pushv1connect.PusherServiceClient, 0, len(config.Endpoints))
 	debugInfos := make([]*debuginfo.Client, 0, len(config.Endpoints))
 	ingestClients := make(map[*EndpointOptions]*http.Client)
@@ -292,6 +320,11 @@ func newFanOut(logger log.Logger, tracer trace.Tracer, config Arguments, metrics
 		debugInfos = append(debugInfos, debugInfo)
 	}
 
+	queues, err := walQueues.get(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &fanOutClient{
 		logger:        logger,
 		tracer:        tracer,
@@ -300,6 +333,10 @@ func newFanOut(logger log.Logger, tracer trace.Tracer, config Arguments, metrics
 		ingestClients: ingestClients,
 		config:        config,
 		metrics:       metrics,
+		queues:        queues,
+		walQueues:     walQueues,
+
+		debugDataPublisher: debugDataPublisher,
 	}, nil
 }
 
@@ -342,58 +379,21 @@ func (f *fanOutClient) Push(
 		)
 	}()
 
-	for i, client := range f.pushClients {
-		var (
-			client  = client
-			i       = i
-			backoff = backoff.New(ctx, backoff.Config{
-				MinBackoff: f.config.Endpoints[i].MinBackoff,
-				MaxBackoff: f.config.Endpoints[i].MaxBackoff,
-				MaxRetries: f.config.Endpoints[i].MaxBackoffRetries,
-			})
-			err error
-		)
+	if f.queues != nil {
+		if errs = f.enqueue(&walRecord{push: req.Msg}); errs != nil {
+			return nil, errs
+		}
+		return connect.NewResponse(&pushv1.PushResponse{}), nil
+	}
+
+	for i := range f.pushClients {
 		wg.Add(1)
 		go func() {
-			defer f.observeLatency(f.config.Endpoints[i].URL, "push_endpoint")()
 			defer wg.Done()
-			req := connect.NewRequest(req.Msg)
-			for k, v := range f.config.Endpoints[i].Headers {
-				req.Header().Set(k, v)
-			}
-			for {
-				err = func() error {
-					defer f.observeLatency(f.config.Endpoints[i].URL, "push_downstream")()
-					ctx, cancel := context.WithTimeout(ctx, f.config.Endpoints[i].RemoteTimeout)
-					defer cancel()
-
-					_, err := client.Push(ctx, req)
-					return err
-				}()
-				if err == nil {
-					f.metrics.sentBytes.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(reqSize))
-					f.metrics.sentProfiles.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(profileCount))
-					break
-				}
-				_ = level.Debug(l).Log("msg",
-					"failed to push to endpoint",
-					"endpoint", f.config.Endpoints[i].URL,
-					"retries", backoff.NumRetries(),
-					"err", err,
-				)
-				if !shouldRetry(err) {
-					break
-				}
-				backoff.Wait()
-				if !backoff.Ongoing() {
-					break
-				}
-				f.metrics.retries.WithLabelValues(f.config.Endpoints[i].URL).Inc()
-			}
+			err := f.pushToEndpoint(ctx, i, req, f.config.Endpoints[i].MaxBackoffRetries, l)
 			if err != nil {
 				f.metrics.droppedBytes.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(reqSize))
 				f.metrics.droppedProfiles.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(profileCount))
-				err = fmt.Errorf("failed to push to endpoint %s (%d retries): %w", f.config.Endpoints[i].URL, backoff.NumRetries(), err)
 				util.ErrorsJoinConcurrent(&errs, err, &errorMut)
 			}
 		}()
@@ -406,6 +406,58 @@ func (f *fanOutClient) Push(
 	return connect.NewResponse(&pushv1.PushResponse{}), nil
 }
 
+// pushToEndpoint sends a request to the i-th endpoint, retrying up to
+// maxRetries times. Zero means infinite retries.
+func (f *fanOutClient) pushToEndpoint(ctx context.Context, i int, req *connect.Request[pushv1.PushRequest], maxRetries int, l log.Logger) error {
+	var (
+		client                = f.pushClients[i]
+		endpoint              = f.config.Endpoints[i]
+		reqSize, profileCount = requestSize(req)
+		backoff               = backoff.New(ctx, backoff.Config{
+			MinBackoff: endpoint.MinBackoff,
+			MaxBackoff: endpoint.MaxBackoff,
+			MaxRetries: maxRetries,
+		})
+		err error
+	)
+	defer f.observeLatency(endpoint.URL, "push_endpoint")()
+
+	req = connect.NewRequest(req.Msg)
+	for k, v := range endpoint.Headers {
+		req.Header().Set(k, v)
+	}
+	for {
+		err = func() error {
+			defer f.observeLatency(endpoint.URL, "push_downstream")()
+			ctx, cancel := context.WithTimeout(ctx, endpoint.RemoteTimeout)
+			defer cancel()
+
+			_, err := client.Push(ctx, req)
+			return err
+		}()
+		if err == nil {
+			f.metrics.sentBytes.WithLabelValues(endpoint.URL).Add(float64(reqSize))
+			f.metrics.sentProfiles.WithLabelValues(endpoint.URL).Add(float64(profileCount))
+			return nil
+		}
+		_ = level.Debug(l).Log("msg",
+			"failed to push to endpoint",
+			"endpoint", endpoint.URL,
+			"retries", backoff.NumRetries(),
+			"err", err,
+		)
+		if !shouldRetry(err) {
+			break
+		}
+		backoff.Wait()
+		if !backoff.Ongoing() {
+			break
+		}
+		f.metrics.retries.WithLabelValues(endpoint.URL).Inc()
+	}
+	return fmt.Errorf("failed to push to endpoint %s (%d retries): %w", endpoint.URL, backoff.NumRetries(), err)
+}
+
 func shouldRetry(err error) bool {
 	if errors.Is(err, context.DeadlineExceeded) {
 		return true
@@ -484,12 +536,17 @@ func (f *fanOutClient) Append(ctx context.Context, lbs labels.Labels, samples []
 			RawProfile: sample.RawProfile,
 		})
 	}
-	// push to all clients
-	_, err := f.Push(ctx, connect.NewRequest(&pushv1.PushRequest{
+	f.debugDataPublisher.Publish(uint64(len(samples)), func() string {
+		finalLabels := lbsBuilder.Labels()
+		return fmt.Sprintf("labels: %s\n%s", finalLabels.String(), pyroscope.SamplesDebugString(finalLabels.Get(pyroscope.LabelName), samples))
+	})
+	req := &pushv1.PushRequest{
 		Series: []*pushv1.RawProfileSeries{
 			{Labels: protoLabels, Samples: protoSamples},
 		},
-	}))
+	}
+	// push to all clients
+	_, err := f.Push(ctx, connect.NewRequest(req))
 	return err
 }
 
@@ -568,100 +625,24 @@ func (f *fanOutClient) AppendIngest(ctx context.Context, profile *pyroscope.Inco
 	}
 	query.Set("name", ls.Normalized())
 
+	f.debugDataPublisher.Publish(1, func() string {
+		return fmt.Sprintf("labels: %s\n%s", ls.Normalized(), pyroscope.IncomingProfileDebugString(profile))
+	})
+
+	if f.queues != nil {
+		errs = f.enqueue(&walRecord{ingest: profile, ingestQuery: query})
+		return errs
+	}
+
 	// Send to each endpoint concurrently
-	for endpointIdx, endpoint := range f.config.Endpoints {
-		var (
-			endpoint = endpoint
-			i        = endpointIdx
-			backoff  = backoff.New(ctx, backoff.Config{
-				MinBackoff: f.config.Endpoints[i].MinBackoff,
-				MaxBackoff: f.config.Endpoints[i].MaxBackoff,
-				MaxRetries: f.config.Endpoints[i].MaxBackoffRetries,
-			})
-			err error
-		)
+	for i := range f.config.Endpoints {
 		wg.Add(1)
 		go func() {
-			defer f.observeLatency(endpoint.URL, "ingest_endpoint")()
 			defer wg.Done()
-			for {
-				err = func() error {
-					defer f.observeLatency(endpoint.URL, "ingest_downstream")()
-					u, err := url.Parse(endpoint.URL)
-					if err != nil {
-						return fmt.Errorf("parse URL: %w", err)
-					}
-
-					u.Path = path.Join(u.Path, profile.URL.Path)
-
-					// attach labels
-					u.RawQuery = query.Encode()
-
-					ctx, cancel := context.WithTimeout(ctx, f.config.Endpoints[i].RemoteTimeout)
-					defer cancel()
-
-					req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(profile.RawBody))
-					if err != nil {
-						return fmt.Errorf("create request: %w", err)
-					}
-
-					// set headers from endpoint
-					for k, v := range endpoint.Headers {
-						req.Header.Set(k, v)
-					}
-
-					// now set profile content type, overwrite what existed
-					for idx := range profile.ContentType {
-						if idx == 0 {
-							req.Header.Set(pyroscope.HeaderContentType, profile.ContentType[idx])
-							continue
-						}
-						req.Header.Add(pyroscope.HeaderContentType, profile.ContentType[idx])
-					}
-
-					resp, err := f.ingestClients[endpoint].Do(req)
-					if err != nil {
-						return fmt.Errorf("do request: %w", err)
-					}
-					defer resp.Body.Close()
-
-					if resp.StatusCode != http.StatusOK {
-						wErr := &PyroscopeWriteError{StatusCode: resp.StatusCode}
-						wErr.readBody(resp)
-						return fmt.Errorf("remote error: %w", wErr)
-					}
-
-					// Ensure full body is read to keep http connection Keep-Alive
-					_, err = io.Copy(io.Discard, resp.Body)
-					if err != nil {
-						return fmt.Errorf("reading response body: %w", err)
-					}
-
-					return nil
-				}()
-				if err == nil {
-					f.metrics.sentBytes.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(reqSize))
-					f.metrics.sentProfiles.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(profileCount))
-					break
-				}
-				_ = level.Debug(l).Log(
-					"msg", "failed to ingest to endpoint",
-					"endpoint", f.config.Endpoints[i].URL,
-					"retries", backoff.NumRetries(),
-					"err", err)
-				if !shouldRetry(err) {
-					break
-				}
-				backoff.Wait()
-				if !backoff.Ongoing() {
-					break
-				}
-				f.metrics.retries.WithLabelValues(f.config.Endpoints[i].URL).Inc()
-			}
+			err := f.ingestToEndpoint(ctx, i, profile, query, f.config.Endpoints[i].MaxBackoffRetries, l)
 			if err != nil {
 				f.metrics.droppedBytes.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(reqSize))
 				f.metrics.droppedProfiles.WithLabelValues(f.config.Endpoints[i].URL).Add(float64(profileCount))
-				err = fmt.Errorf("failed to ingest to endpoint %s (%d retries): %w", f.config.Endpoints[i].URL, backoff.NumRetries(), err)
 				util.ErrorsJoinConcurrent(&errs, err, &errorMut)
 			}
 		}()
@@ -672,6 +653,98 @@ func (f *fanOutClient) AppendIngest(ctx context.Context, profile *pyroscope.Inco
 	return errs
 }
 
+// ingestToEndpoint sends a profile to the ingest API of the i-th endpoint,
+// retrying up to maxRetries times. Zero means infinite retries.
+func (f *fanOutClient) ingestToEndpoint(ctx context.Context, i int, profile *pyroscope.IncomingProfile, query url.Values, maxRetries int, l log.Logger) error {
+	var (
+		endpoint              = f.config.Endpoints[i]
+		reqSize, profileCount = int64(len(profile.RawBody)), int64(1)
+		backoff               = backoff.New(ctx, backoff.Config{
+			MinBackoff: endpoint.MinBackoff,
+			MaxBackoff: endpoint.MaxBackoff,
+			MaxRetries: maxRetries,
+		})
+		err error
+	)
+	defer f.observeLatency(endpoint.URL, "ingest_endpoint")()
+
+	for {
+		err = func() error {
+			defer f.observeLatency(endpoint.URL, "ingest_downstream")()
+			u, err := url.Parse(endpoint.URL)
+			if err != nil {
+				return fmt.Errorf("parse URL: %w", err)
+			}
+
+			u.Path = path.Join(u.Path, profile.URL.Path)
+
+			// attach labels
+			u.RawQuery = query.Encode()
+
+			ctx, cancel := context.WithTimeout(ctx, endpoint.RemoteTimeout)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(profile.RawBody))
+			if err != nil {
+				return fmt.Errorf("create request: %w", err)
+			}
+
+			// set headers from endpoint
+			for k, v := range endpoint.Headers {
+				req.Header.Set(k, v)
+			}
+
+			// now set profile content type, overwrite what existed
+			for idx := range profile.ContentType {
+				if idx == 0 {
+					req.Header.Set(pyroscope.HeaderContentType, profile.ContentType[idx])
+					continue
+				}
+				req.Header.Add(pyroscope.HeaderContentType, profile.ContentType[idx])
+			}
+
+			resp, err := f.ingestClients[endpoint].Do(req)
+			if err != nil {
+				return fmt.Errorf("do request: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				wErr := &PyroscopeWriteError{StatusCode: resp.StatusCode}
+				wErr.readBody(resp)
+				return fmt.Errorf("remote error: %w", wErr)
+			}
+
+			// Ensure full body is read to keep http connection Keep-Alive
+			_, err = io.Copy(io.Discard, resp.Body)
+			if err != nil {
+				return fmt.Errorf("reading response body: %w", err)
+			}
+
+			return nil
+		}()
+		if err == nil {
+			f.metrics.sentBytes.WithLabelValues(endpoint.URL).Add(float64(reqSize))
+			f.metrics.sentProfiles.WithLabelValues(endpoint.URL).Add(float64(profileCount))
+			return nil
+		}
+		_ = level.Debug(l).Log(
+			"msg", "failed to ingest to endpoint",
+			"endpoint", endpoint.URL,
+			"retries", backoff.NumRetries(),
+			"err", err)
+		if !shouldRetry(err) {
+			break
+		}
+		backoff.Wait()
+		if !backoff.Ongoing() {
+			break
+		}
+		f.metrics.retries.WithLabelValues(endpoint.URL).Inc()
+	}
+	return fmt.Errorf("failed to ingest to endpoint %s (%d retries): %w", endpoint.URL, backoff.NumRetries(), err)
+}
+
 func (f *fanOutClient) observeLatency(endpoint, latencyType string) func() {
 	t := time.Now()
 	return func() {
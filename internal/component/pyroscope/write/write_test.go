@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"connectrpc.com/connect"
+	"github.com/alecthomas/units"
 	"github.com/grafana/alloy/internal/component/pyroscope"
 	pyrotestlogger "github.com/grafana/alloy/internal/component/pyroscope/util/testlog"
 	"github.com/grafana/alloy/syntax"
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/pyroscope/api/model/labelset"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.opentelemetry.io/otel/trace/noop"
@@ -101,6 +104,7 @@ func Test_Write_FanOut(t *testing.T) {
 			"Alloy/239",
 			"",
 			t.TempDir(),
+			nil,
 			arg,
 		)
 		require.NoError(t, err)
@@ -181,6 +185,7 @@ func Test_Write_Update(t *testing.T) {
 		"Alloy/239",
 		"",
 		t.TempDir(),
+		nil,
 		argument,
 	)
 	require.NoError(t, err)
@@ -323,6 +328,7 @@ func (s *AppendIngestTestSuite) newComponent(argument Arguments) {
 		"Alloy/239",
 		"",
 		s.T().TempDir(),
+		nil,
 		argument,
 	)
 	s.Require().NoError(err)
@@ -607,6 +613,7 @@ func Test_Write_FanOut_ValidateLabels(t *testing.T) {
 		"Alloy/239",
 		"",
 		t.TempDir(),
+		nil,
 		argument,
 	)
 	require.NoError(t, err)
@@ -687,3 +694,261 @@ func Test_Write_FanOut_ValidateLabels(t *testing.T) {
 		})
 	}
 }
+
+func Test_Write_WAL(t *testing.T) {
+	var (
+		mut      sync.Mutex
+		received []string
+		failing  = atomic.NewBool(true)
+	)
+	_, handler := pushv1connect.NewPusherServiceHandler(PushFunc(
+		func(_ context.Context, req *connect.Request[pushv1.PushRequest]) (*connect.Response[pushv1.PushResponse], error) {
+			if failing.Load() {
+				return nil, connect.NewError(connect.CodeUnavailable, errors.New("unavailable"))
+			}
+			mut.Lock()
+			defer mut.Unlock()
+			received = append(received, req.Msg.Series[0].Samples[0].ID)
+			return &connect.Response[pushv1.PushResponse]{}, nil
+		},
+	))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	argument := DefaultArguments()
+	argument.WAL.Enabled = true
+	argument.Endpoints = []*EndpointOptions{{
+		URL:           server.URL,
+		RemoteTimeout: GetDefaultEndpointOptions().RemoteTimeout,
+		MinBackoff:    10 * time.Millisecond,
+		MaxBackoff:    10 * time.Millisecond,
+	}}
+	dataPath := t.TempDir()
+
+	run := func(t *testing.T) (pyroscope.Appendable, func()) {
+		var (
+			wg     sync.WaitGroup
+			export Exports
+		)
+		wg.Add(1)
+		c, err := New(
+			pyrotestlogger.TestLogger(t),
+			noop.Tracer{},
+			prometheus.NewRegistry(),
+			func(e Exports) {
+				defer wg.Done()
+				export = e
+			},
+			"Alloy/239",
+			"",
+			dataPath,
+			nil,
+			argument,
+		)
+		require.NoError(t, err)
+		wg.Wait()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = c.Run(ctx)
+		}()
+		return export.Receiver, func() {
+			cancel()
+			<-done
+		}
+	}
+
+	// Profiles are accepted while the endpoint is unavailable.
+	receiver, stop := run(t)
+	for _, id := range []string{"1", "2"} {
+		err := receiver.Appender().Append(t.Context(), labels.FromStrings("__name__", "test"), []*pyroscope.RawSample{
+			{ID: id, RawProfile: []byte("pprofraw")},
+		})
+		require.NoError(t, err)
+	}
+	// Requests pushed directly are stored in the WAL as well.
+	_, err := receiver.(*fanOutClient).Push(t.Context(), connect.NewRequest(&pushv1.PushRequest{
+		Series: []*pushv1.RawProfileSeries{{
+			Labels:  []*typesv1.LabelPair{{Name: "__name__", Value: "test"}},
+			Samples: []*pushv1.RawSample{{ID: "3", RawProfile: []byte("pprofraw")}},
+		}},
+	}))
+	require.NoError(t, err)
+	stop()
+
+	// They are sent in order once the component is restarted and the
+	// endpoint is available again.
+	failing.Store(false)
+	receiver, stop = run(t)
+	defer stop()
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		mut.Lock()
+		defer mut.Unlock()
+		assert.Equal(c, []string{"1", "2", "3"}, received)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = receiver.Appender().Append(t.Context(), labels.FromStrings("__name__", "test"), []*pyroscope.RawSample{
+		{ID: "4", RawProfile: []byte("pprofraw")},
+	})
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		mut.Lock()
+		defer mut.Unlock()
+		assert.Equal(c, []string{"1", "2", "3", "4"}, received)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_Write_WAL_EndpointChanged(t *testing.T) {
+	startServer := func(failing bool) (*httptest.Server, func() []string) {
+		var (
+			mut      sync.Mutex
+			received []string
+		)
+		_, handler := pushv1connect.NewPusherServiceHandler(PushFunc(
+			func(_ context.Context, req *connect.Request[pushv1.PushRequest]) (*connect.Response[pushv1.PushResponse], error) {
+				if failing {
+					return nil, connect.NewError(connect.CodeUnavailable, errors.New("unavailable"))
+				}
+				mut.Lock()
+				defer mut.Unlock()
+				received = append(received, req.Msg.Series[0].Samples[0].ID)
+				return &connect.Response[pushv1.PushResponse]{}, nil
+			},
+		))
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		return server, func() []string {
+			mut.Lock()
+			defer mut.Unlock()
+			return slices.Clone(received)
+		}
+	}
+	oldServer, _ := startServer(true)
+	newServer, received := startServer(false)
+
+	argument := DefaultArguments()
+	argument.WAL.Enabled = true
+	argument.Endpoints = []*EndpointOptions{{
+		URL:           oldServer.URL,
+		RemoteTimeout: GetDefaultEndpointOptions().RemoteTimeout,
+		MinBackoff:    10 * time.Millisecond,
+		MaxBackoff:    10 * time.Millisecond,
+	}}
+	dataPath := t.TempDir()
+
+	var export Exports
+	c, err := New(
+		pyrotestlogger.TestLogger(t),
+		noop.Tracer{},
+		prometheus.NewRegistry(),
+		func(e Exports) { export = e },
+		"Alloy/239",
+		"",
+		dataPath,
+		nil,
+		argument,
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Profiles are pending in the WAL of the unavailable endpoint.
+	for _, id := range []string{"1", "2", "3"} {
+		err := export.Receiver.Appender().Append(t.Context(), labels.FromStrings("__name__", "test"), []*pyroscope.RawSample{
+			{ID: id, RawProfile: []byte("pprofraw")},
+		})
+		require.NoError(t, err)
+	}
+	oldDir := c.walQueues.walDir(argument.Endpoints[0])
+	require.DirExists(t, oldDir)
+
+	// Changing the URL of the endpoint drops its pending profiles instead of
+	// sending them to the new URL.
+	changed := argument
+	changed.Endpoints = []*EndpointOptions{{
+		URL:           newServer.URL,
+		RemoteTimeout: GetDefaultEndpointOptions().RemoteTimeout,
+		MinBackoff:    10 * time.Millisecond,
+		MaxBackoff:    10 * time.Millisecond,
+	}}
+	require.NoError(t, c.Update(changed))
+	require.NoDirExists(t, oldDir)
+
+	err = export.Receiver.Appender().Append(t.Context(), labels.FromStrings("__name__", "test"), []*pyroscope.RawSample{
+		{ID: "4", RawProfile: []byte("pprofraw")},
+	})
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, []string{"4"}, received())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return len(received()) > 1 }, 200*time.Millisecond, 10*time.Millisecond)
+}
+
+func Test_WalRecord(t *testing.T) {
+	push := &walRecord{push: &pushv1.PushRequest{
+		Series: []*pushv1.RawProfileSeries{{
+			Labels:  []*typesv1.LabelPair{{Name: "__name__", Value: "test"}},
+			Samples: []*pushv1.RawSample{{ID: "id", RawProfile: []byte("pprofraw")}},
+		}},
+	}}
+	data, err := push.marshal()
+	require.NoError(t, err)
+	decoded, err := unmarshalWalRecord(data)
+	require.NoError(t, err)
+	require.Nil(t, decoded.ingest)
+	require.Equal(t, "id", decoded.push.Series[0].Samples[0].ID)
+	require.Equal(t, []byte("pprofraw"), decoded.push.Series[0].Samples[0].RawProfile)
+
+	ingest := &walRecord{
+		ingest: &pyroscope.IncomingProfile{
+			RawBody:     []byte("body"),
+			ContentType: []string{"multipart/form-data", "boundary=x"},
+			URL:         &url.URL{Path: "/ingest"},
+		},
+		ingestQuery: url.Values{"name": []string{"app{}"}, "from": []string{"1"}},
+	}
+	data, err = ingest.marshal()
+	require.NoError(t, err)
+	decoded, err = unmarshalWalRecord(data)
+	require.NoError(t, err)
+	require.Nil(t, decoded.push)
+	require.Equal(t, ingest.ingest.RawBody, decoded.ingest.RawBody)
+	require.Equal(t, ingest.ingest.ContentType, decoded.ingest.ContentType)
+	require.Equal(t, "/ingest", decoded.ingest.URL.Path)
+	require.Equal(t, ingest.ingestQuery, decoded.ingestQuery)
+
+	_, err = unmarshalWalRecord(data[:len(data)-1])
+	require.Error(t, err)
+}
+
+func Test_Unmarshal_WAL(t *testing.T) {
+	var arg Arguments
+	err := syntax.Unmarshal([]byte(`
+	wal {
+		enabled  = true
+		max_size = "256MiB"
+	}`), &arg)
+	require.NoError(t, err)
+	require.True(t, arg.WAL.Enabled)
+	require.Equal(t, 256*units.MiB, arg.WAL.MaxSize)
+	require.Equal(t, DefaultWalArguments.MaxSegmentSize, arg.WAL.MaxSegmentSize)
+
+	err = syntax.Unmarshal([]byte(`
+	wal {
+		enabled          = true
+		max_size         = "64MiB"
+		max_segment_size = "64MiB"
+	}`), &arg)
+	require.ErrorContains(t, err, "WAL max segment size should be lower than max size")
+}
@@ -0,0 +1,43 @@
+package function
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+const (
+	// BlockName is the block name for function blocks.
+	BlockName = "function"
+	// StabilityLevel for function blocks.
+	StabilityLevel = featuregate.StabilityExperimental
+
+	// ArgumentBlockName is the name of the blocks declaring the arguments of
+	// a function.
+	ArgumentBlockName = "argument"
+
+	// ReturnAttrName is the name of the attribute holding the expression
+	// returned by a function.
+	ReturnAttrName = "return"
+)
+
+// Types lists the values accepted by the type attribute of an argument. An
+// empty type is the same as "any".
+var Types = []string{"any", "string", "number", "bool", "array", "object"}
+
+// Argument configures a single argument of a function.
+type Argument struct {
+	Optional bool   `alloy:"optional,attr,optional"`
+	Default  any    `alloy:"default,attr,optional"`
+	Type     string `alloy:"type,attr,optional"`
+	Comment  string `alloy:"comment,attr,optional"`
+}
+
+// Validate implements syntax.Validator.
+func (a *Argument) Validate() error {
+	if a.Type != "" && !slices.Contains(Types, a.Type) {
+		return fmt.Errorf("unsupported argument type %q, must be one of %v", a.Type, Types)
+	}
+	return nil
+}
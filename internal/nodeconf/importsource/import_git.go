@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-kit/log"
 
 	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/signature"
 	"github.com/grafana/alloy/internal/runtime/equality"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/vcs"
@@ -30,6 +32,7 @@ type ImportGit struct {
 	repoOpts        vcs.GitRepoOptions
 	args            GitArguments
 	repoPath        string
+	verifier        *signature.Verifier
 	onContentChange func(map[string]string)
 
 	argsChanged chan struct{}
@@ -50,6 +53,8 @@ type GitArguments struct {
 	Path          string            `alloy:"path,attr"`
 	PullFrequency time.Duration     `alloy:"pull_frequency,attr,optional"`
 	GitAuthConfig vcs.GitAuthConfig `alloy:",squash"`
+
+	Signature *signature.Arguments `alloy:"signature,block,optional"`
 }
 
 var DefaultGitArguments = GitArguments{
@@ -206,6 +211,15 @@ func (im *ImportGit) Update(args component.Arguments) (err error) {
 		Auth:       newArgs.GitAuthConfig,
 	}
 
+	// Set up signature verification before polling, so that the module is
+	// checked with the new settings.
+	im.verifier = nil
+	if newArgs.Signature != nil {
+		if im.verifier, err = signature.NewVerifier(*newArgs.Signature); err != nil {
+			return fmt.Errorf("creating signature verifier: %w", err)
+		}
+	}
+
 	// Create or update the repo field.
 	// Failure to update repository makes the module loader temporarily use cached contents on disk
 	if im.repo == nil || !equality.DeepEqual(repoOpts, im.repoOpts) {
@@ -277,7 +291,7 @@ func (im *ImportGit) handleDirectory(path string) error {
 		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".alloy") {
 			continue
 		}
-		bb, err := im.repo.ReadFile(filepath.Join(path, fi.Name()))
+		bb, err := im.readVerifiedFile(filepath.Join(path, fi.Name()))
 		if err != nil {
 			return err
 		}
@@ -288,7 +302,7 @@ func (im *ImportGit) handleDirectory(path string) error {
 }
 
 func (im *ImportGit) handleFile(path string) error {
-	bb, err := im.repo.ReadFile(path)
+	bb, err := im.readVerifiedFile(path)
 	if err != nil {
 		return err
 	}
@@ -296,6 +310,38 @@ func (im *ImportGit) handleFile(path string) error {
 	return nil
 }
 
+// readVerifiedFile reads a file from the repository and verifies its
+// signature if enabled. Detached signatures are read from the file with the
+// signature.FileSuffix suffix next to it. readVerifiedFile must only be
+// called with im.mut held.
+func (im *ImportGit) readVerifiedFile(path string) ([]byte, error) {
+	bb, err := im.repo.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if im.verifier == nil {
+		return bb, nil
+	}
+
+	var sig string
+	if im.verifier.Format() == signature.FormatDetached {
+		sb, err := im.repo.ReadFile(path + signature.FileSuffix)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("verifying signature of %s: %w", path, signature.ErrUnsigned)
+		case err != nil:
+			return nil, err
+		}
+		sig = string(sb)
+	}
+
+	verified, err := im.verifier.Verify(bb, sig)
+	if err != nil {
+		return nil, fmt.Errorf("verifying signature of %s: %w", path, err)
+	}
+	return verified, nil
+}
+
 // CurrentHealth implements component.HealthComponent.
 func (im *ImportGit) CurrentHealth() component.Health {
 	im.healthMut.RLock()
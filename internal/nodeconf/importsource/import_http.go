@@ -3,15 +3,20 @@ package importsource
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/grafana/alloy/internal/component"
 	common_config "github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/signature"
 	remote_http "github.com/grafana/alloy/internal/component/remote/http"
 	"github.com/grafana/alloy/internal/runtime/equality"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/syntax/vm"
+	prom_config "github.com/prometheus/common/config"
 )
 
 // ImportHTTP imports a module from a HTTP server via the remote.http component.
@@ -20,19 +25,31 @@ type ImportHTTP struct {
 	arguments         HTTPArguments
 	managedOpts       component.Options
 	eval              *vm.Evaluator
+	onContentChange   func(map[string]string)
+
+	// mut protects the state used to verify the signature of the content.
+	mut        sync.Mutex
+	verifyArgs HTTPArguments
+	verifier   *signature.Verifier
+	sigClient  *http.Client
+	content    *string // Last content received from the remote.http component.
+	accepted   bool    // Whether any content passed verification.
+	verifyErr  error   // Error of the last verification.
 }
 
 var _ ImportSource = (*ImportHTTP)(nil)
 
 func NewImportHTTP(managedOpts component.Options, eval *vm.Evaluator, onContentChange func(map[string]string)) *ImportHTTP {
+	im := &ImportHTTP{
+		eval:            eval,
+		onContentChange: onContentChange,
+	}
 	opts := managedOpts
 	opts.OnStateChange = func(e component.Exports) {
-		onContentChange(map[string]string{opts.ID: e.(remote_http.Exports).Content.Value})
-	}
-	return &ImportHTTP{
-		managedOpts: opts,
-		eval:        eval,
+		im.handleContent(e.(remote_http.Exports).Content.Value)
 	}
+	im.managedOpts = opts
+	return im
 }
 
 // HTTPArguments holds values which are used to configure the remote.http component.
@@ -46,6 +63,8 @@ type HTTPArguments struct {
 	Body    string            `alloy:"body,attr,optional"`
 
 	Client common_config.HTTPClientConfig `alloy:"client,block,optional"`
+
+	Signature *signature.Arguments `alloy:"signature,block,optional"`
 }
 
 // DefaultHTTPArguments holds default settings for HTTPArguments.
@@ -76,31 +95,216 @@ func (im *ImportHTTP) Evaluate(scope *vm.Scope) error {
 		Client:        arguments.Client,
 	}
 	if im.managedRemoteHTTP == nil {
+		if err := im.updateVerifier(arguments); err != nil {
+			return err
+		}
+
 		var err error
 		im.managedRemoteHTTP, err = remote_http.New(im.managedOpts, remoteHttpArguments)
 		if err != nil {
 			return fmt.Errorf("creating http component: %w", err)
 		}
 		im.arguments = arguments
+
+		// Unverified modules must not be loaded when there isn't a previous
+		// version to fall back to.
+		if err := im.rejectedErr(); err != nil {
+			return err
+		}
 	}
 
 	if equality.DeepEqual(im.arguments, arguments) {
 		return nil
 	}
 
+	signatureChanged := !equality.DeepEqual(im.arguments.Signature, arguments.Signature)
+	if err := im.updateVerifier(arguments); err != nil {
+		return err
+	}
+
 	// Update the existing managed component
 	if err := im.managedRemoteHTTP.Update(remoteHttpArguments); err != nil {
 		return fmt.Errorf("updating component: %w", err)
 	}
 	im.arguments = arguments
+
+	// The content isn't sent again if it didn't change, so it must be checked
+	// against the new settings.
+	if signatureChanged {
+		im.reverify()
+	}
+	return nil
+}
+
+// updateVerifier sets up the verification of the signature of the content
+// from the arguments. It must be called before the remote.http component is
+// updated, since it reports new content synchronously.
+func (im *ImportHTTP) updateVerifier(arguments HTTPArguments) error {
+	var (
+		verifier  *signature.Verifier
+		sigClient *http.Client
+	)
+	if arguments.Signature != nil {
+		var err error
+		verifier, err = signature.NewVerifier(*arguments.Signature)
+		if err != nil {
+			return fmt.Errorf("creating signature verifier: %w", err)
+		}
+		sigClient, err = prom_config.NewClientFromConfig(*arguments.Client.Convert(), im.managedOpts.ID)
+		if err != nil {
+			return fmt.Errorf("creating signature client: %w", err)
+		}
+	}
+
+	im.mut.Lock()
+	defer im.mut.Unlock()
+	im.verifyArgs = arguments
+	im.verifier = verifier
+	im.sigClient = sigClient
+	if verifier == nil {
+		im.verifyErr = nil
+	}
+	return nil
+}
+
+// handleContent verifies the signature of content, if enabled, and passes it
+// on to the module loader. Content which fails verification is rejected and
+// the previous module keeps running.
+func (im *ImportHTTP) handleContent(content string) {
+	im.mut.Lock()
+	im.content = &content
+	verifier, sigClient, arguments := im.verifier, im.sigClient, im.verifyArgs
+	im.mut.Unlock()
+
+	if verifier == nil {
+		im.onContentChange(map[string]string{im.managedOpts.ID: content})
+		return
+	}
+
+	verified, err := im.verify(verifier, sigClient, arguments, content)
+
+	im.mut.Lock()
+	im.verifyErr = err
+	if err == nil {
+		im.accepted = true
+	}
+	im.mut.Unlock()
+
+	if err != nil {
+		level.Error(im.managedOpts.Logger).Log("msg", "rejecting module which failed signature verification", "url", arguments.URL, "err", err)
+		return
+	}
+	im.onContentChange(map[string]string{im.managedOpts.ID: string(verified)})
+}
+
+func (im *ImportHTTP) verify(verifier *signature.Verifier, sigClient *http.Client, arguments HTTPArguments, content string) ([]byte, error) {
+	var sig string
+	if verifier.Format() == signature.FormatDetached {
+		var err error
+		if sig, err = fetchSignature(sigClient, arguments); err != nil {
+			return nil, err
+		}
+	}
+	return verifier.Verify([]byte(content), sig)
+}
+
+// fetchSignature fetches the detached signature of the module, which is
+// served next to it with the signature.FileSuffix suffix.
+func fetchSignature(cli *http.Client, arguments HTTPArguments) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), arguments.PollTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, arguments.URL+signature.FileSuffix, nil)
+	if err != nil {
+		return "", err
+	}
+	for name, value := range arguments.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", signature.ErrUnsigned
+	case resp.StatusCode/100 != 2:
+		return "", fmt.Errorf("fetching signature: unexpected status code %s", resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading signature: %w", err)
+	}
+	return string(b), nil
+}
+
+// reverify checks the last received content again.
+func (im *ImportHTTP) reverify() {
+	im.mut.Lock()
+	content := im.content
+	im.mut.Unlock()
+
+	if content != nil {
+		im.handleContent(*content)
+	}
+}
+
+// rejectedErr returns the verification error if no content was ever accepted.
+func (im *ImportHTTP) rejectedErr() error {
+	im.mut.Lock()
+	defer im.mut.Unlock()
+
+	if im.verifyErr != nil && !im.accepted {
+		return fmt.Errorf("verifying module signature: %w", im.verifyErr)
+	}
 	return nil
 }
 
 func (im *ImportHTTP) Run(ctx context.Context) error {
+	go im.retryRejected(ctx)
 	return im.managedRemoteHTTP.Run(ctx)
 }
 
+// retryRejected periodically checks rejected content again, since the
+// remote.http component only reports content when it changes. This handles
+// signatures which are published after the module.
+func (im *ImportHTTP) retryRejected(ctx context.Context) {
+	for {
+		im.mut.Lock()
+		pollFrequency := im.verifyArgs.PollFrequency
+		im.mut.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollFrequency):
+		}
+
+		im.mut.Lock()
+		rejected := im.verifyErr != nil
+		im.mut.Unlock()
+		if rejected {
+			im.reverify()
+		}
+	}
+}
+
 func (im *ImportHTTP) CurrentHealth() component.Health {
+	im.mut.Lock()
+	verifyErr := im.verifyErr
+	im.mut.Unlock()
+
+	if verifyErr != nil {
+		return component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    fmt.Sprintf("signature verification failed: %s", verifyErr),
+			UpdateTime: time.Now(),
+		}
+	}
 	return im.managedRemoteHTTP.CurrentHealth()
 }
 
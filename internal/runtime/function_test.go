@@ -0,0 +1,321 @@
+package runtime_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime"
+	"github.com/grafana/alloy/internal/runtime/internal/testcomponents"
+	"github.com/grafana/alloy/internal/runtime/logging"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunction(t *testing.T) {
+	tt := []testCase{
+		{
+			name: "LocalFunction",
+			config: `
+			function "double" {
+				argument "x" {
+					type = "number"
+				}
+				return = argument.x.value * 2
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			testcomponents.summation "sum" {
+				input = double(testcomponents.count.inc.count)
+			}
+			`,
+			expected: 20,
+		},
+		{
+			name: "OptionalArgument",
+			config: `
+			function "add" {
+				argument "x" {}
+				argument "y" {
+					optional = true
+					default  = 5
+				}
+				return = argument.x.value + argument.y.value
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			testcomponents.summation "sum" {
+				input = add(testcomponents.count.inc.count)
+			}
+			`,
+			expected: 15,
+		},
+		{
+			name: "FunctionCallingFunction",
+			config: `
+			function "double" {
+				argument "x" {}
+				return = argument.x.value * 2
+			}
+
+			function "triple" {
+				argument "x" {}
+				return = double(argument.x.value) + argument.x.value
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			testcomponents.summation "sum" {
+				input = triple(testcomponents.count.inc.count)
+			}
+			`,
+			expected: 30,
+		},
+		{
+			name: "ImportedFunction",
+			config: `
+			import.string "lib" {
+				content = ` + "`" + `
+					function "negate" {
+						argument "x" {}
+						return = -argument.x.value
+					}
+				` + "`" + `
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			testcomponents.summation "sum" {
+				input = lib.negate(testcomponents.count.inc.count)
+			}
+			`,
+			expected: -10,
+		},
+		{
+			name: "ImportedFunctionInImportedDeclare",
+			config: `
+			import.string "lib" {
+				content = ` + "`" + `
+					function "negate" {
+						argument "x" {}
+						return = -argument.x.value
+					}
+
+					declare "negation" {
+						argument "input" {}
+
+						export "output" {
+							value = negate(argument.input.value)
+						}
+					}
+				` + "`" + `
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			lib.negation "default" {
+				input = testcomponents.count.inc.count
+			}
+
+			testcomponents.summation "sum" {
+				input = lib.negation.default.output
+			}
+			`,
+			expected: -10,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := testOptions(t)
+			opts.MinStability = featuregate.StabilityExperimental
+			ctrl := runtime.New(opts)
+			f, err := runtime.ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+			require.NotNil(t, f)
+
+			err = ctrl.LoadSource(f, nil, "")
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan struct{})
+			go func() {
+				ctrl.Run(ctx)
+				close(done)
+			}()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			require.Eventually(t, func() bool {
+				return ctrl.LoadComplete()
+			}, 3*time.Second, 10*time.Millisecond)
+
+			require.Eventually(t, func() bool {
+				export := getExport[testcomponents.SummationExports](t, ctrl, "", "testcomponents.summation.sum")
+				return export.LastAdded == tc.expected
+			}, 3*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestFunctionError(t *testing.T) {
+	tt := []errorTestCase{
+		{
+			name: "RecursiveFunction",
+			config: `
+			function "a" {
+				argument "x" {}
+				return = a(argument.x.value)
+			}
+			`,
+			expectedError: regexp.MustCompile(`self reference: function\.a`),
+		},
+		{
+			name: "MutuallyRecursiveFunctions",
+			config: `
+			function "a" {
+				argument "x" {}
+				return = b(argument.x.value)
+			}
+			function "b" {
+				argument "x" {}
+				return = a(argument.x.value)
+			}
+			`,
+			expectedError: regexp.MustCompile(`cycle: function\.(a|b), function\.(a|b)`),
+		},
+		{
+			name: "ReferenceToComponent",
+			config: `
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+
+			function "a" {
+				return = testcomponents.count.inc.count
+			}
+			`,
+			expectedError: regexp.MustCompile(`function "a" can't reference testcomponents\.count\.inc\.count`),
+		},
+		{
+			name: "UnknownArgument",
+			config: `
+			function "a" {
+				argument "x" {}
+				return = argument.y.value
+			}
+			`,
+			expectedError: regexp.MustCompile(`argument\.y\.value is not an argument of function "a"`),
+		},
+		{
+			name: "NameClashWithComponent",
+			config: `
+			function "testcomponents" {
+				return = 1
+			}
+
+			testcomponents.count "inc" {
+				frequency = "10ms"
+				max = 10
+			}
+			`,
+			expectedError: regexp.MustCompile(`function "testcomponents" has the same name as the first part of component "testcomponents\.count\.inc"`),
+		},
+		{
+			name: "WrongArgumentType",
+			config: `
+			function "double" {
+				argument "x" {
+					type = "number"
+				}
+				return = argument.x.value * 2
+			}
+
+			testcomponents.summation "sum" {
+				input = double(true)
+			}
+			`,
+			expectedError: regexp.MustCompile(`argument "x" should be number, got bool`),
+		},
+		{
+			name: "MissingArgument",
+			config: `
+			function "add" {
+				argument "x" {}
+				argument "y" {}
+				return = argument.x.value + argument.y.value
+			}
+
+			testcomponents.summation "sum" {
+				input = add(1)
+			}
+			`,
+			expectedError: regexp.MustCompile(`missing required argument "y"`),
+		},
+		{
+			name: "MissingReturn",
+			config: `
+			function "a" {
+				argument "x" {}
+			}
+			`,
+			expectedError: regexp.MustCompile(`function "a" is missing the "return" attribute`),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer verifyNoGoroutineLeaks(t)
+			s, err := logging.New(os.Stderr, logging.DefaultOptions)
+			require.NoError(t, err)
+			ctrl := runtime.New(runtime.Options{
+				Logger:       s,
+				DataPath:     t.TempDir(),
+				MinStability: featuregate.StabilityExperimental,
+				Reg:          nil,
+				Services:     []service.Service{},
+			})
+			f, err := runtime.ParseSource(t.Name(), []byte(tc.config))
+			require.NoError(t, err)
+			require.NotNil(t, f)
+
+			err = ctrl.LoadSource(f, nil, "")
+			if err == nil {
+				t.Errorf("Expected error to match regex %q, but got: nil", tc.expectedError)
+			} else if !tc.expectedError.MatchString(err.Error()) {
+				t.Errorf("Expected error to match regex %q, but got: %v", tc.expectedError, err)
+			}
+
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan struct{})
+			go func() {
+				ctrl.Run(ctx)
+				close(done)
+			}()
+			cancel()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/grafana/alloy/internal/nodeconf/function"
+	astutil "github.com/grafana/alloy/internal/util/ast"
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/diag"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+// userFunction is the type of the values exposed to Alloy expressions for
+// user-defined functions.
+type userFunction func(args ...any) (any, error)
+
+// functionDefinition is the parsed content of a function block.
+type functionDefinition struct {
+	name       string
+	arguments  []functionArgument
+	returnExpr ast.Expr
+}
+
+type functionArgument struct {
+	name string
+	function.Argument
+}
+
+// parseFunction builds a functionDefinition from the body of a function
+// block. The argument blocks are evaluated with the provided scope.
+func parseFunction(name string, body ast.Body, scope *vm.Scope) (*functionDefinition, error) {
+	def := &functionDefinition{name: name}
+	seen := make(map[string]struct{})
+
+	for _, stmt := range body {
+		switch stmt := stmt.(type) {
+		case *ast.BlockStmt:
+			if stmt.GetBlockName() != function.ArgumentBlockName {
+				return nil, fmt.Errorf("unsupported block %q in function %q", stmt.GetBlockName(), name)
+			}
+			if stmt.Label == "" {
+				return nil, fmt.Errorf("argument blocks in function %q must have a label", name)
+			}
+			if _, ok := seen[stmt.Label]; ok {
+				return nil, fmt.Errorf("argument %q redefined in function %q", stmt.Label, name)
+			}
+			seen[stmt.Label] = struct{}{}
+
+			var arg function.Argument
+			if err := vm.New(stmt.Body).Evaluate(scope, &arg); err != nil {
+				return nil, fmt.Errorf("decoding argument %q of function %q: %w", stmt.Label, name, err)
+			}
+			if !arg.Optional && len(def.arguments) > 0 && def.arguments[len(def.arguments)-1].Optional {
+				return nil, fmt.Errorf("required argument %q of function %q can't follow an optional argument", stmt.Label, name)
+			}
+			def.arguments = append(def.arguments, functionArgument{name: stmt.Label, Argument: arg})
+
+		case *ast.AttributeStmt:
+			if stmt.Name.Name != function.ReturnAttrName {
+				return nil, fmt.Errorf("unsupported attribute %q in function %q", stmt.Name.Name, name)
+			}
+			if def.returnExpr != nil {
+				return nil, fmt.Errorf("attribute %q redefined in function %q", function.ReturnAttrName, name)
+			}
+			def.returnExpr = stmt.Value
+
+		default:
+			return nil, fmt.Errorf("unsupported statement type %T in function %q", stmt, name)
+		}
+	}
+
+	if def.returnExpr == nil {
+		return nil, fmt.Errorf("function %q is missing the %q attribute", name, function.ReturnAttrName)
+	}
+	return def, nil
+}
+
+// call evaluates the return expression of the function. The arguments are
+// exposed as argument.NAME.value, like the arguments of a module.
+func (def *functionDefinition) call(scope *vm.Scope, args []any) (any, error) {
+	if len(args) > len(def.arguments) {
+		return nil, fmt.Errorf("expected at most %d arguments, got %d", len(def.arguments), len(args))
+	}
+
+	values := make(map[string]any, len(def.arguments))
+	for i, arg := range def.arguments {
+		var v any
+		switch {
+		case i < len(args):
+			v = args[i]
+		case arg.Optional:
+			v = arg.Default
+		default:
+			return nil, fmt.Errorf("missing required argument %q", arg.name)
+		}
+
+		if err := checkArgumentType(arg.name, arg.Type, v); err != nil {
+			return nil, err
+		}
+		if containsFunction(reflect.ValueOf(v)) {
+			return nil, fmt.Errorf("argument %q can't contain functions", arg.name)
+		}
+		values[arg.name] = map[string]any{"value": v}
+	}
+
+	vars := make(map[string]any, len(scope.Variables)+1)
+	maps.Copy(vars, scope.Variables)
+	vars[argumentLabel] = values
+
+	var res any
+	if err := vm.New(def.returnExpr).Evaluate(vm.NewScope(vars), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// argumentTypes maps the supported argument types to constructors of Go
+// values which only accept Alloy values of that type.
+var argumentTypes = map[string]func() any{
+	"string": func() any { return new(string) },
+	"number": func() any { return new(float64) },
+	"bool":   func() any { return new(bool) },
+	"array":  func() any { return new([]any) },
+	"object": func() any { return new(map[string]any) },
+}
+
+// checkArgumentType checks that v can be assigned to the type of an argument,
+// following the same conversion rules as component arguments.
+func checkArgumentType(name string, typ string, v any) error {
+	newTarget, ok := argumentTypes[typ]
+	if !ok {
+		return nil
+	}
+
+	// The value is evaluated through a single character identifier which
+	// doesn't need a source file to compute its position in the diagnostic.
+	const ident = "v"
+	expr := &ast.IdentifierExpr{Ident: &ast.Ident{Name: ident}}
+	err := vm.New(expr).Evaluate(vm.NewScope(map[string]any{ident: v}), newTarget())
+	if err == nil {
+		return nil
+	}
+
+	var d diag.Diagnostic
+	if errors.As(err, &d) {
+		return fmt.Errorf("argument %q%s", name, strings.TrimPrefix(d.Message, ident))
+	}
+	return fmt.Errorf("argument %q: %w", name, err)
+}
+
+// containsFunction reports whether v holds a function value. Functions can't
+// be passed as arguments because they could be used to bypass the recursion
+// checks done when loading the config.
+func containsFunction(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func:
+		return true
+	case reflect.Interface, reflect.Pointer:
+		return !v.IsNil() && containsFunction(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if containsFunction(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if containsFunction(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findFunctionCycle looks for functions calling each other in a loop within
+// the provided set of function blocks. The returned slice holds the names of
+// the functions of the first cycle found, or is empty if there is none.
+func findFunctionCycle(functions map[string]*ast.BlockStmt) []string {
+	calls := make(map[string][]string, len(functions))
+	for name, block := range functions {
+		for _, t := range astutil.TraversalsFromBody(block.Body) {
+			if _, ok := functions[t[0].Name]; ok {
+				calls[name] = append(calls[name], t[0].Name)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		state = make(map[string]int, len(functions))
+		stack []string
+		visit func(name string) []string
+	)
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(stack, name)
+			return append(slices.Clone(stack[start:]), name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, callee := range calls[name] {
+			if cycle := visit(callee); cycle != nil {
+				return cycle
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+
+	// Iterate in a stable order so that the reported cycle is deterministic.
+	for _, name := range slices.Sorted(maps.Keys(functions)) {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// formatFunctionCycle formats a cycle returned by findFunctionCycle.
+func formatFunctionCycle(cycle []string) string {
+	return strings.Join(cycle, " -> ")
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/parser"
+	"github.com/grafana/alloy/syntax/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFunctionBlocks(t *testing.T, content string) map[string]*ast.BlockStmt {
+	t.Helper()
+
+	file, err := parser.ParseFile(t.Name(), []byte(content))
+	require.NoError(t, err)
+
+	blocks := make(map[string]*ast.BlockStmt)
+	for _, stmt := range file.Body {
+		block := stmt.(*ast.BlockStmt)
+		blocks[block.Label] = block
+	}
+	return blocks
+}
+
+func TestFunctionCall(t *testing.T) {
+	blocks := parseFunctionBlocks(t, `
+		function "format_address" {
+			argument "host" {
+				type = "string"
+			}
+			argument "port" {
+				optional = true
+				default  = 80
+				type     = "number"
+			}
+			return = string.format("%s:%d", argument.host.value, argument.port.value)
+		}
+	`)
+	def, err := parseFunction("format_address", blocks["format_address"].Body, vm.NewScope(nil))
+	require.NoError(t, err)
+
+	res, err := def.call(vm.NewScope(nil), []any{"localhost"})
+	require.NoError(t, err)
+	require.Equal(t, "localhost:80", res)
+
+	res, err = def.call(vm.NewScope(nil), []any{"localhost", 8080})
+	require.NoError(t, err)
+	require.Equal(t, "localhost:8080", res)
+
+	_, err = def.call(vm.NewScope(nil), []any{})
+	require.EqualError(t, err, `missing required argument "host"`)
+
+	_, err = def.call(vm.NewScope(nil), []any{"localhost", 8080, true})
+	require.EqualError(t, err, "expected at most 2 arguments, got 3")
+
+	_, err = def.call(vm.NewScope(nil), []any{"localhost", true})
+	require.EqualError(t, err, `argument "port" should be number, got bool`)
+
+	_, err = def.call(vm.NewScope(nil), []any{[]any{"localhost"}})
+	require.EqualError(t, err, `argument "host" should be string, got array`)
+}
+
+func TestFunctionArgumentsWithFunctions(t *testing.T) {
+	blocks := parseFunctionBlocks(t, `
+		function "identity" {
+			argument "x" {}
+			return = argument.x.value
+		}
+	`)
+	def, err := parseFunction("identity", blocks["identity"].Body, vm.NewScope(nil))
+	require.NoError(t, err)
+
+	var fn userFunction = func(args ...any) (any, error) { return nil, nil }
+	_, err = def.call(vm.NewScope(nil), []any{map[string]any{"fn": fn}})
+	require.EqualError(t, err, `argument "x" can't contain functions`)
+}
+
+func TestParseFunctionErrors(t *testing.T) {
+	tt := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name: "missing return",
+			content: `function "f" {
+				argument "x" {}
+			}`,
+			expectedErr: `function "f" is missing the "return" attribute`,
+		},
+		{
+			name: "required after optional",
+			content: `function "f" {
+				argument "x" {
+					optional = true
+				}
+				argument "y" {}
+				return = 1
+			}`,
+			expectedErr: `required argument "y" of function "f" can't follow an optional argument`,
+		},
+		{
+			name: "unsupported type",
+			content: `function "f" {
+				argument "x" {
+					type = "duration"
+				}
+				return = 1
+			}`,
+			expectedErr: `unsupported argument type "duration"`,
+		},
+		{
+			name: "unsupported attribute",
+			content: `function "f" {
+				value = 1
+			}`,
+			expectedErr: `unsupported attribute "value" in function "f"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks := parseFunctionBlocks(t, tc.content)
+			_, err := parseFunction("f", blocks["f"].Body, vm.NewScope(nil))
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestFindFunctionCycle(t *testing.T) {
+	blocks := parseFunctionBlocks(t, `
+		function "a" {
+			return = b() + c()
+		}
+		function "b" {
+			return = c()
+		}
+		function "c" {
+			return = 1
+		}
+	`)
+	require.Empty(t, findFunctionCycle(blocks))
+
+	blocks = parseFunctionBlocks(t, `
+		function "a" {
+			return = b()
+		}
+		function "b" {
+			return = c()
+		}
+		function "c" {
+			return = a()
+		}
+	`)
+	require.Equal(t, []string{"a", "b", "c", "a"}, findFunctionCycle(blocks))
+
+	blocks = parseFunctionBlocks(t, `
+		function "a" {
+			return = a()
+		}
+	`)
+	require.Equal(t, "a -> a", formatFunctionCycle(findFunctionCycle(blocks)))
+}
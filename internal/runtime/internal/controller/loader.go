@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/alloy/internal/dag"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/nodeconf/foreach"
+	"github.com/grafana/alloy/internal/nodeconf/function"
 	"github.com/grafana/alloy/internal/runtime/internal/worker"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/runtime/tracing"
@@ -55,6 +56,7 @@ type Loader struct {
 	declareNodes         map[string]*DeclareNode
 	importConfigNodes    map[string]*ImportConfigNode
 	forEachNodes         map[string]*ForeachConfigNode
+	functionNodes        map[string]*FunctionConfigNode
 	serviceNodes         []*ServiceNode
 	cache                *valueCache
 	blocks               []*ast.BlockStmt // Most recently loaded blocks, used for writing
@@ -329,6 +331,11 @@ func (l *Loader) loadNewGraph(args map[string]any, componentBlocks []*ast.BlockS
 	componentNodeDiags := l.populateComponentNodes(&g, componentBlocks)
 	diags = append(diags, componentNodeDiags...)
 
+	// Expose the functions to the expressions of the module. This must be
+	// done before wiring the edges so that the function calls can be resolved.
+	functionDiags := l.cacheFunctions(&g)
+	diags = append(diags, functionDiags...)
+
 	// Write up the edges of the graph
 	wireDiags := l.wireGraphEdges(&g)
 	diags = append(diags, wireDiags...)
@@ -561,6 +568,7 @@ func (l *Loader) populateConfigBlockNodes(args map[string]any, g *dag.Graph, con
 
 	l.importConfigNodes = nodeMap.importMap
 	l.forEachNodes = nodeMap.foreachMap
+	l.functionNodes = nodeMap.functionMap
 
 	return diags
 }
@@ -647,11 +655,20 @@ func (l *Loader) wireGraphEdges(g *dag.Graph) diag.Diagnostics {
 			l.wireCustomComponentNode(g, n)
 		case *ForeachConfigNode:
 			l.wireForEachNode(g, n)
+		case *FunctionConfigNode:
+			// Functions only reference their arguments, other functions
+			// and the stdlib, so they don't need the component references.
+			diags = append(diags, l.wireFunctionNode(g, n)...)
+			continue
+		}
+
+		if bn, ok := n.(BlockNode); ok {
+			l.wireFunctionReferences(g, bn)
 		}
 
 		// Finally, wire component references.
 		l.cache.mut.RLock()
-		refs, nodeDiags := ComponentReferences(n, g, l.log, l.cache.GetContext(), l.globals.MinStability)
+		refs, nodeDiags := ComponentReferences(n, g, l.log, l.referenceScope(), l.globals.MinStability)
 		l.cache.mut.RUnlock()
 		setDataFlowEdges(n, refs)
 		for _, ref := range refs {
@@ -687,6 +704,144 @@ func (l *Loader) wireForEachNode(g *dag.Graph, fn *ForeachConfigNode) {
 	}
 }
 
+// wireFunctionNode adds edges between a function node and the function and
+// import nodes that it calls. Calling a function must not depend on the state
+// of the pipeline, so references to anything else than the arguments, other
+// functions and the stdlib are rejected. Recursive calls show up as cycles
+// in the graph.
+func (l *Loader) wireFunctionNode(g *dag.Graph, fn *FunctionConfigNode) diag.Diagnostics {
+	var (
+		diags     diag.Diagnostics
+		block     = fn.Block()
+		arguments = make(map[string]struct{})
+		scope     = l.cache.GetContext()
+	)
+
+	for _, stmt := range block.Body {
+		if b, ok := stmt.(*ast.BlockStmt); ok && b.GetBlockName() == function.ArgumentBlockName {
+			arguments[b.Label] = struct{}{}
+		}
+	}
+
+	for _, t := range astutil.TraversalsFromBody(block.Body) {
+		var (
+			name = t[0].Name
+			msg  string
+		)
+
+		if dep, ok := l.functionNodes[name]; ok {
+			g.AddEdge(dag.Edge{From: fn, To: dep})
+			continue
+		}
+		if importNode, ok := l.importConfigNodes[name]; ok && len(t) > 1 {
+			g.AddEdge(dag.Edge{From: fn, To: importNode})
+			continue
+		}
+
+		_, resolveDiags := astutil.ResolveTraversal(t, g)
+		_, scopeMatch := scope.Lookup(name)
+		switch {
+		case name == argumentLabel:
+			if len(t) > 1 {
+				if _, ok := arguments[t[1].Name]; ok {
+					continue
+				}
+			}
+			msg = fmt.Sprintf("%s is not an argument of function %q", t.String(), fn.Label())
+		case !resolveDiags.HasErrors():
+			msg = fmt.Sprintf("function %q can't reference %s, functions can only reference their arguments, other functions and the standard library", fn.Label(), t.String())
+		case !scopeMatch:
+			diags = append(diags, resolveDiags...)
+			continue
+		case scope.IsStdlibExperimental(t.String()):
+			if err := featuregate.CheckAllowed(featuregate.StabilityExperimental, l.globals.MinStability, t.String()); err != nil {
+				msg = err.Error()
+			}
+		}
+
+		if msg != "" {
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  msg,
+				StartPos: ast.StartPos(t[0]).Position(),
+				EndPos:   ast.EndPos(t[len(t)-1]).Position(),
+			})
+		}
+	}
+
+	return diags
+}
+
+// wireFunctionReferences adds edges between a node and the function and
+// import nodes of the functions that it calls.
+func (l *Loader) wireFunctionReferences(g *dag.Graph, n BlockNode) {
+	if n.Block() == nil {
+		return
+	}
+	for _, t := range astutil.TraversalsFromBody(n.Block().Body) {
+		if fn, ok := l.functionNodes[t[0].Name]; ok {
+			g.AddEdge(dag.Edge{From: n, To: fn})
+		} else if importNode, ok := l.importConfigNodes[t[0].Name]; ok && len(t) > 1 {
+			g.AddEdge(dag.Edge{From: n, To: importNode})
+		}
+	}
+}
+
+// referenceScope returns the scope used to check the references of the nodes.
+// Imports are added to it because the functions that they export aren't known
+// before they are evaluated. The cache mut must be held when calling
+// referenceScope.
+func (l *Loader) referenceScope() *vm.Scope {
+	scope := l.cache.GetContext()
+	for label := range l.importConfigNodes {
+		if _, ok := scope.Variables[label]; !ok {
+			scope.Variables[label] = make(map[string]any)
+		}
+	}
+	return scope
+}
+
+// cacheFunctions exposes the function nodes and the functions exported by the
+// imports to the expressions of the module.
+func (l *Loader) cacheFunctions(g *dag.Graph) diag.Diagnostics {
+	var (
+		diags      diag.Diagnostics
+		components = make(map[string]string)
+		functions  = make(map[string]any, len(l.functionNodes)+len(l.importConfigNodes))
+	)
+
+	for _, n := range g.Nodes() {
+		if cn, ok := n.(ComponentNode); ok {
+			components[cn.ID()[0]] = cn.NodeID()
+		}
+	}
+
+	for label, fn := range l.functionNodes {
+		if id, ok := components[label]; ok {
+			diags.Add(diag.Diagnostic{
+				Severity: diag.SeverityLevelError,
+				Message:  fmt.Sprintf("function %q has the same name as the first part of component %q", label, id),
+				StartPos: ast.StartPos(fn.Block()).Position(),
+				EndPos:   ast.EndPos(fn.Block()).Position(),
+			})
+			continue
+		}
+		if (&vm.Scope{}).IsStdlibIdentifiers(label) {
+			level.Warn(l.log).Log("msg", "a function is shadowing an existing stdlib name", "function", fn.NodeID(), "stdlib name", label)
+		}
+		functions[label] = fn.Value()
+	}
+
+	for label, importNode := range l.importConfigNodes {
+		if importedFunctions := importNode.Functions(); len(importedFunctions) > 0 {
+			functions[label] = importedFunctions
+		}
+	}
+
+	l.cache.CacheFunctions(functions)
+	return diags
+}
+
 // Variables returns the Variables the Loader exposes for other components to
 // reference.
 func (l *Loader) Variables() map[string]any {
@@ -762,6 +917,7 @@ func (l *Loader) EvaluateDependants(ctx context.Context, updatedNodes []*QueuedN
 		case *ImportConfigNode:
 			// Update the scope with the imported content.
 			l.componentNodeManager.customComponentReg.updateImportContent(parentNode)
+			l.cache.CacheImportedFunctions(parentNode.Label(), parentNode.Functions())
 		}
 		// We collect all nodes directly incoming to parent.
 		_ = dag.WalkIncomingNodes(l.graph, parent.Node, func(n dag.Node) error {
@@ -859,6 +1015,11 @@ func (l *Loader) concurrentEvalFn(n dag.Node, spanCtx context.Context, tracer tr
 		if exp, ok := n.(*ExportConfigNode); ok {
 			l.cache.CacheModuleExportValue(exp.Label(), exp.Value())
 		}
+		// The nodes calling a function must be re-evaluated when the function
+		// changes because one of its dependencies was updated.
+		if fn, ok := n.(*FunctionConfigNode); ok && evalErr == nil {
+			l.globals.OnBlockNodeUpdate(fn)
+		}
 		if l.globals.OnExportsChange != nil && l.cache.ExportChangeIndex() != l.moduleExportIndex {
 			// Upgrade to write lock to update the module exports.
 			l.mut.RUnlock()
@@ -923,6 +1084,7 @@ func (l *Loader) postEvaluate(logger log.Logger, bn BlockNode, err error) error
 		}
 	case *ImportConfigNode:
 		l.componentNodeManager.customComponentReg.updateImportContent(c)
+		l.cache.CacheImportedFunctions(c.Label(), c.Functions())
 	}
 
 	if err != nil {
@@ -182,9 +182,17 @@ func NewBuiltinComponentNode(globals ComponentGlobals, reg component.Registratio
 func getManagedOptions(globals ComponentGlobals, cn *BuiltinComponentNode) component.Options {
 	cn.registry = prometheus.NewRegistry()
 	parent, id := splitPath(cn.globalID)
+
+	// Use a logger which honors the level set for the component in the
+	// logging block, if any.
+	var logger log.Logger = globals.Logger
+	if globals.Logger != nil {
+		logger = globals.Logger.ComponentLogger(cn.globalID)
+	}
+
 	return component.Options{
 		ID:     cn.globalID,
-		Logger: log.With(globals.Logger, "component_path", parent, "component_id", id),
+		Logger: log.With(logger, "component_path", parent, "component_id", id),
 		Registerer: prometheus.WrapRegistererWith(prometheus.Labels{
 			"component_path": parent,
 			"component_id":   id,
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/nodeconf/foreach"
+	"github.com/grafana/alloy/internal/nodeconf/function"
 	"github.com/grafana/alloy/internal/nodeconf/importsource"
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/diag"
@@ -19,7 +20,8 @@ const (
 
 // Add config blocks that are not GA. Config blocks that are not specified here are considered GA.
 var configBlocksUnstable = map[string]featuregate.Stability{
-	foreach.BlockName: foreach.StabilityLevel,
+	foreach.BlockName:  foreach.StabilityLevel,
+	function.BlockName: function.StabilityLevel,
 }
 
 // NewConfigNode creates a new ConfigNode from an initial ast.BlockStmt.
@@ -50,6 +52,8 @@ func NewConfigNode(block *ast.BlockStmt, globals ComponentGlobals, customReg *Cu
 		return NewImportConfigNode(block, globals, importsource.GetSourceType(block.GetBlockName())), nil
 	case foreach.BlockName:
 		return NewForeachConfigNode(block, globals, customReg), nil
+	case function.BlockName:
+		return NewFunctionConfigNode(block, globals), nil
 	default:
 		diags.Add(diag.Diagnostic{
 			Severity: diag.SeverityLevelError,
@@ -79,6 +83,7 @@ type ConfigNodeMap struct {
 	exportMap   map[string]*ExportConfigNode
 	importMap   map[string]*ImportConfigNode
 	foreachMap  map[string]*ForeachConfigNode
+	functionMap map[string]*FunctionConfigNode
 }
 
 // NewConfigNodeMap will create an initial ConfigNodeMap. Append must be called
@@ -91,6 +96,7 @@ func NewConfigNodeMap() *ConfigNodeMap {
 		exportMap:   map[string]*ExportConfigNode{},
 		importMap:   map[string]*ImportConfigNode{},
 		foreachMap:  map[string]*ForeachConfigNode{},
+		functionMap: map[string]*FunctionConfigNode{},
 	}
 }
 
@@ -112,6 +118,8 @@ func (nodeMap *ConfigNodeMap) Append(configNode BlockNode) diag.Diagnostics {
 		nodeMap.importMap[n.Label()] = n
 	case *ForeachConfigNode:
 		nodeMap.foreachMap[n.Label()] = n
+	case *FunctionConfigNode:
+		nodeMap.functionMap[n.Label()] = n
 	default:
 		diags.Add(diag.Diagnostic{
 			Severity: diag.SeverityLevelError,
@@ -134,6 +142,9 @@ func (nodeMap *ConfigNodeMap) Validate(isInModule bool, args map[string]any) dia
 	newDiags = nodeMap.ValidateUnsupportedArguments(args)
 	diags = append(diags, newDiags...)
 
+	newDiags = nodeMap.ValidateFunctionNames()
+	diags = append(diags, newDiags...)
+
 	return diags
 }
 
@@ -201,3 +212,32 @@ func (nodeMap *ConfigNodeMap) ValidateUnsupportedArguments(args map[string]any)
 
 	return diags
 }
+
+// ValidateFunctionNames will make sure that the functions can be referenced
+// by their label without ambiguity.
+func (nodeMap *ConfigNodeMap) ValidateFunctionNames() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for label, fn := range nodeMap.functionMap {
+		var msg string
+		switch {
+		case label == "":
+			msg = "function blocks must have a label"
+		case label == argumentLabel:
+			msg = fmt.Sprintf("function name %q is reserved", label)
+		case nodeMap.importMap[label] != nil:
+			msg = fmt.Sprintf("function %q has the same name as an import block", label)
+		default:
+			continue
+		}
+
+		diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			Message:  msg,
+			StartPos: ast.StartPos(fn.Block()).Position(),
+			EndPos:   ast.EndPos(fn.Block()).Position(),
+		})
+	}
+
+	return diags
+}
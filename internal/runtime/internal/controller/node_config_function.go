@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/alloy/syntax/ast"
+	"github.com/grafana/alloy/syntax/vm"
+)
+
+// FunctionConfigNode represents a user-defined function. The function is
+// exposed to the other nodes of the module under its label.
+type FunctionConfigNode struct {
+	label         string
+	nodeID        string
+	componentName string
+
+	mut   sync.RWMutex
+	block *ast.BlockStmt // Current Alloy blocks to derive config from
+	def   *functionDefinition
+	scope *vm.Scope // Scope used to evaluate the return expression
+}
+
+var _ BlockNode = (*FunctionConfigNode)(nil)
+
+// NewFunctionConfigNode creates a new FunctionConfigNode from an initial ast.BlockStmt.
+// The underlying config isn't applied until Evaluate is called.
+func NewFunctionConfigNode(block *ast.BlockStmt, globals ComponentGlobals) *FunctionConfigNode {
+	return &FunctionConfigNode{
+		label:         block.Label,
+		nodeID:        BlockComponentID(block).String(),
+		componentName: block.GetBlockName(),
+
+		block: block,
+	}
+}
+
+// Evaluate implements BlockNode and updates the function definition by
+// parsing the Alloy block with the provided scope. The scope is kept to
+// evaluate the return expression when the function is called.
+func (cn *FunctionConfigNode) Evaluate(scope *vm.Scope) error {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+
+	def, err := parseFunction(cn.label, cn.block.Body, scope)
+	if err != nil {
+		return err
+	}
+	cn.def = def
+	cn.scope = scope
+	return nil
+}
+
+// Value returns the value exposed to Alloy expressions for this function.
+// The value stays the same across evaluations and always calls the latest
+// definition of the function.
+func (cn *FunctionConfigNode) Value() any {
+	return userFunction(cn.call)
+}
+
+func (cn *FunctionConfigNode) call(args ...any) (any, error) {
+	cn.mut.RLock()
+	def, scope := cn.def, cn.scope
+	cn.mut.RUnlock()
+
+	if def == nil {
+		return nil, fmt.Errorf("function %q has not been evaluated yet", cn.label)
+	}
+	return def.call(scope, args)
+}
+
+func (cn *FunctionConfigNode) Label() string { return cn.label }
+
+// Block implements BlockNode and returns the current block of the managed config node.
+func (cn *FunctionConfigNode) Block() *ast.BlockStmt {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.block
+}
+
+// NodeID implements dag.Node and returns the unique ID for the config node.
+func (cn *FunctionConfigNode) NodeID() string { return cn.nodeID }
+
+// UpdateBlock updates the Alloy block used to construct the function.
+// The new block isn't used until the next time Evaluate is invoked.
+//
+// UpdateBlock will panic if the block does not match the component ID of the
+// FunctionConfigNode.
+func (cn *FunctionConfigNode) UpdateBlock(b *ast.BlockStmt) {
+	if !BlockComponentID(b).Equals(strings.Split(cn.nodeID, ".")) {
+		panic("UpdateBlock called with an Alloy block with a different ID")
+	}
+
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+	cn.block = b
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/nodeconf/function"
 	"github.com/grafana/alloy/internal/nodeconf/importsource"
 	"github.com/grafana/alloy/internal/runner"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
@@ -26,8 +27,8 @@ import (
 	"github.com/grafana/alloy/syntax/vm"
 )
 
-// ImportConfigNode imports declare and import blocks via a managed import source.
-// The imported declare are stored in importedDeclares.
+// ImportConfigNode imports declare, function and import blocks via a managed import source.
+// The imported declare are stored in importedDeclares and the imported functions in importedFunctions.
 // For every imported import block, the ImportConfigNode will create ImportConfigNode children.
 // The children are evaluated and ran by the parent.
 // When an ImportConfigNode receives new content from its source, it updates its importedDeclares and recreates its children.
@@ -53,6 +54,8 @@ type ImportConfigNode struct {
 	importConfigNodesChildren map[string]*ImportConfigNode
 	importChildrenRunning     bool
 	importedDeclares          map[string]ast.Body
+	importedFunctions         map[string]*ast.BlockStmt
+	functionDefinitions       map[string]*functionDefinition
 
 	// NOTE: To avoid deadlocks, whenever we need both locks we must always first lock the mut, then healthMut.
 	healthMut     sync.RWMutex
@@ -212,6 +215,8 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 		cn.importedContent[k] = v
 	}
 	cn.importedDeclares = make(map[string]ast.Body)
+	cn.importedFunctions = make(map[string]*ast.BlockStmt)
+	cn.functionDefinitions = make(map[string]*functionDefinition)
 	cn.importConfigNodesChildren = make(map[string]*ImportConfigNode)
 
 	for f, ic := range importedContent {
@@ -231,8 +236,15 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 		}
 	}
 
+	err := cn.processImportedFunctions()
+	if err != nil {
+		level.Error(cn.logger).Log("msg", "failed to process imported functions", "err", err)
+		cn.setContentHealth(component.HealthTypeUnhealthy, fmt.Sprintf("imported functions are invalid: %s", err))
+		return
+	}
+
 	// evaluate the importConfigNodesChildren that have been created
-	err := cn.evaluateChildren()
+	err = cn.evaluateChildren()
 	if err != nil {
 		level.Error(cn.logger).Log("msg", "failed to evaluate nested import", "err", err)
 		cn.setContentHealth(component.HealthTypeUnhealthy, fmt.Sprintf("nested import block failed to evaluate: %s", err))
@@ -251,25 +263,30 @@ func (cn *ImportConfigNode) onContentUpdate(importedContent map[string]string) {
 	cn.OnBlockNodeUpdate(cn)
 }
 
-// processImportedContent processes declare and import blocks of the provided ast content.
+// processImportedContent processes declare, function and import blocks of the provided ast content.
 func (cn *ImportConfigNode) processImportedContent(content *ast.File) error {
 	for _, stmt := range content.Body {
 		blockStmt, ok := stmt.(*ast.BlockStmt)
 		if !ok {
-			return fmt.Errorf("only declare and import blocks are allowed in a module")
+			return fmt.Errorf("only declare, function and import blocks are allowed in a module")
 		}
 
 		componentName := strings.Join(blockStmt.Name, ".")
 		switch componentName {
 		case declareType:
 			cn.processDeclareBlock(blockStmt)
+		case function.BlockName:
+			err := cn.processFunctionBlock(blockStmt)
+			if err != nil {
+				return err
+			}
 		case importsource.BlockNameFile, importsource.BlockNameString, importsource.BlockNameHTTP, importsource.BlockNameGit:
 			err := cn.processImportBlock(blockStmt, componentName)
 			if err != nil {
 				return err
 			}
 		default:
-			return fmt.Errorf("only declare and import blocks are allowed in a module, got %s", componentName)
+			return fmt.Errorf("only declare, function and import blocks are allowed in a module, got %s", componentName)
 		}
 	}
 	return nil
@@ -284,7 +301,50 @@ func (cn *ImportConfigNode) processDeclareBlock(stmt *ast.BlockStmt) {
 	cn.importedDeclares[stmt.Label] = stmt.Body
 }
 
-// processDeclareBlock creates an ImportConfigNode child from the provided import block.
+// processFunctionBlock stores the function block in the importedFunctions.
+func (cn *ImportConfigNode) processFunctionBlock(stmt *ast.BlockStmt) error {
+	if err := checkFeatureStability(stmt.GetBlockName(), cn.globals.MinStability); err != nil {
+		return err
+	}
+	if stmt.Label == "" {
+		return fmt.Errorf("function blocks must have a label")
+	}
+	if _, ok := cn.importedFunctions[stmt.Label]; ok {
+		return fmt.Errorf("function block redefined %s", stmt.Label)
+	}
+	cn.importedFunctions[stmt.Label] = stmt
+	return nil
+}
+
+// processImportedFunctions parses the imported function blocks once all the
+// imported content has been processed. Functions calling each other in a loop
+// are rejected.
+func (cn *ImportConfigNode) processImportedFunctions() error {
+	if cycle := findFunctionCycle(cn.importedFunctions); cycle != nil {
+		return fmt.Errorf("recursive function calls are not allowed: %s", formatFunctionCycle(cycle))
+	}
+
+	scope := vm.NewScope(map[string]any{
+		importsource.ModulePath: cn.source.ModulePath(),
+	})
+	for name, block := range cn.importedFunctions {
+		if _, ok := cn.importedDeclares[name]; ok {
+			return fmt.Errorf("function %q has the same name as a declare block", name)
+		}
+		if _, ok := cn.importConfigNodesChildren[name]; ok {
+			return fmt.Errorf("function %q has the same name as an import block", name)
+		}
+
+		def, err := parseFunction(name, block.Body, scope)
+		if err != nil {
+			return err
+		}
+		cn.functionDefinitions[name] = def
+	}
+	return nil
+}
+
+// processImportBlock creates an ImportConfigNode child from the provided import block.
 func (cn *ImportConfigNode) processImportBlock(stmt *ast.BlockStmt, fullName string) error {
 	sourceType := importsource.GetSourceType(fullName)
 	if _, ok := cn.importConfigNodesChildren[stmt.Label]; ok {
@@ -434,11 +494,61 @@ func (cn *ImportConfigNode) ImportedDeclares() map[string]ast.Body {
 	return cn.importedDeclares
 }
 
-// Scope returns the scope associated with the import source.
+// Functions returns the functions that it imported. The returned functions
+// always call the latest imported definitions.
+func (cn *ImportConfigNode) Functions() map[string]any {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.functions()
+}
+
+// functions returns the imported functions. mut must be held when calling
+// functions.
+func (cn *ImportConfigNode) functions() map[string]any {
+	functions := make(map[string]any, len(cn.importedFunctions))
+	for name := range cn.importedFunctions {
+		functions[name] = userFunction(func(args ...any) (any, error) {
+			return cn.callFunction(name, args)
+		})
+	}
+	return functions
+}
+
+// callFunction calls the latest definition of an imported function.
+func (cn *ImportConfigNode) callFunction(name string, args []any) (any, error) {
+	cn.mut.RLock()
+	def, ok := cn.functionDefinitions[name]
+	var scope *vm.Scope
+	if ok {
+		scope = cn.scope()
+	}
+	cn.mut.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("function %q is not exported by import %q anymore", name, cn.label)
+	}
+	return def.call(scope, args)
+}
+
+// Scope returns the scope associated with the import source. It contains the
+// imported functions and the functions of the nested imports.
 func (cn *ImportConfigNode) Scope() *vm.Scope {
-	return vm.NewScope(map[string]any{
-		importsource.ModulePath: cn.source.ModulePath(),
-	})
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+	return cn.scope()
+}
+
+// scope returns the scope associated with the import source. mut must be held
+// when calling scope.
+func (cn *ImportConfigNode) scope() *vm.Scope {
+	variables := cn.functions()
+	for label, child := range cn.importConfigNodesChildren {
+		if childFunctions := child.Functions(); len(childFunctions) > 0 {
+			variables[label] = childFunctions
+		}
+	}
+	variables[importsource.ModulePath] = cn.source.ModulePath()
+	return vm.NewScope(variables)
 }
 
 // ImportConfigNodesChildren returns the ImportConfigNodesChildren of this ImportConfigNode.
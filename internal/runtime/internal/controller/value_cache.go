@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"maps"
 	"sync"
 
 	"github.com/grafana/alloy/internal/component"
@@ -21,6 +22,7 @@ type valueCache struct {
 	moduleExports      map[string]any         // Export label -> Export value
 	moduleArguments    map[string]any         // Argument label -> Map with the key "value" that points to the Argument value
 	moduleChangedIndex int                    // Everytime a change occurs this is incremented
+	functions          map[string]any         // Function name or import namespace -> function or map of functions
 	scope              *vm.Scope              // scope provides additional context for the nodes in the module
 }
 
@@ -30,6 +32,7 @@ func newValueCache() *valueCache {
 		componentIds:    make(map[string]ComponentID, 0),
 		moduleExports:   make(map[string]any),
 		moduleArguments: make(map[string]any),
+		functions:       make(map[string]any),
 		scope:           vm.NewScope(make(map[string]any)),
 	}
 }
@@ -70,6 +73,28 @@ func (vc *valueCache) CacheExports(id ComponentID, exports component.Exports) er
 	return nil
 }
 
+// CacheFunctions replaces the cached user-defined functions. The keys of the
+// map are either function names or import namespaces, in which case the value
+// is a map of the functions exported by the import.
+func (vc *valueCache) CacheFunctions(functions map[string]any) {
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+	vc.functions = functions
+}
+
+// CacheImportedFunctions updates the functions exported by an import. Imports
+// without functions aren't exposed so that they don't shadow anything.
+func (vc *valueCache) CacheImportedFunctions(namespace string, functions map[string]any) {
+	vc.mut.Lock()
+	defer vc.mut.Unlock()
+
+	if len(functions) == 0 {
+		delete(vc.functions, namespace)
+		return
+	}
+	vc.functions[namespace] = functions
+}
+
 func (vc *valueCache) GetModuleArgument(key string) (any, bool) {
 	vc.mut.RLock()
 	defer vc.mut.RUnlock()
@@ -215,6 +240,22 @@ func (vc *valueCache) GetContext() *vm.Scope {
 		vars[argumentLabel] = deepCopyMap(vc.moduleArguments)
 	}
 
+	// Add user-defined functions. The functions exported by an import share
+	// their namespace with the custom components instantiated from it.
+	for name, fn := range vc.functions {
+		imported, ok := fn.(map[string]any)
+		if !ok {
+			vars[name] = fn
+			continue
+		}
+		namespace, ok := vars[name].(map[string]any)
+		if !ok {
+			namespace = make(map[string]any, len(imported))
+			vars[name] = namespace
+		}
+		maps.Copy(namespace, imported)
+	}
+
 	return vm.NewScope(vars)
 }
 
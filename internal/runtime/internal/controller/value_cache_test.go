@@ -237,6 +237,41 @@ func TestScopeOverride(t *testing.T) {
 	require.Equal(t, expected, res.Variables)
 }
 
+func TestFunctionCache(t *testing.T) {
+	vc := newValueCache()
+	require.NoError(t, vc.CacheExports(ComponentID{"lib", "negation", "default"}, barArgs{Number: 12}))
+
+	// Strings are used instead of actual functions so that the values can be compared.
+	vc.CacheFunctions(map[string]any{"double": "double"})
+	vc.CacheImportedFunctions("lib", map[string]any{"negate": "negate"})
+	res := vc.GetContext()
+
+	expected := map[string]any{
+		"double": "double",
+		"lib": map[string]any{
+			"negate": "negate",
+			"negation": map[string]any{
+				"default": barArgs{Number: 12},
+			},
+		},
+	}
+	require.Equal(t, expected, res.Variables)
+
+	// Imports without functions aren't exposed.
+	vc.CacheImportedFunctions("lib", map[string]any{})
+	res = vc.GetContext()
+
+	expected = map[string]any{
+		"double": "double",
+		"lib": map[string]any{
+			"negation": map[string]any{
+				"default": barArgs{Number: 12},
+			},
+		},
+	}
+	require.Equal(t, expected, res.Variables)
+}
+
 func TestScopePathOverrideError(t *testing.T) {
 	vc := newValueCache()
 	vc.scope = vm.NewScope(
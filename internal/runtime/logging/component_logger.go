@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/go-kit/log"
+)
+
+// ComponentLogger returns a logger for the component with the given ID. Its
+// logs are filtered using the level set for the component in the
+// component_levels argument, or using the level of l if there is none. The
+// returned logger remains valid if l is updated.
+func (l *Logger) ComponentLogger(id string) log.Logger {
+	return &componentLogger{
+		l: l,
+		h: &handler{
+			w: l.writer,
+			leveler: &componentLeveler{
+				id:       id,
+				levels:   l.componentLevels,
+				fallback: l.level,
+			},
+			formatter: l.format,
+			replacer:  replace,
+		},
+	}
+}
+
+type componentLogger struct {
+	l *Logger
+	h *handler
+}
+
+var (
+	_ log.Logger   = (*componentLogger)(nil)
+	_ EnabledAware = (*componentLogger)(nil)
+)
+
+// Log implements log.Logger.
+func (c *componentLogger) Log(kvps ...any) error {
+	return c.l.log(c.h, kvps...)
+}
+
+// Enabled implements EnabledAware interface.
+func (c *componentLogger) Enabled(ctx context.Context, level slog.Level) bool {
+	return c.h.Enabled(ctx, level)
+}
+
+// componentLeveler is the slog.Leveler of a single component.
+type componentLeveler struct {
+	id       string
+	levels   *componentLevelsVar
+	fallback slog.Leveler
+}
+
+// Level implements slog.Leveler.
+func (cl *componentLeveler) Level() slog.Level {
+	if level, ok := cl.levels.Get(cl.id); ok {
+		return level
+	}
+	return cl.fallback.Level()
+}
+
+type componentLevelsVar struct {
+	mut    sync.RWMutex
+	levels map[string]slog.Level
+}
+
+func (v *componentLevelsVar) Get(id string) (slog.Level, bool) {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+	level, ok := v.levels[id]
+	return level, ok
+}
+
+func (v *componentLevelsVar) Set(levels map[string]Level) {
+	converted := make(map[string]slog.Level, len(levels))
+	for id, level := range levels {
+		converted[id] = slogLevel(level).Level()
+	}
+
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.levels = converted
+}
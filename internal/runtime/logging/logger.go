@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/prometheus/common/model"
+	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/slogadapter"
@@ -28,11 +30,13 @@ type Logger struct {
 	buffer       []*bufferedItem // Store logs before correctly determine the log format
 	hasLogFormat bool            // Confirmation whether log format has been determined
 
-	level        *slog.LevelVar       // Current configured level.
-	format       *formatVar           // Current configured format.
-	writer       *writerVar           // Current configured multiwriter (inner + write_to).
-	handler      *handler             // Handler which handles logs.
-	deferredSlog *deferredSlogHandler // This handles deferred logging for slog.
+	level           *slog.LevelVar       // Current configured level.
+	componentLevels *componentLevelsVar  // Current configured per-component levels.
+	format          *formatVar           // Current configured format.
+	writer          *writerVar           // Current configured multiwriter (inner + file + write_to).
+	fileOptions     *FileOptions         // Options of the current file writer, if any.
+	handler         *handler             // Handler which handles logs.
+	deferredSlog    *deferredSlogHandler // This handles deferred logging for slog.
 }
 
 var _ EnabledAware = (*Logger)(nil)
@@ -75,9 +79,10 @@ func NewDeferred(w io.Writer) (*Logger, error) {
 		buffer:       []*bufferedItem{},
 		hasLogFormat: false,
 
-		level:  &leveler,
-		format: &format,
-		writer: &writer,
+		level:           &leveler,
+		componentLevels: &componentLevelsVar{},
+		format:          &format,
+		writer:          &writer,
 		handler: &handler{
 			w:         &writer,
 			leveler:   &leveler,
@@ -107,9 +112,11 @@ func (l *Logger) Update(o Options) error {
 	}
 
 	l.level.Set(slogLevel(o.Level).Level())
+	l.componentLevels.Set(o.ComponentLevels)
 	l.format.Set(o.Format)
 
 	l.writer.SetInnerWriter(l.inner)
+	l.updateFileWriter(o.File)
 	if len(o.WriteTo) > 0 {
 		l.writer.SetLokiWriter(&lokiWriter{o.WriteTo})
 	}
@@ -123,7 +130,7 @@ func (l *Logger) Update(o Options) error {
 		if len(bufferedLogChunk.kvps) > 0 {
 			// the buffered logs are currently only sent to the standard output
 			// because the components with the receivers are not running yet
-			slogadapter.GoKit(l.handler).Log(bufferedLogChunk.kvps...)
+			slogadapter.GoKit(bufferedLogChunk.logHandler).Log(bufferedLogChunk.kvps...)
 		} else {
 			// We now can check to see if if our buffered log is at the right level.
 			if bufferedLogChunk.handler.Enabled(context.Background(), bufferedLogChunk.record.Level) {
@@ -137,6 +144,35 @@ func (l *Logger) Update(o Options) error {
 	return nil
 }
 
+// updateFileWriter replaces the file writer if its options changed.
+func (l *Logger) updateFileWriter(o *FileOptions) {
+	if o == nil && l.fileOptions == nil {
+		return
+	}
+	if o != nil && l.fileOptions != nil && *o == *l.fileOptions {
+		return
+	}
+
+	var fw *lumberjack.Logger
+	if o != nil {
+		fw = &lumberjack.Logger{
+			Filename:   o.Path,
+			MaxSize:    int(o.MaxSize / units.MiB),
+			MaxAge:     int(o.MaxAge / (24 * time.Hour)),
+			MaxBackups: o.MaxBackups,
+			Compress:   o.Compress,
+		}
+		fileOptions := *o
+		l.fileOptions = &fileOptions
+	} else {
+		l.fileOptions = nil
+	}
+
+	if old := l.writer.SetFileWriter(fw); old != nil {
+		_ = old.Close()
+	}
+}
+
 func (l *Logger) SetTemporaryWriter(w io.Writer) {
 	l.writer.SetTemporaryWriter(w)
 }
@@ -147,6 +183,11 @@ func (l *Logger) RemoveTemporaryWriter() {
 
 // Log implements log.Logger.
 func (l *Logger) Log(kvps ...any) error {
+	return l.log(l.handler, kvps...)
+}
+
+// log writes kvps to h, which must share the writer and format of l.
+func (l *Logger) log(h *handler, kvps ...any) error {
 	// Buffer logs before confirming log format is configured in `logging` block
 	l.bufferMut.RLock()
 	if !l.hasLogFormat {
@@ -154,7 +195,7 @@ func (l *Logger) Log(kvps ...any) error {
 		l.bufferMut.Lock()
 		// Check hasLogFormat again; could have changed since the unlock.
 		if !l.hasLogFormat {
-			l.buffer = append(l.buffer, &bufferedItem{kvps: kvps})
+			l.buffer = append(l.buffer, &bufferedItem{kvps: kvps, logHandler: h})
 			l.bufferMut.Unlock()
 			return nil
 		}
@@ -165,7 +206,7 @@ func (l *Logger) Log(kvps ...any) error {
 
 	// NOTE(rfratto): this method is a temporary shim while log/slog is still
 	// being adopted throughout the codebase.
-	return slogadapter.GoKit(l.handler).Log(kvps...)
+	return slogadapter.GoKit(h).Log(kvps...)
 }
 
 func (l *Logger) addRecord(r slog.Record, df *deferredSlogHandler) {
@@ -230,6 +271,7 @@ type writerVar struct {
 
 	lokiWriter  *lokiWriter
 	innerWriter io.Writer
+	fileWriter  *lumberjack.Logger
 	tmpWriter   io.Writer
 }
 
@@ -251,6 +293,16 @@ func (w *writerVar) SetInnerWriter(writer io.Writer) {
 	w.innerWriter = writer
 }
 
+// SetFileWriter sets the file writer and returns the previous one, which the
+// caller must close.
+func (w *writerVar) SetFileWriter(writer *lumberjack.Logger) *lumberjack.Logger {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	old := w.fileWriter
+	w.fileWriter = writer
+	return old
+}
+
 func (w *writerVar) SetLokiWriter(writer *lokiWriter) {
 	w.mut.Lock()
 	defer w.mut.Unlock()
@@ -271,6 +323,12 @@ func (w *writerVar) Write(p []byte) (int, error) {
 		return 0, err
 	}
 
+	if w.fileWriter != nil {
+		if _, err := w.fileWriter.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
 	if w.lokiWriter != nil {
 		if _, err := w.lokiWriter.Write(p); err != nil {
 			return 0, err
@@ -287,7 +345,8 @@ func (w *writerVar) Write(p []byte) (int, error) {
 }
 
 type bufferedItem struct {
-	kvps    []any
-	handler *deferredSlogHandler
-	record  slog.Record
+	kvps       []any
+	logHandler *handler // Handler for kvps.
+	handler    *deferredSlogHandler
+	record     slog.Record
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/runtime/logging"
 	alloylevel "github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax"
 	"github.com/stretchr/testify/require"
 )
 
@@ -185,6 +188,76 @@ func Test_lokiWriter_nil(t *testing.T) {
 	})
 }
 
+func TestComponentLevels(t *testing.T) {
+	var buffer bytes.Buffer
+	opts := infoLevel()
+	opts.ComponentLevels = map[string]logging.Level{"loki.source.file.app": logging.LevelDebug}
+	logger, err := logging.New(&buffer, opts)
+	require.NoError(t, err)
+
+	noisy := logger.ComponentLogger("loki.source.file.app")
+	other := logger.ComponentLogger("loki.source.file.other")
+
+	gokitlevel.Debug(noisy).Log("msg", "noisy debug")
+	gokitlevel.Debug(other).Log("msg", "other debug")
+	gokitlevel.Info(other).Log("msg", "other info")
+	require.Contains(t, buffer.String(), "noisy debug")
+	require.NotContains(t, buffer.String(), "other debug")
+	require.Contains(t, buffer.String(), "other info")
+
+	// Existing component loggers pick up updated levels.
+	buffer.Reset()
+	require.NoError(t, logger.Update(warnLevel()))
+	gokitlevel.Info(noisy).Log("msg", "noisy info")
+	require.Empty(t, buffer.String())
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "alloy.log")
+
+	opts := infoLevel()
+	opts.File = &logging.FileOptions{}
+	opts.File.SetToDefault()
+	opts.File.Path = path
+
+	var buffer bytes.Buffer
+	logger, err := logging.New(&buffer, opts)
+	require.NoError(t, err)
+	logger.Log("msg", "hello")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "msg=hello")
+	require.Contains(t, buffer.String(), "msg=hello")
+
+	// Logs are no longer written to the file once the block is removed.
+	require.NoError(t, logger.Update(infoLevel()))
+	logger.Log("msg", "goodbye")
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "goodbye")
+}
+
+func TestFileOptions_Validate(t *testing.T) {
+	var opts logging.Options
+	err := syntax.Unmarshal([]byte(`
+		file {
+			path     = "/var/log/alloy.log"
+			max_size = "512KiB"
+		}
+	`), &opts)
+	require.ErrorContains(t, err, "max_size must be at least 1MiB")
+
+	err = syntax.Unmarshal([]byte(`
+		file {
+			path    = "/var/log/alloy.log"
+			max_age = "1h"
+		}
+	`), &opts)
+	require.ErrorContains(t, err, "max_age must be 0 or at least 24h")
+}
+
 func BenchmarkLogging_NoLevel_Prints(b *testing.B) {
 	logger, err := logging.New(io.Discard, infoLevel())
 	require.NoError(b, err)
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"time"
+
+	"github.com/alecthomas/units"
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/syntax"
@@ -16,6 +19,11 @@ type Options struct {
 	Format Format `alloy:"format,attr,optional"`
 
 	WriteTo []loki.LogsReceiver `alloy:"write_to,attr,optional"`
+
+	// ComponentLevels overrides Level for the components with the given IDs.
+	ComponentLevels map[string]Level `alloy:"component_levels,attr,optional"`
+
+	File *FileOptions `alloy:"file,block,optional"`
 }
 
 // DefaultOptions holds defaults for creating a Logger.
@@ -31,6 +39,61 @@ func (o *Options) SetToDefault() {
 	*o = DefaultOptions
 }
 
+var _ syntax.Validator = (*Options)(nil)
+
+// Validate implements syntax.Validator.
+func (o *Options) Validate() error {
+	for id := range o.ComponentLevels {
+		if id == "" {
+			return fmt.Errorf("component_levels must not contain an empty component ID")
+		}
+	}
+	return nil
+}
+
+// FileOptions configures writing logs to a file, which is rotated once it
+// reaches a maximum size.
+type FileOptions struct {
+	Path       string           `alloy:"path,attr"`
+	MaxSize    units.Base2Bytes `alloy:"max_size,attr,optional"`
+	MaxAge     time.Duration    `alloy:"max_age,attr,optional"`
+	MaxBackups int              `alloy:"max_backups,attr,optional"`
+	Compress   bool             `alloy:"compress,attr,optional"`
+}
+
+// DefaultFileOptions holds defaults for FileOptions.
+var DefaultFileOptions = FileOptions{
+	MaxSize:    100 * units.MiB,
+	MaxBackups: 10,
+}
+
+var (
+	_ syntax.Defaulter = (*FileOptions)(nil)
+	_ syntax.Validator = (*FileOptions)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (o *FileOptions) SetToDefault() {
+	*o = DefaultFileOptions
+}
+
+// Validate implements syntax.Validator.
+func (o *FileOptions) Validate() error {
+	if o.Path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if o.MaxSize < units.MiB {
+		return fmt.Errorf("max_size must be at least 1MiB")
+	}
+	if o.MaxAge != 0 && o.MaxAge < 24*time.Hour {
+		return fmt.Errorf("max_age must be 0 or at least 24h")
+	}
+	if o.MaxBackups < 0 {
+		return fmt.Errorf("max_backups must not be negative")
+	}
+	return nil
+}
+
 // Level represents how verbose logging should be.
 type Level string
 
@@ -100,9 +100,10 @@ type Service struct {
 	tracer trace.TracerProvider
 	opts   Options
 
-	sharder shard.Sharder
-	node    *ckit.Node
-	randGen *rand.Rand
+	sharder    shard.Sharder
+	node       *ckit.Node
+	httpClient *http.Client
+	randGen    *rand.Rand
 
 	// alloyCluster is given to components via calls to Data() and implements Cluster.
 	alloyCluster *alloyCluster
@@ -201,6 +202,7 @@ func New(opts Options) (*Service, error) {
 
 		sharder:             ckitConfig.Sharder,
 		node:                node,
+		httpClient:          httpClient,
 		randGen:             rand.New(rand.NewSource(time.Now().UnixNano())),
 		notifyClusterChange: make(chan struct{}, 1),
 	}
@@ -500,6 +502,12 @@ func (s *Service) stop() {
 	}
 }
 
+// PeerClient returns the HTTP client used to reach the HTTP servers of peers,
+// and whether it authenticates them with the cluster TLS settings.
+func (s *Service) PeerClient() (client *http.Client, tls bool) {
+	return s.httpClient, s.opts.EnableTLS
+}
+
 // Update implements [service.Service]. It returns an error since the cluster
 // service does not support runtime configuration.
 func (s *Service) Update(_ any) error {
@@ -0,0 +1,422 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// Resolver resolves the fields of a GraphQL object type.
+//
+// Resolve is called once for every field requested on the object. Values
+// returned by Resolve must be one of:
+//
+//   - nil, for null values.
+//   - Another Resolver, for fields of object types.
+//   - A slice of any of the supported values, for fields of list types.
+//   - A bool, string, integer or floating point number for scalar and enum
+//     fields.
+//   - A [time.Time] for fields of the Time scalar.
+//   - A [json.RawMessage] for fields of the JSON scalar.
+type Resolver interface {
+	Resolve(ctx context.Context, field *ast.Field, args map[string]any) (any, error)
+}
+
+// SubscriptionResolver resolves the root fields of the Subscription type.
+//
+// Subscribe returns a channel of events for the requested field. Each event is
+// resolved as the value of the field, following the same rules as the values
+// returned by [Resolver]. The channel must be closed once ctx is canceled or
+// when there are no more events to send.
+type SubscriptionResolver interface {
+	Subscribe(ctx context.Context, field *ast.Field, args map[string]any) (<-chan any, error)
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of executing a GraphQL request.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors gqlerror.List   `json:"errors,omitempty"`
+}
+
+// Executor executes GraphQL requests against a schema.
+type Executor struct {
+	schema *ast.Schema
+}
+
+// NewExecutor returns a new Executor for the embedded schema.
+func NewExecutor() (*Executor, error) {
+	schema, err := LoadSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &Executor{schema: schema}, nil
+}
+
+// Schema returns the schema used by the Executor.
+func (e *Executor) Schema() *ast.Schema { return e.schema }
+
+// Prepare parses and validates a request, returning the operation to run
+// along with its coerced variables.
+func (e *Executor) Prepare(req Request) (*ast.OperationDefinition, map[string]any, gqlerror.List) {
+	doc, errs := gqlparser.LoadQuery(e.schema, req.Query)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	op := doc.Operations.ForName(req.OperationName)
+	if op == nil {
+		if req.OperationName == "" {
+			return nil, nil, gqlerror.List{gqlerror.Errorf("operation name is required when the document contains multiple operations")}
+		}
+		return nil, nil, gqlerror.List{gqlerror.Errorf("operation %q not found", req.OperationName)}
+	}
+
+	vars, err := validator.VariableValues(e.schema, op, req.Variables)
+	if err != nil {
+		var gqlErr *gqlerror.Error
+		if errors.As(err, &gqlErr) {
+			return nil, nil, gqlerror.List{gqlErr}
+		}
+		return nil, nil, gqlerror.List{gqlerror.Errorf("%s", err)}
+	}
+
+	return op, vars, nil
+}
+
+// Execute runs a query operation from req against root.
+func (e *Executor) Execute(ctx context.Context, root Resolver, req Request) *Response {
+	op, vars, errs := e.Prepare(req)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if op.Operation != ast.Query {
+		return &Response{Errors: gqlerror.List{gqlerror.Errorf("%s operations are not supported by this endpoint", op.Operation)}}
+	}
+
+	return e.ExecuteSelection(ctx, root, op.SelectionSet, vars)
+}
+
+// Subscribe runs a subscription operation from req against root. The returned
+// channel receives a response for every event of the subscription, and is
+// closed when ctx is canceled or the subscription ends.
+func (e *Executor) Subscribe(ctx context.Context, root SubscriptionResolver, req Request) (<-chan *Response, gqlerror.List) {
+	op, vars, errs := e.Prepare(req)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if op.Operation != ast.Subscription {
+		return nil, gqlerror.List{gqlerror.Errorf("expected a subscription operation, got %s", op.Operation)}
+	}
+
+	// Validation guarantees that subscriptions have a single root field.
+	fields := CollectFields(op.SelectionSet, vars)
+	if len(fields) != 1 {
+		return nil, gqlerror.List{gqlerror.Errorf("subscriptions must select exactly one top level field")}
+	}
+	field := fields[0]
+
+	ctx = context.WithValue(ctx, varsContextKey{}, vars)
+	events, err := root.Subscribe(ctx, field, field.ArgumentMap(vars))
+	if err != nil {
+		return nil, gqlerror.List{&gqlerror.Error{Message: err.Error(), Path: ast.Path{ast.PathName(field.Alias)}}}
+	}
+
+	out := make(chan *Response)
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+
+				resp := e.ExecuteSelection(ctx, valueResolver{value: ev}, ast.SelectionSet{field}, vars)
+				select {
+				case out <- resp:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// valueResolver resolves any field to a fixed value. It's used to resolve the
+// events of a subscription.
+type valueResolver struct {
+	value any
+}
+
+func (r valueResolver) Resolve(context.Context, *ast.Field, map[string]any) (any, error) {
+	return r.value, nil
+}
+
+// ExecuteSelection resolves a selection set against root.
+func (e *Executor) ExecuteSelection(ctx context.Context, root Resolver, sel ast.SelectionSet, vars map[string]any) *Response {
+	ex := &execution{schema: e.schema, vars: vars}
+	ctx = context.WithValue(ctx, varsContextKey{}, vars)
+	data, _ := ex.resolveObject(ctx, root, sel, nil)
+
+	bb, err := json.Marshal(data)
+	if err != nil {
+		ex.errs = append(ex.errs, gqlerror.Errorf("failed to encode response: %s", err))
+		return &Response{Errors: ex.errs}
+	}
+	return &Response{Data: bb, Errors: ex.errs}
+}
+
+// errNullPropagation is returned when a non-null field resolved to null and
+// the null value must be propagated to the parent field.
+var errNullPropagation = errors.New("null value in non-null field")
+
+type execution struct {
+	schema *ast.Schema
+	vars   map[string]any
+	errs   gqlerror.List
+}
+
+func (ex *execution) resolveObject(ctx context.Context, obj Resolver, sel ast.SelectionSet, path ast.Path) (*orderedMap, error) {
+	fields := CollectFields(sel, ex.vars)
+	out := &orderedMap{}
+
+	for _, field := range fields {
+		fieldPath := append(append(ast.Path{}, path...), ast.PathName(field.Alias))
+
+		if field.Name == "__typename" {
+			out.Set(field.Alias, field.ObjectDefinition.Name)
+			continue
+		}
+
+		val, err := obj.Resolve(ctx, field, field.ArgumentMap(ex.vars))
+		if err != nil {
+			ex.errs = append(ex.errs, &gqlerror.Error{Message: err.Error(), Path: fieldPath})
+			val = nil
+		}
+
+		res, err := ex.completeValue(ctx, field, field.Definition.Type, val, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		out.Set(field.Alias, res)
+	}
+
+	return out, nil
+}
+
+func (ex *execution) completeValue(ctx context.Context, field *ast.Field, typ *ast.Type, val any, path ast.Path) (any, error) {
+	if isNil(val) {
+		if typ.NonNull {
+			return nil, errNullPropagation
+		}
+		return nil, nil
+	}
+
+	res, err := ex.completeNonNull(ctx, field, typ, val, path)
+	if errors.Is(err, errNullPropagation) && !typ.NonNull {
+		return nil, nil
+	}
+	return res, err
+}
+
+func (ex *execution) completeNonNull(ctx context.Context, field *ast.Field, typ *ast.Type, val any, path ast.Path) (any, error) {
+	if typ.Elem != nil {
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			ex.errs = append(ex.errs, &gqlerror.Error{Message: fmt.Sprintf("expected a list, got %T", val), Path: path})
+			return nil, errNullPropagation
+		}
+
+		out := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elemPath := append(append(ast.Path{}, path...), ast.PathIndex(i))
+			res, err := ex.completeValue(ctx, field, typ.Elem, rv.Index(i).Interface(), elemPath)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, res)
+		}
+		return out, nil
+	}
+
+	def := ex.schema.Types[typ.NamedType]
+	if def == nil {
+		ex.errs = append(ex.errs, &gqlerror.Error{Message: fmt.Sprintf("unknown type %q", typ.NamedType), Path: path})
+		return nil, errNullPropagation
+	}
+
+	switch def.Kind {
+	case ast.Object:
+		obj, ok := val.(Resolver)
+		if !ok {
+			ex.errs = append(ex.errs, &gqlerror.Error{Message: fmt.Sprintf("expected an object, got %T", val), Path: path})
+			return nil, errNullPropagation
+		}
+		res, err := ex.resolveObject(ctx, obj, field.SelectionSet, path)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+
+	case ast.Scalar, ast.Enum:
+		switch v := val.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		case json.RawMessage:
+			if len(v) == 0 {
+				return nil, nil
+			}
+			return v, nil
+		default:
+			return v, nil
+		}
+
+	default:
+		ex.errs = append(ex.errs, &gqlerror.Error{Message: fmt.Sprintf("unsupported type kind %s for %q", def.Kind, typ.NamedType), Path: path})
+		return nil, errNullPropagation
+	}
+}
+
+// CollectFields flattens a selection set into the list of fields to resolve,
+// expanding fragments and honoring the @skip and @include directives. Fields
+// with the same response name are merged.
+func CollectFields(sel ast.SelectionSet, vars map[string]any) []*ast.Field {
+	var (
+		fields []*ast.Field
+		seen   = map[string]*ast.Field{}
+	)
+
+	var collect func(sel ast.SelectionSet)
+	collect = func(sel ast.SelectionSet) {
+		for _, s := range sel {
+			switch s := s.(type) {
+			case *ast.Field:
+				if !shouldInclude(s.Directives, vars) {
+					continue
+				}
+				if prev, ok := seen[s.Alias]; ok {
+					// Merge sub-selections of fields requested multiple times.
+					prev.SelectionSet = append(append(ast.SelectionSet{}, prev.SelectionSet...), s.SelectionSet...)
+					continue
+				}
+				f := *s
+				seen[s.Alias] = &f
+				fields = append(fields, &f)
+			case *ast.InlineFragment:
+				if shouldInclude(s.Directives, vars) {
+					collect(s.SelectionSet)
+				}
+			case *ast.FragmentSpread:
+				if shouldInclude(s.Directives, vars) && s.Definition != nil {
+					collect(s.Definition.SelectionSet)
+				}
+			}
+		}
+	}
+	collect(sel)
+
+	return fields
+}
+
+type varsContextKey struct{}
+
+// RequestedFields returns the set of field names selected under field by the
+// request being executed with ctx.
+func RequestedFields(ctx context.Context, field *ast.Field) map[string]struct{} {
+	vars, _ := ctx.Value(varsContextKey{}).(map[string]any)
+
+	names := map[string]struct{}{}
+	for _, f := range CollectFields(field.SelectionSet, vars) {
+		names[f.Name] = struct{}{}
+	}
+	return names
+}
+
+func shouldInclude(directives ast.DirectiveList, vars map[string]any) bool {
+	if d := directives.ForName("skip"); d != nil {
+		if v, _ := d.ArgumentMap(vars)["if"].(bool); v {
+			return false
+		}
+	}
+	if d := directives.ForName("include"); d != nil {
+		if v, _ := d.ArgumentMap(vars)["if"].(bool); !v {
+			return false
+		}
+	}
+	return true
+}
+
+func isNil(val any) bool {
+	if val == nil {
+		return true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// orderedMap is a JSON object which preserves insertion order, so responses
+// follow the order of the fields in the request.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) Set(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+
+		vb, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
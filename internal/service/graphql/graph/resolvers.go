@@ -0,0 +1,222 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/syntax/encoding/alloyjson"
+)
+
+// QueryResolver resolves the fields of the root Query type.
+type QueryResolver struct {
+	Host      service.Host
+	ReadyFunc func() bool
+}
+
+var _ Resolver = (*QueryResolver)(nil)
+
+// Resolve implements [Resolver].
+func (r *QueryResolver) Resolve(ctx context.Context, field *ast.Field, args map[string]any) (any, error) {
+	switch field.Name {
+	case "alloy":
+		return &alloyResolver{ready: r.ReadyFunc}, nil
+
+	case "components":
+		return r.components(ctx, field, args)
+
+	case "component":
+		id, _ := args["id"].(string)
+		info, err := r.Host.GetComponent(component.ParseID(id), infoOptions(RequestedFields(ctx, field)))
+		if errors.Is(err, component.ErrComponentNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return &componentResolver{info: info}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown field %q on Query", field.Name)
+	}
+}
+
+func (r *QueryResolver) components(ctx context.Context, field *ast.Field, args map[string]any) (any, error) {
+	var (
+		moduleID, _  = args["moduleID"].(string)
+		recursive, _ = args["recursive"].(bool)
+		name, _      = args["name"].(string)
+		health, _    = args["health"].(string)
+	)
+
+	opts := infoOptions(RequestedFields(ctx, field))
+	if health != "" {
+		opts.GetHealth = true
+	}
+
+	infos, err := r.Host.ListComponents(moduleID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if recursive {
+		var nested []*component.Info
+		for _, info := range infos {
+			nested = append(nested, info)
+			for _, module := range info.ModuleIDs {
+				nested = append(nested, listAllComponents(r.Host, module, opts)...)
+			}
+		}
+		infos = nested
+	}
+
+	res := make([]Resolver, 0, len(infos))
+	for _, info := range infos {
+		if name != "" && info.ComponentName != name {
+			continue
+		}
+		if health != "" && healthState(info.Health.Health) != health {
+			continue
+		}
+		res = append(res, &componentResolver{info: info})
+	}
+	return res, nil
+}
+
+// listAllComponents returns the components of moduleID and all the modules
+// nested in it. Modules which disappear while being listed are ignored.
+func listAllComponents(host service.Host, moduleID string, opts component.InfoOptions) []*component.Info {
+	infos, err := host.ListComponents(moduleID, opts)
+	if err != nil {
+		return nil
+	}
+
+	res := make([]*component.Info, 0, len(infos))
+	for _, info := range infos {
+		res = append(res, info)
+		for _, module := range info.ModuleIDs {
+			res = append(res, listAllComponents(host, module, opts)...)
+		}
+	}
+	return res
+}
+
+// infoOptions returns the InfoOptions needed to resolve the requested
+// Component fields. Arguments, exports and debug info can be expensive to
+// compute, so they are only retrieved when requested.
+func infoOptions(requested map[string]struct{}) component.InfoOptions {
+	has := func(name string) bool {
+		_, ok := requested[name]
+		return ok
+	}
+
+	return component.InfoOptions{
+		GetHealth:    has("health"),
+		GetArguments: has("arguments"),
+		GetExports:   has("exports"),
+		GetDebugInfo: has("debugInfo"),
+	}
+}
+
+type alloyResolver struct {
+	ready func() bool
+}
+
+func (r *alloyResolver) Resolve(_ context.Context, field *ast.Field, _ map[string]any) (any, error) {
+	switch field.Name {
+	case "branch":
+		return build.Branch, nil
+	case "buildDate":
+		return build.BuildDate, nil
+	case "buildUser":
+		return build.BuildUser, nil
+	case "isReady":
+		return r.ready != nil && r.ready(), nil
+	case "revision":
+		return build.Revision, nil
+	case "version":
+		return build.Version, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q on Alloy", field.Name)
+	}
+}
+
+type componentResolver struct {
+	info *component.Info
+}
+
+func (r *componentResolver) Resolve(_ context.Context, field *ast.Field, _ map[string]any) (any, error) {
+	info := r.info
+
+	switch field.Name {
+	case "arguments":
+		return marshalBody(info.Arguments)
+	case "dataFlowEdgesTo":
+		return info.DataFlowEdgesTo, nil
+	case "debugInfo":
+		return marshalBody(info.DebugInfo)
+	case "exports":
+		return marshalBody(info.Exports)
+	case "health":
+		return &healthResolver{health: info.Health}, nil
+	case "id":
+		return info.ID.String(), nil
+	case "label":
+		if info.Label == "" {
+			return nil, nil
+		}
+		return info.Label, nil
+	case "liveDebuggingEnabled":
+		return info.LiveDebuggingEnabled, nil
+	case "localID":
+		return info.ID.LocalID, nil
+	case "moduleID":
+		return info.ID.ModuleID, nil
+	case "moduleIDs":
+		return info.ModuleIDs, nil
+	case "name":
+		return info.ComponentName, nil
+	case "referencedBy":
+		return info.ReferencedBy, nil
+	case "references":
+		return info.References, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q on Component", field.Name)
+	}
+}
+
+// marshalBody encodes an Alloy value the same way as the component
+// endpoints of the HTTP API.
+func marshalBody(val any) (json.RawMessage, error) {
+	if val == nil {
+		return nil, nil
+	}
+	return alloyjson.MarshalBody(val)
+}
+
+type healthResolver struct {
+	health component.Health
+}
+
+func (r *healthResolver) Resolve(_ context.Context, field *ast.Field, _ map[string]any) (any, error) {
+	switch field.Name {
+	case "message":
+		return r.health.Message, nil
+	case "lastUpdated":
+		return r.health.UpdateTime, nil
+	case "state":
+		return healthState(r.health.Health), nil
+	default:
+		return nil, fmt.Errorf("unknown field %q on Health", field.Name)
+	}
+}
+
+// healthState returns the HealthState enum value of ht.
+func healthState(ht component.HealthType) string {
+	return strings.ToUpper(ht.String())
+}
@@ -0,0 +1,70 @@
+// Package graph implements the GraphQL schema of the graphql service and a
+// small executor to resolve requests against it.
+package graph
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+//go:embed schema/*.graphqls
+var schemaFS embed.FS
+
+// LoadSchema parses and validates the embedded GraphQL schema files.
+func LoadSchema() (*ast.Schema, error) {
+	sources, err := schemaSources()
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := gqlparser.LoadSchema(sources...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	return schema, nil
+}
+
+// SchemaSDL returns the raw schema definition language of the embedded
+// schema files, concatenated in a stable order.
+func SchemaSDL() (string, error) {
+	sources, err := schemaSources()
+	if err != nil {
+		return "", err
+	}
+
+	var sdl string
+	for _, src := range sources {
+		sdl += "# " + src.Name + "\n" + src.Input + "\n"
+	}
+	return sdl, nil
+}
+
+func schemaSources() ([]*ast.Source, error) {
+	entries, err := fs.ReadDir(schemaFS, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]*ast.Source, 0, len(entries))
+	for _, entry := range entries {
+		name := path.Join("schema", entry.Name())
+		bb, err := fs.ReadFile(schemaFS, name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &ast.Source{Name: name, Input: string(bb)})
+	}
+
+	// base.graphqls defines the root types which the other files extend, so it
+	// must always be loaded first.
+	sort.SliceStable(sources, func(i, j int) bool {
+		return path.Base(sources[i].Name) == "base.graphqls" && path.Base(sources[j].Name) != "base.graphqls"
+	})
+	return sources, nil
+}
@@ -0,0 +1,315 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/internal/service/remotecfg"
+)
+
+// subscriptionBufferSize is the number of events buffered for each
+// subscription before new events are dropped.
+const subscriptionBufferSize = 1000
+
+// SubscriptionRoot resolves the fields of the root Subscription type.
+type SubscriptionRoot struct {
+	Host            service.Host
+	CallbackManager livedebugging.CallbackManager
+	Logger          log.Logger
+}
+
+var _ SubscriptionResolver = (*SubscriptionRoot)(nil)
+
+// Subscribe implements [SubscriptionResolver].
+func (r *SubscriptionRoot) Subscribe(ctx context.Context, field *ast.Field, args map[string]any) (<-chan any, error) {
+	switch field.Name {
+	case "componentHealth":
+		return r.componentHealth(ctx, args)
+	case "liveDebugging":
+		return r.liveDebugging(ctx, args)
+	default:
+		return nil, fmt.Errorf("unknown field %q on Subscription", field.Name)
+	}
+}
+
+type componentHealthEvent struct {
+	info          *component.Info
+	previousState string
+}
+
+func (ev *componentHealthEvent) Resolve(_ context.Context, field *ast.Field, _ map[string]any) (any, error) {
+	switch field.Name {
+	case "component":
+		return &componentResolver{info: ev.info}, nil
+	case "health":
+		return &healthResolver{health: ev.info.Health}, nil
+	case "previousState":
+		if ev.previousState == "" {
+			return nil, nil
+		}
+		return ev.previousState, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q on ComponentHealthEvent", field.Name)
+	}
+}
+
+// componentHealth polls the health of the requested components and sends an
+// event every time it changes.
+func (r *SubscriptionRoot) componentHealth(ctx context.Context, args map[string]any) (<-chan any, error) {
+	var (
+		moduleID, _ = args["moduleID"].(string)
+		ids         = stringList(args["ids"])
+		interval    = time.Second
+	)
+	if v, ok := toInt(args["interval"]); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("interval must be at least 1 second")
+		}
+		interval = time.Duration(v) * time.Second
+	}
+
+	host, err := resolveServiceHost(r.Host, moduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail early if the module doesn't exist.
+	if _, err := host.ListComponents(moduleID, component.InfoOptions{}); err != nil {
+		return nil, err
+	}
+
+	out := make(chan any, subscriptionBufferSize)
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := make(map[string]component.Health)
+		for {
+			infos, err := host.ListComponents(moduleID, component.InfoOptions{GetHealth: true})
+			if err != nil {
+				level.Debug(r.Logger).Log("msg", "failed to list components for health subscription", "module", moduleID, "err", err)
+			}
+
+			current := make(map[string]struct{}, len(infos))
+			for _, info := range infos {
+				id := info.ID.String()
+				if len(ids) > 0 && !slices.Contains(ids, id) {
+					continue
+				}
+				current[id] = struct{}{}
+
+				prev, seen := last[id]
+				if seen && prev.Health == info.Health.Health && prev.Message == info.Health.Message {
+					continue
+				}
+				last[id] = info.Health
+
+				ev := &componentHealthEvent{info: info}
+				if seen {
+					ev.previousState = healthState(prev.Health)
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			// Forget removed components, so that the map doesn't grow with
+			// every component which ever existed. Nothing is forgotten if
+			// listing failed.
+			if err == nil {
+				maps.DeleteFunc(last, func(id string, _ component.Health) bool {
+					_, ok := current[id]
+					return !ok
+				})
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+type liveDebuggingEvent struct {
+	data livedebugging.Data
+}
+
+func (ev *liveDebuggingEvent) Resolve(_ context.Context, field *ast.Field, _ map[string]any) (any, error) {
+	switch field.Name {
+	case "componentID":
+		return string(ev.data.ComponentID), nil
+	case "count":
+		return ev.data.Count, nil
+	case "data":
+		if ev.data.DataFunc == nil {
+			return "", nil
+		}
+		return ev.data.DataFunc(), nil
+	case "targetComponentIDs":
+		return ev.data.TargetComponentIDs, nil
+	case "type":
+		return string(ev.data.Type), nil
+	default:
+		return nil, fmt.Errorf("unknown field %q on LiveDebuggingData", field.Name)
+	}
+}
+
+// liveDebugging registers live debugging callbacks for the requested
+// components and forwards the data they publish.
+func (r *SubscriptionRoot) liveDebugging(ctx context.Context, args map[string]any) (<-chan any, error) {
+	if r.CallbackManager == nil {
+		return nil, fmt.Errorf("live debugging is not available")
+	}
+
+	var (
+		componentIDs = stringList(args["componentIDs"])
+		types        = stringList(args["types"])
+		sampleRate   = 1.0
+	)
+	if v, ok := args["sampleRate"].(float64); ok {
+		if v < 0 || v > 1 {
+			return nil, fmt.Errorf("sampleRate must be between 0 and 1")
+		}
+		sampleRate = v
+	} else if v, ok := toInt(args["sampleRate"]); ok {
+		if v < 0 || v > 1 {
+			return nil, fmt.Errorf("sampleRate must be between 0 and 1")
+		}
+		sampleRate = float64(v)
+	}
+	if len(componentIDs) == 0 {
+		return nil, fmt.Errorf("at least one component ID is required")
+	}
+
+	var (
+		callbackID = livedebugging.CallbackID(uuid.New().String())
+		dataCh     = make(chan any, subscriptionBufferSize)
+		registered []livedebugging.ComponentID
+	)
+
+	unregister := func() {
+		for _, id := range registered {
+			r.CallbackManager.DeleteCallback(callbackID, id)
+		}
+	}
+
+	// The callback runs on the goroutines of every subscribed component.
+	var droppedData atomic.Bool
+	callback := func(data livedebugging.Data) {
+		if len(types) > 0 && !slices.Contains(types, string(data.Type)) {
+			return
+		}
+		if sampleRate < 1 && rand.Float64() > sampleRate {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// Avoid blocking the component when the subscriber is slow.
+		select {
+		case dataCh <- &liveDebuggingEvent{data: data}:
+		default:
+			if droppedData.CompareAndSwap(false, true) {
+				level.Warn(r.Logger).Log("msg", "data throughput is very high, not all debugging data can be sent to the GraphQL subscription")
+			}
+		}
+	}
+
+	for _, id := range componentIDs {
+		host, err := resolveServiceHost(r.Host, id)
+		if err != nil {
+			unregister()
+			return nil, err
+		}
+
+		componentID := livedebugging.ComponentID(id)
+		if err := r.CallbackManager.AddCallback(host, callbackID, componentID, callback); err != nil {
+			unregister()
+			return nil, fmt.Errorf("component %q: %w", id, err)
+		}
+		registered = append(registered, componentID)
+	}
+
+	// dataCh is never closed since callbacks may still be running while they
+	// are being unregistered. Events are forwarded to a separate channel which
+	// is closed once the subscription ends.
+	out := make(chan any)
+	go func() {
+		defer close(out)
+		defer unregister()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-dataCh:
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// resolveServiceHost returns the host owning the given component or module
+// ID, which is the remotecfg host for IDs starting with "remotecfg/".
+func resolveServiceHost(host service.Host, id string) (service.Host, error) {
+	if strings.HasPrefix(id, "remotecfg/") {
+		return remotecfg.GetHost(host)
+	}
+	return host, nil
+}
+
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	res := make([]string, 0, len(list))
+	for _, elem := range list {
+		if s, ok := elem.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func toInt(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,183 @@
+// Package graphql implements the GraphQL service, which exposes information
+// about the running Alloy instance and its components through a GraphQL API.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/internal/service/graphql/graph"
+	http_service "github.com/grafana/alloy/internal/service/http"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+// ServiceName defines the name used for the GraphQL service.
+const ServiceName = "graphql"
+
+// maxRequestSize is the maximum size of a GraphQL request body.
+const maxRequestSize = 1 << 20
+
+// Options are used to configure the GraphQL service. Options are constant for
+// the lifetime of the GraphQL service.
+type Options struct {
+	Logger          log.Logger
+	ReadyFunc       func() bool                   // Reports whether Alloy has loaded its configuration.
+	CallbackManager livedebugging.CallbackManager // CallbackManager is used for live debugging subscriptions.
+}
+
+// Service implements the GraphQL service.
+type Service struct {
+	opts     Options
+	executor *graph.Executor
+}
+
+var (
+	_ service.Service             = (*Service)(nil)
+	_ http_service.ServiceHandler = (*Service)(nil)
+)
+
+// New returns a new, unstarted GraphQL service.
+func New(opts Options) (*Service, error) {
+	if opts.Logger == nil {
+		opts.Logger = log.NewNopLogger()
+	}
+
+	executor, err := graph.NewExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		opts:     opts,
+		executor: executor,
+	}, nil
+}
+
+// Definition returns the definition of the GraphQL service.
+func (s *Service) Definition() service.Definition {
+	return service.Definition{
+		Name:       ServiceName,
+		ConfigType: nil, // graphql does not accept configuration
+		DependsOn:  []string{http_service.ServiceName, livedebugging.ServiceName},
+		Stability:  featuregate.StabilityExperimental,
+	}
+}
+
+// Run starts the GraphQL service. It will run until the provided context is
+// canceled or there is a fatal error.
+func (s *Service) Run(ctx context.Context, host service.Host) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements [service.Service]. It is a no-op since the GraphQL
+// service does not support runtime configuration.
+func (s *Service) Update(newConfig any) error {
+	return fmt.Errorf("GraphQL service does not support configuration")
+}
+
+// Data implements [service.Service]. It returns nil, as the GraphQL service
+// does not have any runtime data.
+func (s *Service) Data() any {
+	return nil
+}
+
+// ServiceHandler implements [http_service.ServiceHandler]. It returns the HTTP
+// endpoints to serve GraphQL requests. Subscriptions are served over
+// websockets on the same endpoint as queries.
+func (s *Service) ServiceHandler(host service.Host) (base string, handler http.Handler) {
+	const basePath = "/graphql"
+
+	r := mux.NewRouter()
+	r.Handle(path.Join(basePath, "/schema"), http.HandlerFunc(s.schemaHandler)).Methods(http.MethodGet)
+	r.Handle(basePath, s.queryHandler(host)).Methods(http.MethodGet, http.MethodPost)
+
+	return basePath, r
+}
+
+func (s *Service) queryHandler(host service.Host) http.HandlerFunc {
+	var (
+		root    = &graph.QueryResolver{Host: host, ReadyFunc: s.opts.ReadyFunc}
+		subRoot = &graph.SubscriptionRoot{Host: host, CallbackManager: s.opts.CallbackManager, Logger: s.opts.Logger}
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			s.serveWebsocket(w, r, root, subRoot)
+			return
+		}
+
+		req, err := parseRequest(r)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, &graph.Response{
+				Errors: gqlerror.List{gqlerror.Errorf("%s", err)},
+			})
+			return
+		}
+
+		resp := s.executor.Execute(r.Context(), root, req)
+
+		// Requests which could not be parsed or validated have no data and are
+		// reported as bad requests; errors raised while resolving fields are
+		// reported alongside partial data.
+		status := http.StatusOK
+		if resp.Data == nil && len(resp.Errors) > 0 {
+			status = http.StatusBadRequest
+		}
+		writeResponse(w, status, resp)
+	}
+}
+
+func (s *Service) schemaHandler(w http.ResponseWriter, _ *http.Request) {
+	sdl, err := graph.SchemaSDL()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, sdl)
+}
+
+// parseRequest reads a GraphQL request from either the query parameters of a
+// GET request or the JSON body of a POST request.
+func parseRequest(r *http.Request) (graph.Request, error) {
+	var req graph.Request
+
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return req, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+	} else {
+		dec := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize))
+		if err := dec.Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid request body: %w", err)
+		}
+	}
+
+	if req.Query == "" {
+		return req, fmt.Errorf("no query provided")
+	}
+	return req, nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp *graph.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
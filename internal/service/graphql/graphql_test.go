@@ -0,0 +1,341 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/service"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+func TestQuery_Alloy(t *testing.T) {
+	resp := doQuery(t, newTestService(t), `{ alloy { isReady version } }`, nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"alloy":{"isReady":true,"version":"v0.0.0"}}`, string(resp.Data))
+}
+
+func TestQuery_Components(t *testing.T) {
+	svc := newTestService(t)
+
+	t.Run("root module", func(t *testing.T) {
+		resp := doQuery(t, svc, `{ components { id name label health { state message } } }`, nil)
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{"components":[
+			{"id":"local.file.a","name":"local.file","label":"a","health":{"state":"HEALTHY","message":"ok"}},
+			{"id":"import.file.mod","name":"import.file","label":"mod","health":{"state":"UNHEALTHY","message":"failed"}}
+		]}`, string(resp.Data))
+	})
+
+	t.Run("filter by name and health", func(t *testing.T) {
+		resp := doQuery(t, svc, `query($state: HealthState) {
+			byName: components(name: "local.file") { id }
+			byHealth: components(health: $state) { id }
+		}`, map[string]any{"state": "UNHEALTHY"})
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{
+			"byName":[{"id":"local.file.a"}],
+			"byHealth":[{"id":"import.file.mod"}]
+		}`, string(resp.Data))
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		resp := doQuery(t, svc, `{ components(recursive: true) { id moduleID moduleIDs } }`, nil)
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{"components":[
+			{"id":"local.file.a","moduleID":"","moduleIDs":[]},
+			{"id":"import.file.mod","moduleID":"","moduleIDs":["import.file.mod"]},
+			{"id":"import.file.mod/prometheus.relabel.b","moduleID":"import.file.mod","moduleIDs":[]}
+		]}`, string(resp.Data))
+	})
+
+	t.Run("unknown module", func(t *testing.T) {
+		resp := doQuery(t, svc, `{ components(moduleID: "missing") { id } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		require.Contains(t, resp.Errors[0].Message, "module not found")
+	})
+}
+
+func TestQuery_Component(t *testing.T) {
+	svc := newTestService(t)
+
+	t.Run("found", func(t *testing.T) {
+		resp := doQuery(t, svc, `
+			query($id: ID!) { component(id: $id) { ...details } }
+			fragment details on Component { id references referencedBy dataFlowEdgesTo arguments @include(if: true) exports @skip(if: true) }
+		`, map[string]any{"id": "import.file.mod/prometheus.relabel.b"})
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{"component":{
+			"id":"import.file.mod/prometheus.relabel.b",
+			"references":["local.file.a"],
+			"referencedBy":[],
+			"dataFlowEdgesTo":["prometheus.remote_write.default"],
+			"arguments":[{"name":"max_cache_size","type":"attr","value":{"type":"number","value":100}}]
+		}}`, string(resp.Data))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		resp := doQuery(t, svc, `{ component(id: "missing") { id } }`, nil)
+		require.Empty(t, resp.Errors)
+		require.JSONEq(t, `{"component":null}`, string(resp.Data))
+	})
+}
+
+func TestQuery_Invalid(t *testing.T) {
+	svc := newTestService(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ unknownField }`), nil)
+	_, handler := svc.ServiceHandler(fakeHost{})
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "unknownField")
+}
+
+func TestSchemaEndpoint(t *testing.T) {
+	svc := newTestService(t)
+
+	rec := httptest.NewRecorder()
+	_, handler := svc.ServiceHandler(fakeHost{})
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql/schema", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "type Component {")
+}
+
+func TestSubscription_ComponentHealth(t *testing.T) {
+	conn := dialWebsocket(t, newTestService(t))
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsSubscribe, Payload: mustMarshal(t, map[string]any{
+		"query": `subscription { componentHealth(ids: ["import.file.mod"]) { component { id } health { state } previousState } }`,
+	})})
+
+	msg := readWS(t, conn)
+	require.Equal(t, wsNext, msg.Type)
+	require.Equal(t, "1", msg.ID)
+	require.JSONEq(t, `{"data":{"componentHealth":{"component":{"id":"import.file.mod"},"health":{"state":"UNHEALTHY"},"previousState":null}}}`, string(msg.Payload))
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsComplete})
+	writeWS(t, conn, wsMessage{Type: wsPing})
+	require.Equal(t, wsPong, readWS(t, conn).Type)
+}
+
+func TestSubscription_LiveDebugging(t *testing.T) {
+	cm := &fakeCallbackManager{callbacks: make(map[livedebugging.ComponentID]func(livedebugging.Data))}
+	svc, err := New(Options{CallbackManager: cm})
+	require.NoError(t, err)
+	conn := dialWebsocket(t, svc)
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsSubscribe, Payload: mustMarshal(t, map[string]any{
+		"query": `subscription { liveDebugging(componentIDs: ["local.file.a"], types: ["loki_log"]) { componentID type count data } }`,
+	})})
+
+	require.Eventually(t, func() bool { return cm.callback("local.file.a") != nil }, 5*time.Second, 10*time.Millisecond)
+	publish := cm.callback("local.file.a")
+	publish(livedebugging.NewData("local.file.a", livedebugging.Target, 1, func() string { return "ignored" }))
+	publish(livedebugging.NewData("local.file.a", livedebugging.LokiLog, 2, func() string { return "hello" }))
+
+	msg := readWS(t, conn)
+	require.Equal(t, wsNext, msg.Type)
+	require.JSONEq(t, `{"data":{"liveDebugging":{"componentID":"local.file.a","type":"loki_log","count":2,"data":"hello"}}}`, string(msg.Payload))
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsComplete})
+	require.Eventually(t, func() bool { return cm.callback("local.file.a") == nil }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSubscription_InvalidQuery(t *testing.T) {
+	conn := dialWebsocket(t, newTestService(t))
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsSubscribe, Payload: mustMarshal(t, map[string]any{
+		"query": `subscription { componentHealth(moduleID: "missing") { component { id } } }`,
+	})})
+
+	msg := readWS(t, conn)
+	require.Equal(t, wsError, msg.Type)
+	require.Contains(t, string(msg.Payload), "module not found")
+}
+
+func TestWebsocket_ReadLimit(t *testing.T) {
+	conn := dialWebsocket(t, newTestService(t))
+
+	writeWS(t, conn, wsMessage{ID: "1", Type: wsSubscribe, Payload: mustMarshal(t, map[string]any{
+		"query": strings.Repeat(" ", wsReadLimit),
+	})})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "unexpected error: %v", err)
+}
+
+func dialWebsocket(t *testing.T, svc *Service) *websocket.Conn {
+	t.Helper()
+
+	_, handler := svc.ServiceHandler(fakeHost{})
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{wsSubprotocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/graphql", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	writeWS(t, conn, wsMessage{Type: wsConnectionInit})
+	require.Equal(t, wsConnectionAck, readWS(t, conn).Type)
+	return conn
+}
+
+func writeWS(t *testing.T, conn *websocket.Conn, msg wsMessage) {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(msg))
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func mustMarshal(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	bb, err := json.Marshal(v)
+	require.NoError(t, err)
+	return bb
+}
+
+type fakeCallbackManager struct {
+	mut       sync.Mutex
+	callbacks map[livedebugging.ComponentID]func(livedebugging.Data)
+}
+
+var _ livedebugging.CallbackManager = (*fakeCallbackManager)(nil)
+
+func (cm *fakeCallbackManager) callback(id livedebugging.ComponentID) func(livedebugging.Data) {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+	return cm.callbacks[id]
+}
+
+func (cm *fakeCallbackManager) AddCallback(_ service.Host, _ livedebugging.CallbackID, componentID livedebugging.ComponentID, callback func(livedebugging.Data)) error {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+	cm.callbacks[componentID] = callback
+	return nil
+}
+
+func (cm *fakeCallbackManager) DeleteCallback(_ livedebugging.CallbackID, componentID livedebugging.ComponentID) {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+	delete(cm.callbacks, componentID)
+}
+
+func (cm *fakeCallbackManager) AddCallbackMulti(service.Host, livedebugging.CallbackID, livedebugging.ModuleID, func(livedebugging.Data)) error {
+	return nil
+}
+
+func (cm *fakeCallbackManager) DeleteCallbackMulti(service.Host, livedebugging.CallbackID, livedebugging.ModuleID) {
+}
+
+type testResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	svc, err := New(Options{ReadyFunc: func() bool { return true }})
+	require.NoError(t, err)
+	return svc
+}
+
+func doQuery(t *testing.T, svc *Service, query string, vars map[string]any) testResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	_, handler := svc.ServiceHandler(fakeHost{})
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	var resp testResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return resp
+}
+
+type fakeArguments struct {
+	MaxCacheSize int `alloy:"max_cache_size,attr"`
+}
+
+var testComponents = map[string][]*component.Info{
+	"": {
+		{
+			ID:            component.ID{LocalID: "local.file.a"},
+			ComponentName: "local.file",
+			Label:         "a",
+			ReferencedBy:  []string{"import.file.mod"},
+			Health:        component.Health{Health: component.HealthTypeHealthy, Message: "ok", UpdateTime: time.Unix(0, 0)},
+		},
+		{
+			ID:            component.ID{LocalID: "import.file.mod"},
+			ComponentName: "import.file",
+			Label:         "mod",
+			ModuleIDs:     []string{"import.file.mod"},
+			References:    []string{"local.file.a"},
+			Health:        component.Health{Health: component.HealthTypeUnhealthy, Message: "failed", UpdateTime: time.Unix(0, 0)},
+		},
+	},
+	"import.file.mod": {
+		{
+			ID:              component.ID{ModuleID: "import.file.mod", LocalID: "prometheus.relabel.b"},
+			ComponentName:   "prometheus.relabel",
+			Label:           "b",
+			References:      []string{"local.file.a"},
+			DataFlowEdgesTo: []string{"prometheus.remote_write.default"},
+			Arguments:       fakeArguments{MaxCacheSize: 100},
+		},
+	},
+}
+
+type fakeHost struct{}
+
+var _ service.Host = (fakeHost{})
+
+func (fakeHost) GetComponent(id component.ID, opts component.InfoOptions) (*component.Info, error) {
+	for _, info := range testComponents[id.ModuleID] {
+		if info.ID == id {
+			return info, nil
+		}
+	}
+	return nil, component.ErrComponentNotFound
+}
+
+func (fakeHost) ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error) {
+	infos, ok := testComponents[moduleID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", component.ErrModuleNotFound, moduleID)
+	}
+	return infos, nil
+}
+
+func (fakeHost) GetServiceConsumers(serviceName string) []service.Consumer { return nil }
+
+func (fakeHost) NewController(id string) service.Controller { return nil }
+
+func (fakeHost) GetService(svc string) (service.Service, bool) { return nil, false }
@@ -0,0 +1,255 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/graphql/graph"
+)
+
+// The graphql-transport-ws protocol is used to serve subscriptions over
+// websockets. See
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+const (
+	wsSubprotocol = "graphql-transport-ws"
+
+	wsConnectionInit = "connection_init"
+	wsConnectionAck  = "connection_ack"
+	wsPing           = "ping"
+	wsPong           = "pong"
+	wsSubscribe      = "subscribe"
+	wsNext           = "next"
+	wsError          = "error"
+	wsComplete       = "complete"
+
+	wsCloseInvalidMessage      = 4400
+	wsCloseUnauthorized        = 4401
+	wsCloseInitTimeout         = 4408
+	wsCloseSubscriberExists    = 4409
+	wsCloseTooManyInitRequests = 4429
+
+	// wsInitTimeout is how long clients have to send connection_init after
+	// connecting.
+	wsInitTimeout = 10 * time.Second
+
+	// wsReadLimit is the maximum size of a message read from clients.
+	// Clients only send small control and subscribe messages.
+	wsReadLimit = 64 << 10
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{wsSubprotocol},
+}
+
+// wsConn is a single websocket connection serving GraphQL operations.
+type wsConn struct {
+	conn     *websocket.Conn
+	executor *graph.Executor
+	query    graph.Resolver
+	sub      graph.SubscriptionResolver
+	log      log.Logger
+
+	writeMut sync.Mutex
+
+	mut        sync.Mutex
+	operations map[string]context.CancelFunc
+}
+
+func (s *Service) serveWebsocket(w http.ResponseWriter, r *http.Request, query graph.Resolver, sub graph.SubscriptionResolver) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied to the client.
+		level.Debug(s.opts.Logger).Log("msg", "failed to upgrade GraphQL websocket connection", "err", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(wsReadLimit)
+
+	if conn.Subprotocol() != wsSubprotocol {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseProtocolError, "unsupported subprotocol"))
+		return
+	}
+
+	ws := &wsConn{
+		conn:       conn,
+		executor:   s.executor,
+		query:      query,
+		sub:        sub,
+		log:        s.opts.Logger,
+		operations: make(map[string]context.CancelFunc),
+	}
+	ws.run(r.Context())
+}
+
+func (ws *wsConn) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	initTimer := time.AfterFunc(wsInitTimeout, func() {
+		ws.close(wsCloseInitTimeout, "connection initialisation timeout")
+	})
+	defer initTimer.Stop()
+
+	initialized := false
+	for {
+		var msg wsMessage
+		if err := ws.conn.ReadJSON(&msg); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				level.Debug(ws.log).Log("msg", "closing GraphQL websocket connection", "err", err)
+			}
+			return
+		}
+
+		switch msg.Type {
+		case wsConnectionInit:
+			if initialized {
+				ws.close(wsCloseTooManyInitRequests, "too many initialisation requests")
+				return
+			}
+			initTimer.Stop()
+			initialized = true
+			ws.write(wsMessage{Type: wsConnectionAck})
+
+		case wsPing:
+			ws.write(wsMessage{Type: wsPong})
+
+		case wsPong:
+			// Nothing to do.
+
+		case wsSubscribe:
+			if !initialized {
+				ws.close(wsCloseUnauthorized, "unauthorized")
+				return
+			}
+
+			var req graph.Request
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				ws.close(wsCloseInvalidMessage, "invalid subscribe message")
+				return
+			}
+
+			opCtx, opCancel := context.WithCancel(ctx)
+			if !ws.startOperation(msg.ID, opCancel) {
+				opCancel()
+				ws.close(wsCloseSubscriberExists, fmt.Sprintf("subscriber for %s already exists", msg.ID))
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer ws.stopOperation(msg.ID)
+				ws.runOperation(opCtx, msg.ID, req)
+			}()
+
+		case wsComplete:
+			ws.stopOperation(msg.ID)
+
+		default:
+			ws.close(wsCloseInvalidMessage, fmt.Sprintf("unexpected message type %q", msg.Type))
+			return
+		}
+	}
+}
+
+// runOperation runs a single operation until it completes or ctx is
+// canceled.
+func (ws *wsConn) runOperation(ctx context.Context, id string, req graph.Request) {
+	op, _, errs := ws.executor.Prepare(req)
+	if len(errs) > 0 {
+		ws.writeErrors(id, errs)
+		return
+	}
+
+	if op.Operation != ast.Subscription {
+		resp := ws.executor.Execute(ctx, ws.query, req)
+		ws.writePayload(id, wsNext, resp)
+		ws.write(wsMessage{ID: id, Type: wsComplete})
+		return
+	}
+
+	results, errs := ws.executor.Subscribe(ctx, ws.sub, req)
+	if len(errs) > 0 {
+		ws.writeErrors(id, errs)
+		return
+	}
+
+	for resp := range results {
+		ws.writePayload(id, wsNext, resp)
+	}
+
+	// Only notify the client if the subscription ended on the server side.
+	if ctx.Err() == nil {
+		ws.write(wsMessage{ID: id, Type: wsComplete})
+	}
+}
+
+func (ws *wsConn) startOperation(id string, cancel context.CancelFunc) bool {
+	ws.mut.Lock()
+	defer ws.mut.Unlock()
+
+	if _, exists := ws.operations[id]; exists {
+		return false
+	}
+	ws.operations[id] = cancel
+	return true
+}
+
+func (ws *wsConn) stopOperation(id string) {
+	ws.mut.Lock()
+	defer ws.mut.Unlock()
+
+	if cancel, ok := ws.operations[id]; ok {
+		cancel()
+		delete(ws.operations, id)
+	}
+}
+
+func (ws *wsConn) writeErrors(id string, errs gqlerror.List) {
+	ws.writePayload(id, wsError, errs)
+}
+
+func (ws *wsConn) writePayload(id string, typ string, payload any) {
+	bb, err := json.Marshal(payload)
+	if err != nil {
+		level.Warn(ws.log).Log("msg", "failed to encode GraphQL websocket payload", "err", err)
+		return
+	}
+	ws.write(wsMessage{ID: id, Type: typ, Payload: bb})
+}
+
+func (ws *wsConn) write(msg wsMessage) {
+	ws.writeMut.Lock()
+	defer ws.writeMut.Unlock()
+
+	if err := ws.conn.WriteJSON(msg); err != nil {
+		level.Debug(ws.log).Log("msg", "failed to write GraphQL websocket message", "err", err)
+	}
+}
+
+func (ws *wsConn) close(code int, reason string) {
+	ws.writeMut.Lock()
+	defer ws.writeMut.Unlock()
+
+	_ = ws.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	_ = ws.conn.Close()
+}
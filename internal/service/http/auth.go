@@ -1,20 +1,32 @@
 package http
 
 import (
+	"context"
+	"crypto"
 	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"os"
 	"slices"
 	"strings"
 
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+
 	"github.com/grafana/alloy/syntax"
 	"github.com/grafana/alloy/syntax/alloytypes"
 )
 
 type AuthArguments struct {
-	Basic  *BasicAuthArguments `alloy:"basic,block,optional"`
-	Filter FilterAuthArguments `alloy:"filter,block,optional"`
+	Basic  *BasicAuthArguments   `alloy:"basic,block,optional"`
+	Bearer []BearerAuthArguments `alloy:"bearer,block,optional"`
+	JWT    *JWTAuthArguments     `alloy:"jwt,block,optional"`
+	Roles  []RoleArguments       `alloy:"role,block,optional"`
+	Filter FilterAuthArguments   `alloy:"filter,block,optional"`
 }
 
 type BasicAuthArguments struct {
@@ -22,6 +34,65 @@ type BasicAuthArguments struct {
 	Password alloytypes.Secret `alloy:"password,attr"`
 }
 
+// BearerAuthArguments is a static bearer token and the user it identifies.
+type BearerAuthArguments struct {
+	Token alloytypes.Secret `alloy:"token,attr"`
+	User  string            `alloy:"user,attr"`
+}
+
+// JWTAuthArguments configures the validation of JWT bearer tokens, such as
+// OIDC ID tokens.
+type JWTAuthArguments struct {
+	JWKSFile      string `alloy:"jwks_file,attr,optional"`
+	JWKSURL       string `alloy:"jwks_url,attr,optional"`
+	Issuer        string `alloy:"issuer,attr"`
+	Audience      string `alloy:"audience,attr"`
+	UsernameClaim string `alloy:"username_claim,attr,optional"`
+}
+
+var _ syntax.Defaulter = (*JWTAuthArguments)(nil)
+
+// SetToDefault implements syntax.Defaulter.
+func (j *JWTAuthArguments) SetToDefault() {
+	*j = JWTAuthArguments{UsernameClaim: "sub"}
+}
+
+var _ syntax.Validator = (*JWTAuthArguments)(nil)
+
+// Validate implements syntax.Validator.
+func (j *JWTAuthArguments) Validate() error {
+	if (j.JWKSFile == "") == (j.JWKSURL == "") {
+		return errors.New("exactly one of jwks_file or jwks_url must be set")
+	}
+	if j.JWKSURL != "" {
+		if _, err := url.ParseRequestURI(j.JWKSURL); err != nil {
+			return fmt.Errorf("invalid jwks_url: %w", err)
+		}
+	}
+	// Without these checks, tokens issued to any other application trusting
+	// the same keys would be accepted.
+	if j.Issuer == "" {
+		return errors.New("issuer must not be empty")
+	}
+	if j.Audience == "" {
+		return errors.New("audience must not be empty")
+	}
+	if j.UsernameClaim == "" {
+		return errors.New("username_claim must not be empty")
+	}
+	return nil
+}
+
+// RoleArguments grants the users and token claims it matches access to a set
+// of paths.
+type RoleArguments struct {
+	Name         string            `alloy:",label"`
+	Paths        []string          `alloy:"paths,attr"`
+	ExcludePaths []string          `alloy:"exclude_paths,attr,optional"`
+	Users        []string          `alloy:"users,attr,optional"`
+	Claims       map[string]string `alloy:"claims,attr,optional"`
+}
+
 type FilterAuthArguments struct {
 	Paths             []string `alloy:"paths,attr,optional"`
 	AuthMatchingPaths bool     `alloy:"authenticate_matching_paths,attr,optional"`
@@ -34,31 +105,201 @@ func (f *FilterAuthArguments) SetToDefault() {
 	f.AuthMatchingPaths = true
 }
 
-func (a *AuthArguments) authenticator() authenticator {
+var _ syntax.Validator = (*AuthArguments)(nil)
+
+// Validate implements syntax.Validator.
+func (a *AuthArguments) Validate() error {
+	tokens := make(map[string]struct{}, len(a.Bearer))
+	for i, b := range a.Bearer {
+		if b.Token == "" {
+			return fmt.Errorf("bearer[%d]: token must not be empty", i)
+		}
+		if _, ok := tokens[string(b.Token)]; ok {
+			return fmt.Errorf("bearer[%d]: token is used by another bearer block", i)
+		}
+		tokens[string(b.Token)] = struct{}{}
+	}
+
+	names := make(map[string]struct{}, len(a.Roles))
+	for _, role := range a.Roles {
+		if _, ok := names[role.Name]; ok {
+			return fmt.Errorf("role %q is defined more than once", role.Name)
+		}
+		names[role.Name] = struct{}{}
+		if len(role.Paths) == 0 {
+			return fmt.Errorf("role %q must allow at least one path", role.Name)
+		}
+		if len(role.Users) == 0 && len(role.Claims) == 0 {
+			return fmt.Errorf("role %q must match at least one user or claim", role.Name)
+		}
+	}
+
+	if len(a.Roles) > 0 && a.Basic == nil && len(a.Bearer) == 0 && a.JWT == nil {
+		return errors.New("roles require at least one of the basic, bearer or jwt blocks")
+	}
+	return nil
+}
+
+func (a *AuthArguments) authenticator() (authenticator, error) {
+	var (
+		creds   []credentials
+		schemes []string
+	)
 	if a.Basic != nil {
-		return routeAuthenticator(a.Filter, basicAuthenticator(a.Basic.Username, string(a.Basic.Password)))
+		creds = append(creds, basicCredentials(a.Basic.Username, string(a.Basic.Password)))
+		schemes = append(schemes, "Basic")
+	}
+	if len(a.Bearer) > 0 {
+		creds = append(creds, bearerCredentials(a.Bearer))
+	}
+	if a.JWT != nil {
+		c, err := jwtCredentials(*a.JWT)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, c)
 	}
-	// No need to wrap with routeAuthenticator because authentication is not configured.
-	return allowAuthenticator
+	if len(a.Bearer) > 0 || a.JWT != nil {
+		schemes = append(schemes, "Bearer")
+	}
+
+	if len(creds) == 0 {
+		// No need to wrap with routeAuthenticator because authentication is not configured.
+		return allowAuthenticator, nil
+	}
+	return routeAuthenticator(a.Filter, identityAuthenticator(creds, schemes, a.Roles)), nil
 }
 
 type authenticator func(w http.ResponseWriter, r *http.Request) error
 
+// authError is returned by authenticators for denied requests.
+type authError struct {
+	status int    // HTTP status to respond with.
+	user   string // Authenticated user, if any.
+	err    error
+}
+
+func (e *authError) Error() string { return e.err.Error() }
+func (e *authError) Unwrap() error { return e.err }
+
+func unauthorized(err error) error {
+	return &authError{status: http.StatusUnauthorized, err: err}
+}
+
+// deniedRequest returns the HTTP status and authenticated user of a request
+// denied with err.
+func deniedRequest(err error) (status int, user string) {
+	var aerr *authError
+	if errors.As(err, &aerr) {
+		return aerr.status, aerr.user
+	}
+	return http.StatusUnauthorized, ""
+}
+
 func allowAuthenticator(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-func basicAuthenticator(username, password string) authenticator {
+// identity is the caller of an authenticated request.
+type identity struct {
+	user   string
+	claims map[string]any
+}
+
+// errNoCredentials is returned by credentials when the request doesn't carry
+// the kind of credentials they check.
+var errNoCredentials = errors.New("no credentials")
+
+// credentials authenticates the caller of a request.
+type credentials func(r *http.Request) (*identity, error)
+
+// identityAuthenticator authenticates requests with the first of creds which
+// accepts them, and authorizes the caller against roles. Any authenticated
+// caller is authorized if there are no roles.
+func identityAuthenticator(creds []credentials, schemes []string, roles []RoleArguments) authenticator {
+	challenge := func(w http.ResponseWriter) {
+		for _, scheme := range schemes {
+			w.Header().Add("WWW-Authenticate", scheme+` realm="Restricted"`)
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var errs []error
+		for _, c := range creds {
+			id, err := c(r)
+			if errors.Is(err, errNoCredentials) {
+				continue
+			} else if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			if !authorized(roles, id, r.URL.Path) {
+				return &authError{
+					status: http.StatusForbidden,
+					user:   id.user,
+					err:    fmt.Errorf("no role of user %q allows access to the path", id.user),
+				}
+			}
+			return nil
+		}
+
+		challenge(w)
+		if len(errs) == 0 {
+			return unauthorized(errors.New("missing credentials"))
+		}
+		return unauthorized(errors.Join(errs...))
+	}
+}
+
+func authorized(roles []RoleArguments, id *identity, path string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+
+	compare := func(s string) bool { return strings.HasPrefix(path, s) }
+	for _, role := range roles {
+		if !role.matches(id) {
+			continue
+		}
+		if slices.ContainsFunc(role.Paths, compare) && !slices.ContainsFunc(role.ExcludePaths, compare) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the role applies to id, because it lists its user
+// or one of its claims.
+func (role RoleArguments) matches(id *identity) bool {
+	if slices.Contains(role.Users, id.user) {
+		return true
+	}
+	for name, want := range role.Claims {
+		switch v := id.claims[name].(type) {
+		case string:
+			if v == want {
+				return true
+			}
+		case []any:
+			if slices.Contains(v, any(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func basicCredentials(username, password string) credentials {
 	// We hash both expected and incoming data to prevent timing attacks, otherwise
 	// a caller can figure out the length of both password and username.
 	expectedUsername := sha256.Sum256([]byte(username))
 	expectedPassword := sha256.Sum256([]byte(password))
 
-	return func(w http.ResponseWriter, r *http.Request) error {
+	return func(r *http.Request) (*identity, error) {
 		username, password, ok := r.BasicAuth()
 		if !ok {
-			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-			return errors.New("unauthorized")
+			return nil, errNoCredentials
 		}
 
 		usernameHash := sha256.Sum256([]byte(username))
@@ -68,12 +309,128 @@ func basicAuthenticator(username, password string) authenticator {
 		passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], expectedPassword[:]) == 1
 
 		if !usernameMatch || !passwordMatch {
-			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-			return errors.New("unauthorized")
+			return nil, errors.New("invalid username or password")
 		}
 
-		return nil
+		return &identity{user: username}, nil
+	}
+}
+
+// bearerToken returns the bearer token of r.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func bearerCredentials(args []BearerAuthArguments) credentials {
+	type hashedToken struct {
+		hash [sha256.Size]byte
+		user string
+	}
+	tokens := make([]hashedToken, 0, len(args))
+	for _, b := range args {
+		tokens = append(tokens, hashedToken{hash: sha256.Sum256([]byte(b.Token)), user: b.User})
+	}
+
+	return func(r *http.Request) (*identity, error) {
+		token, ok := bearerToken(r)
+		if !ok {
+			return nil, errNoCredentials
+		}
+
+		// Compare against every token so that the time taken doesn't tell which
+		// one matched.
+		hash := sha256.Sum256([]byte(token))
+		var id *identity
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+				id = &identity{user: t.user}
+			}
+		}
+		if id == nil {
+			return nil, errors.New("invalid bearer token")
+		}
+		return id, nil
+	}
+}
+
+// jwtAlgorithms are the JWT signature algorithms which are accepted.
+var jwtAlgorithms = []string{
+	oidc.RS256, oidc.RS384, oidc.RS512,
+	oidc.ES256, oidc.ES384, oidc.ES512,
+	oidc.PS256, oidc.PS384, oidc.PS512,
+	oidc.EdDSA,
+}
+
+func jwtCredentials(args JWTAuthArguments) (credentials, error) {
+	var keySet oidc.KeySet
+	if args.JWKSFile != "" {
+		keys, err := readJWKS(args.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keySet = &oidc.StaticKeySet{PublicKeys: keys}
+	} else {
+		// The remote key set is fetched on first use, and fetched again when a
+		// token is signed with an unknown key.
+		keySet = oidc.NewRemoteKeySet(context.Background(), args.JWKSURL)
+	}
+
+	verifier := oidc.NewVerifier(args.Issuer, keySet, &oidc.Config{
+		ClientID:             args.Audience,
+		SupportedSigningAlgs: jwtAlgorithms,
+	})
+
+	return func(r *http.Request) (*identity, error) {
+		token, ok := bearerToken(r)
+		if !ok {
+			return nil, errNoCredentials
+		}
+
+		idToken, err := verifier.Verify(r.Context(), token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT: %w", err)
+		}
+		var claims map[string]any
+		if err := idToken.Claims(&claims); err != nil {
+			return nil, fmt.Errorf("invalid JWT claims: %w", err)
+		}
+		user, _ := claims[args.UsernameClaim].(string)
+		if user == "" {
+			return nil, fmt.Errorf("JWT has no %q claim", args.UsernameClaim)
+		}
+		return &identity{user: user, claims: claims}, nil
+	}, nil
+}
+
+// readJWKS reads the public keys of the JSON Web Key Set in path.
+func readJWKS(path string) ([]crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %q: %w", path, err)
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if !k.IsPublic() {
+			k = k.Public()
+		}
+		if k.Key == nil {
+			continue
+		}
+		keys = append(keys, k.Key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %q has no public keys", path)
 	}
+	return keys, nil
 }
 
 func routeAuthenticator(filter FilterAuthArguments, auth authenticator) authenticator {
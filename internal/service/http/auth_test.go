@@ -1,12 +1,21 @@
 package http
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/syntax"
 )
 
 func Test_basicAuthenticatorInclude(t *testing.T) {
@@ -58,7 +67,8 @@ func Test_basicAuthenticatorInclude(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			auth := args.authenticator()
+			auth, err := args.authenticator()
+			require.NoError(t, err)
 
 			w := httptest.NewRecorder()
 			req, err := http.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
@@ -129,7 +139,8 @@ func Test_basicAuthenticatorExclude(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			auth := args.authenticator()
+			auth, err := args.authenticator()
+			require.NoError(t, err)
 
 			w := httptest.NewRecorder()
 			req, err := http.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
@@ -150,3 +161,262 @@ func Test_basicAuthenticatorExclude(t *testing.T) {
 		})
 	}
 }
+
+func Test_bearerAuthenticator(t *testing.T) {
+	args := AuthArguments{
+		Basic: &BasicAuthArguments{Username: "admin", Password: "password"},
+		Bearer: []BearerAuthArguments{
+			{Token: "ci-token", User: "ci"},
+			{Token: "viewer-token", User: "viewer"},
+		},
+		Roles: []RoleArguments{
+			{Name: "admin", Paths: []string{"/"}, Users: []string{"admin", "ci"}},
+			{Name: "read-only", Paths: []string{"/"}, ExcludePaths: []string{"/-/reload", "/-/support"}, Users: []string{"viewer"}},
+		},
+	}
+	auth, err := args.authenticator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		token  string
+		basic  bool
+		path   string
+		status int
+		user   string
+	}{
+		{name: "admin can reload", token: "ci-token", path: "/-/reload"},
+		{name: "viewer can read", token: "viewer-token", path: "/api/v0/web/components"},
+		{name: "viewer can't reload", token: "viewer-token", path: "/-/reload", status: http.StatusForbidden, user: "viewer"},
+		{name: "viewer can't get a support bundle", token: "viewer-token", path: "/-/support", status: http.StatusForbidden, user: "viewer"},
+		{name: "basic auth user has roles", basic: true, path: "/-/reload"},
+		{name: "invalid token", token: "invalid", path: "/", status: http.StatusUnauthorized},
+		{name: "missing credentials", path: "/", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+			require.NoError(t, err)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.basic {
+				req.SetBasicAuth("admin", "password")
+			}
+
+			err = auth(w, req)
+			if tt.status == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			status, user := deniedRequest(err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.user, user)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, []string{`Basic realm="Restricted"`, `Bearer realm="Restricted"`}, w.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func Test_jwtAuthenticator(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: pub, KeyID: "test", Algorithm: string(jose.EdDSA)}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o644))
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.EdDSA, Key: jose.JSONWebKey{Key: priv, KeyID: "test"}}, nil)
+	require.NoError(t, err)
+	sign := func(claims map[string]any) string {
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		obj, err := signer.Sign(payload)
+		require.NoError(t, err)
+		token, err := obj.CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	args := AuthArguments{
+		JWT: &JWTAuthArguments{
+			JWKSFile:      jwksFile,
+			Issuer:        "https://idp.example.com",
+			Audience:      "alloy",
+			UsernameClaim: "email",
+		},
+		Roles: []RoleArguments{
+			{Name: "sre", Paths: []string{"/"}, Claims: map[string]string{"groups": "sre"}},
+			{Name: "viewer", Paths: []string{"/api/v0/web"}, Users: []string{"viewer@example.com"}},
+		},
+	}
+	auth, err := args.authenticator()
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name   string
+		claims map[string]any
+		path   string
+		status int
+	}{
+		{
+			name:   "group claim grants the role",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "alloy", "exp": exp, "email": "sre@example.com", "groups": []string{"dev", "sre"}},
+			path:   "/-/reload",
+		},
+		{
+			name:   "user is granted the role",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "alloy", "exp": exp, "email": "viewer@example.com"},
+			path:   "/api/v0/web/components",
+		},
+		{
+			name:   "path isn't allowed by the role",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "alloy", "exp": exp, "email": "viewer@example.com"},
+			path:   "/-/reload",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "wrong audience",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "other", "exp": exp, "email": "sre@example.com", "groups": "sre"},
+			path:   "/",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "missing audience",
+			claims: map[string]any{"iss": "https://idp.example.com", "exp": exp, "email": "sre@example.com", "groups": "sre"},
+			path:   "/",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "wrong issuer",
+			claims: map[string]any{"iss": "https://other.example.com", "aud": "alloy", "exp": exp, "email": "sre@example.com", "groups": "sre"},
+			path:   "/",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "expired token",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "alloy", "exp": time.Now().Add(-time.Hour).Unix(), "email": "sre@example.com", "groups": "sre"},
+			path:   "/",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "missing username claim",
+			claims: map[string]any{"iss": "https://idp.example.com", "aud": "alloy", "exp": exp, "groups": "sre"},
+			path:   "/",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+sign(tt.claims))
+
+			err = auth(w, req)
+			if tt.status == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			status, _ := deniedRequest(err)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestAuthArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "roles without authentication",
+			config: `
+				role "admin" {
+					paths = ["/"]
+					users = ["admin"]
+				}`,
+			err: "roles require at least one of the basic, bearer or jwt blocks",
+		},
+		{
+			name: "role without users or claims",
+			config: `
+				bearer {
+					token = "token"
+					user  = "ci"
+				}
+				role "admin" {
+					paths = ["/"]
+				}`,
+			err: `role "admin" must match at least one user or claim`,
+		},
+		{
+			name: "duplicate tokens",
+			config: `
+				bearer {
+					token = "token"
+					user  = "ci"
+				}
+				bearer {
+					token = "token"
+					user  = "other"
+				}`,
+			err: "bearer[1]: token is used by another bearer block",
+		},
+		{
+			name: "jwt without keys",
+			config: `
+				jwt {
+					issuer   = "https://idp.example.com"
+					audience = "alloy"
+				}`,
+			err: "exactly one of jwks_file or jwks_url must be set",
+		},
+		{
+			name: "jwt without audience",
+			config: `
+				jwt {
+					jwks_url = "https://idp.example.com/.well-known/jwks.json"
+					issuer   = "https://idp.example.com"
+				}`,
+			err: `missing required attribute "audience"`,
+		},
+		{
+			name: "jwt with empty audience",
+			config: `
+				jwt {
+					jwks_url = "https://idp.example.com/.well-known/jwks.json"
+					issuer   = "https://idp.example.com"
+					audience = ""
+				}`,
+			err: "audience must not be empty",
+		},
+		{
+			name: "jwt with empty issuer",
+			config: `
+				jwt {
+					jwks_url = "https://idp.example.com/.well-known/jwks.json"
+					issuer   = ""
+					audience = "alloy"
+				}`,
+			err: "issuer must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args AuthArguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -202,8 +202,11 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 			err := s.authenticator(w, r)
 			s.authenticatorMut.RUnlock()
 			if err != nil {
-				level.Info(s.log).Log("msg", "failed to authenticate request", "path", r.URL.Path, "err", err)
-				w.WriteHeader(http.StatusUnauthorized)
+				// Denied requests are always logged to keep an audit trail.
+				status, user := deniedRequest(err)
+				level.Warn(s.log).Log("msg", "denied request", "method", r.Method, "path", r.URL.Path,
+					"remote_addr", r.RemoteAddr, "user", user, "status", status, "err", err)
+				w.WriteHeader(status)
 				return
 			}
 
@@ -341,38 +344,112 @@ func (s *Service) generateSupportBundleHandler(host service.Host) func(rw http.R
 			}
 			duration = time.Duration(d) * time.Second
 		}
-		ctx, cancel := context.WithTimeout(context.Background(), duration)
-		defer cancel()
-
-		var logsBuffer bytes.Buffer
-		syncBuff := log.NewSyncWriter(&logsBuffer)
-		s.globalLogger.SetTemporaryWriter(syncBuff)
-		defer func() {
-			s.globalLogger.RemoveTemporaryWriter()
-		}()
 
-		// Get and redact the cached remote config.
-		cachedConfig, err := remoteCfgRedactedCachedConfig(host)
-		if err != nil {
-			level.Debug(s.log).Log("msg", "failed to get cached remote config", "err", err)
+		switch scope := r.URL.Query().Get("scope"); scope {
+		case "", "node":
+		case "cluster":
+			if duration <= clusterSupportBundleOverhead {
+				http.Error(rw, fmt.Sprintf("duration value should be larger than %s with the cluster scope", clusterSupportBundleOverhead), http.StatusBadRequest)
+				return
+			}
+			s.serveClusterSupportBundle(rw, r, host, duration)
+			return
+		default:
+			http.Error(rw, fmt.Sprintf("unknown scope %q, should be one of \"node\" or \"cluster\"", scope), http.StatusBadRequest)
+			return
 		}
 
-		// Ensure the sources are written using the printer as it will handle
-		// secret redaction.
-		sources := redactedSources(s.sources)
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
 
-		bundle, err := ExportSupportBundle(ctx, s.opts.BundleContext.RuntimeFlags, s.opts.HTTPListenAddr, sources, cachedConfig, s.Data().(Data).DialFunc)
+		bundle, logsBuffer, err := s.exportSupportBundle(ctx, host)
 		if err != nil {
 			http.Error(rw, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		if err := ServeSupportBundle(rw, bundle, &logsBuffer); err != nil {
+		if err := ServeSupportBundle(rw, bundle, logsBuffer); err != nil {
 			http.Error(rw, err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 }
 
+// clusterSupportBundleOverhead is the part of the duration of a cluster
+// support bundle which is reserved for fetching the bundles of peers and
+// serving the result.
+const clusterSupportBundleOverhead = 5 * time.Second
+
+// serveClusterSupportBundle serves a support bundle which includes the
+// bundles of all the cluster peers, each gathered for the given duration
+// minus clusterSupportBundleOverhead.
+func (s *Service) serveClusterSupportBundle(rw http.ResponseWriter, r *http.Request, host service.Host, duration time.Duration) {
+	profileDuration := duration - clusterSupportBundleOverhead
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	local := func() (map[string][]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, profileDuration)
+		defer cancel()
+
+		bundle, logsBuffer, err := s.exportSupportBundle(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		return supportBundleFiles(bundle, logsBuffer), nil
+	}
+
+	opts := PeerBundleOptions{
+		Client:      s.opts.BundleContext.PeerClient,
+		Scheme:      "http",
+		Duration:    profileDuration,
+		PeerTimeout: profileDuration + clusterSupportBundleOverhead/2,
+	}
+	// Any node can join a cluster which doesn't use TLS, so the credentials
+	// of the request are only forwarded to peers authenticated with the
+	// cluster TLS settings.
+	if s.opts.BundleContext.PeerTLS {
+		opts.Scheme = "https"
+		opts.Authorization = r.Header.Get("Authorization")
+	}
+	bundle, err := ExportClusterSupportBundle(ctx, s.opts.HTTPListenAddr, s.Data().(Data).DialFunc, local, opts)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := ServeClusterSupportBundle(rw, bundle); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportSupportBundle gathers the support bundle of this instance, along with
+// the logs written while gathering it.
+func (s *Service) exportSupportBundle(ctx context.Context, host service.Host) (*Bundle, *bytes.Buffer, error) {
+	var logsBuffer bytes.Buffer
+	syncBuff := log.NewSyncWriter(&logsBuffer)
+	s.globalLogger.SetTemporaryWriter(syncBuff)
+	defer func() {
+		s.globalLogger.RemoveTemporaryWriter()
+	}()
+
+	// Get and redact the cached remote config.
+	cachedConfig, err := remoteCfgRedactedCachedConfig(host)
+	if err != nil {
+		level.Debug(s.log).Log("msg", "failed to get cached remote config", "err", err)
+	}
+
+	// Ensure the sources are written using the printer as it will handle
+	// secret redaction.
+	sources := redactedSources(s.sources)
+
+	bundle, err := ExportSupportBundle(ctx, s.opts.BundleContext.RuntimeFlags, s.opts.HTTPListenAddr, sources, cachedConfig, s.Data().(Data).DialFunc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bundle, &logsBuffer, nil
+}
+
 // SetSources sets the sources on reload to be delivered
 // with the support bundle.
 func (s *Service) SetSources(sources map[string]*ast.File) {
@@ -465,6 +542,17 @@ func (s *Service) componentHandler(getHost func() (service.Host, error), pathPre
 func (s *Service) Update(newConfig any) error {
 	newArgs := newConfig.(Arguments)
 
+	// Build the authenticator first so that invalid settings don't leave the
+	// server half updated.
+	var auth authenticator = allowAuthenticator
+	if newArgs.Auth != nil {
+		var err error
+		auth, err = newArgs.Auth.authenticator()
+		if err != nil {
+			return fmt.Errorf("failed to configure authentication: %w", err)
+		}
+	}
+
 	if newArgs.TLS != nil {
 		var tlsConfig *tls.Config
 		var err error
@@ -496,11 +584,7 @@ func (s *Service) Update(newConfig any) error {
 	}
 
 	s.authenticatorMut.Lock()
-	if newArgs.Auth != nil {
-		s.authenticator = newArgs.Auth.authenticator()
-	} else {
-		s.authenticator = allowAuthenticator
-	}
+	s.authenticator = auth
 	s.authenticatorMut.Unlock()
 
 	return nil
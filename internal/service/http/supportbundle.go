@@ -4,18 +4,28 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/alloy/internal/build"
 	"github.com/grafana/alloy/internal/static/server"
+	"github.com/grafana/ckit/peer"
 	"github.com/mackerelio/go-osstat/uptime"
 	"gopkg.in/yaml.v3"
 )
@@ -23,8 +33,10 @@ import (
 // SupportBundleContext groups the relevant context that is used in the HTTP
 // service config for the support bundle
 type SupportBundleContext struct {
-	DisableSupportBundle bool     // Whether support bundle endpoint should be disabled.
-	RuntimeFlags         []string // Alloy runtime flags to send with support bundle
+	DisableSupportBundle bool         // Whether support bundle endpoint should be disabled.
+	RuntimeFlags         []string     // Alloy runtime flags to send with support bundle
+	PeerClient           *http.Client // Client used to fetch the support bundles of cluster peers.
+	PeerTLS              bool         // Whether PeerClient authenticates peers with the cluster TLS settings.
 }
 
 // Bundle collects all the data that is exposed as a support bundle.
@@ -51,6 +63,7 @@ type Metadata struct {
 	OS           string  `yaml:"os"`
 	Architecture string  `yaml:"architecture"`
 	Uptime       float64 `yaml:"uptime"`
+	ConfigHash   string  `yaml:"config_hash"`
 }
 
 // ExportSupportBundle gathers the information required for the support bundle.
@@ -95,6 +108,7 @@ func ExportSupportBundle(ctx context.Context, runtimeFlags []string, srvAddress
 		OS:           runtime.GOOS,
 		Architecture: runtime.GOARCH,
 		Uptime:       ut.Seconds(),
+		ConfigHash:   configHash(sources, remoteCfg),
 	}
 	meta, err := yaml.Marshal(m)
 	if err != nil {
@@ -178,6 +192,17 @@ func retrieveAPIEndpoint(httpClient http.Client, srvAddress, endpoint string) ([
 	return res, nil
 }
 
+// configHash returns a hash of the configuration included in the bundle, so
+// that bundles of instances running the same configuration can be matched.
+func configHash(sources map[string][]byte, remoteCfg []byte) string {
+	h := sha256.New()
+	for _, k := range slices.Sorted(maps.Keys(sources)) {
+		h.Write(sources[k])
+	}
+	h.Write(remoteCfg)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func retrieveEnvironmentVariables() []string {
 	relevantVariables := []string{
 		"AUTOMEMLIMIT",
@@ -211,6 +236,25 @@ func ServeSupportBundle(rw http.ResponseWriter, b *Bundle, logsBuf *bytes.Buffer
 	rw.Header().Set("Content-Type", "application/zip")
 	rw.Header().Set("Content-Disposition", "attachment; filename=\"alloy-support-bundle.zip\"")
 
+	for fn, b := range supportBundleFiles(b, logsBuf) {
+		if b != nil {
+			path := append([]string{"alloy-support-bundle"}, strings.Split(fn, "/")...)
+			if err := writeByteSlice(zw, b, path...); err != nil {
+				return err
+			}
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to flush the zip writer: %v", err)
+	}
+	return nil
+}
+
+// supportBundleFiles returns the files of the support bundle, keyed by their
+// slash-separated path within the bundle.
+func supportBundleFiles(b *Bundle, logsBuf *bytes.Buffer) map[string][]byte {
 	zipStructure := map[string][]byte{
 		"alloy-metadata.yaml":            b.meta,
 		"alloy-components.json":          b.components,
@@ -232,33 +276,237 @@ func ServeSupportBundle(rw http.ResponseWriter, b *Bundle, logsBuf *bytes.Buffer
 	}
 
 	for p, s := range b.sources {
-		zipStructure[filepath.Join("sources", filepath.Base(p))] = s
+		zipStructure["sources/"+filepath.Base(p)] = s
 	}
 
-	for fn, b := range zipStructure {
-		if b != nil {
-			path := append([]string{"alloy-support-bundle"}, strings.Split(fn, "/")...)
-			if err := writeByteSlice(zw, b, path...); err != nil {
-				return err
+	return zipStructure
+}
+
+func writeByteSlice(zw *zip.Writer, b []byte, fn ...string) error {
+	f, err := zw.Create(filepath.Join(fn...))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClusterSummary describes the peers whose support bundles are included in a
+// cluster support bundle.
+type ClusterSummary struct {
+	Peers []PeerSummary `yaml:"peers"`
+}
+
+// PeerSummary describes a cluster peer and the outcome of gathering its
+// support bundle.
+type PeerSummary struct {
+	Name         string `yaml:"name"`
+	Addr         string `yaml:"addr"`
+	Self         bool   `yaml:"self"`
+	State        string `yaml:"state"`
+	BuildVersion string `yaml:"build_version,omitempty"`
+	ConfigHash   string `yaml:"config_hash,omitempty"`
+	Error        string `yaml:"error,omitempty"`
+}
+
+// ClusterBundle holds the support bundles of the peers of a cluster.
+type ClusterBundle struct {
+	summary ClusterSummary
+	// nodes holds the files of the support bundle of each peer, keyed by the
+	// peer name.
+	nodes map[string]map[string][]byte
+}
+
+// maxPeerSupportBundleSize is the maximum size of the support bundle of a
+// peer. It is a variable so that tests can lower it.
+var maxPeerSupportBundleSize = 256 << 20
+
+// PeerBundleOptions configures how the support bundles of peers are fetched.
+type PeerBundleOptions struct {
+	Client        *http.Client  // Client used to reach the peers' HTTP servers.
+	Scheme        string        // Scheme used to reach the peers' HTTP servers.
+	Authorization string        // Authorization header forwarded to peers, if any.
+	Duration      time.Duration // Duration of the support bundle of each peer.
+	PeerTimeout   time.Duration // Timeout of the request to each peer.
+}
+
+// ExportClusterSupportBundle gathers the support bundle of every peer of the
+// cluster in parallel. The local bundle is passed in as local; the bundles of
+// other peers are fetched from their /-/support endpoint. Peers which fail to
+// deliver a bundle are listed in the summary along with the error.
+func ExportClusterSupportBundle(ctx context.Context, srvAddress string, dialContext server.DialContextFunc, local func() (map[string][]byte, error), opts PeerBundleOptions) (*ClusterBundle, error) {
+	var localClient http.Client
+	localClient.Transport = &http.Transport{DialContext: dialContext}
+
+	rawPeers, err := retrieveAPIEndpoint(localClient, srvAddress, "api/v0/web/peers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get peer details: %s", err)
+	}
+	var peers []peer.Peer
+	if err := json.Unmarshal(rawPeers, &peers); err != nil {
+		return nil, fmt.Errorf("failed to decode peer details: %s", err)
+	}
+	slices.SortFunc(peers, func(a, b peer.Peer) int { return strings.Compare(a.Name, b.Name) })
+
+	var (
+		wg        sync.WaitGroup
+		summaries = make([]PeerSummary, len(peers))
+		files     = make([]map[string][]byte, len(peers))
+		client    = opts.Client
+	)
+	if client == nil {
+		client = &http.Client{}
+	}
+	for i, p := range peers {
+		summaries[i] = PeerSummary{Name: p.Name, Addr: p.Addr, Self: p.Self, State: p.State.String()}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if p.Self {
+				files[i], err = local()
+			} else {
+				files[i], err = fetchPeerSupportBundle(ctx, client, p.Addr, opts)
+			}
+			if err != nil {
+				summaries[i].Error = err.Error()
+				return
+			}
+
+			var m Metadata
+			if err := yaml.Unmarshal(files[i]["alloy-metadata.yaml"], &m); err == nil {
+				summaries[i].BuildVersion = m.BuildVersion
+				summaries[i].ConfigHash = m.ConfigHash
 			}
+		}()
+	}
+	wg.Wait()
+
+	bundle := &ClusterBundle{
+		summary: ClusterSummary{Peers: summaries},
+		nodes:   make(map[string]map[string][]byte, len(peers)),
+	}
+	for i, p := range peers {
+		if files[i] != nil {
+			bundle.nodes[p.Name] = files[i]
 		}
 	}
+	return bundle, nil
+}
 
-	err := zw.Close()
+// fetchPeerSupportBundle fetches the support bundle of the peer at addr and
+// returns its files.
+func fetchPeerSupportBundle(ctx context.Context, client *http.Client, addr string, opts PeerBundleOptions) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.PeerTimeout)
+	defer cancel()
+
+	u := url.URL{
+		Scheme:   opts.Scheme,
+		Host:     addr,
+		Path:     "/-/support",
+		RawQuery: url.Values{"duration": {strconv.Itoa(int(opts.Duration.Seconds()))}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return fmt.Errorf("failed to flush the zip writer: %v", err)
+		return nil, err
 	}
-	return nil
+	if opts.Authorization != "" {
+		req.Header.Set("Authorization", opts.Authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxPeerSupportBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxPeerSupportBundleSize {
+		return nil, fmt.Errorf("support bundle is larger than %d bytes", maxPeerSupportBundleSize)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid support bundle: %w", err)
+	}
+	files := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		// Bundles written on Windows use backslashes as separators.
+		name := path.Clean(strings.ReplaceAll(f.Name, `\`, "/"))
+		name = strings.TrimPrefix(name, "alloy-support-bundle/")
+		if f.FileInfo().IsDir() || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid support bundle: %w", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid support bundle: %w", err)
+		}
+		files[name] = data
+	}
+	return files, nil
 }
 
-func writeByteSlice(zw *zip.Writer, b []byte, fn ...string) error {
-	f, err := zw.Create(filepath.Join(fn...))
+// ServeClusterSupportBundle serves the cluster support bundle as a zip file
+// over the given http.ResponseWriter. The bundle of each peer is stored in
+// its own directory under nodes/.
+func ServeClusterSupportBundle(rw http.ResponseWriter, b *ClusterBundle) error {
+	summary, err := yaml.Marshal(b.summary)
 	if err != nil {
+		return fmt.Errorf("failed to marshal cluster summary: %s", err)
+	}
+
+	zw := zip.NewWriter(rw)
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Disposition", "attachment; filename=\"alloy-cluster-support-bundle.zip\"")
+
+	if err := writeByteSlice(zw, summary, "alloy-cluster-support-bundle", "cluster-summary.yaml"); err != nil {
 		return err
 	}
-	_, err = f.Write(b)
+	for name, files := range b.nodes {
+		for fn, b := range files {
+			path := append([]string{"alloy-cluster-support-bundle", "nodes", nodeDirName(name)}, strings.Split(fn, "/")...)
+			if err := writeByteSlice(zw, b, path...); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = zw.Close()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to flush the zip writer: %v", err)
 	}
 	return nil
 }
+
+// nodeDirName returns a directory name for the peer with the given name.
+func nodeDirName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':':
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_" + name
+	}
+	return name
+}
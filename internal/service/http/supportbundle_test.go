@@ -0,0 +1,161 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportClusterSupportBundle(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/support" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "3", r.URL.Query().Get("duration"))
+		assert.Empty(t, r.URL.Query().Get("scope"))
+
+		zw := zip.NewWriter(w)
+		for name, content := range map[string]string{
+			"alloy-support-bundle/alloy-metadata.yaml": "build_version: v1.2.3\nconfig_hash: abc\n",
+			"alloy-support-bundle/pprof/cpu.pprof":     "cpu",
+			"../escape.txt":                            "ignored",
+		} {
+			f, err := zw.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+	}))
+	defer remote.Close()
+
+	disabled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("support bundle generation is disabled"))
+	}))
+	defer disabled.Close()
+
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v0/web/peers", r.URL.Path)
+		require.NoError(t, json.NewEncoder(w).Encode([]peer.Peer{
+			{Name: "self", Addr: "127.0.0.1:12345", Self: true, State: peer.StateParticipant},
+			{Name: "remote", Addr: strings.TrimPrefix(remote.URL, "http://"), State: peer.StateParticipant},
+			{Name: "disabled", Addr: strings.TrimPrefix(disabled.URL, "http://"), State: peer.StateTerminating},
+		}))
+	}))
+	defer local.Close()
+
+	var dialer net.Dialer
+	bundle, err := ExportClusterSupportBundle(t.Context(), strings.TrimPrefix(local.URL, "http://"), dialer.DialContext,
+		func() (map[string][]byte, error) {
+			return map[string][]byte{"alloy-metadata.yaml": []byte("build_version: v1.2.4\nconfig_hash: def\n")}, nil
+		},
+		PeerBundleOptions{
+			Scheme:        "http",
+			Authorization: "Bearer token",
+			Duration:      3 * time.Second,
+			PeerTimeout:   5 * time.Second,
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, ClusterSummary{Peers: []PeerSummary{
+		{
+			Name:  "disabled",
+			Addr:  strings.TrimPrefix(disabled.URL, "http://"),
+			State: peer.StateTerminating.String(),
+			Error: "unexpected status 403 Forbidden: support bundle generation is disabled",
+		},
+		{
+			Name:         "remote",
+			Addr:         strings.TrimPrefix(remote.URL, "http://"),
+			State:        peer.StateParticipant.String(),
+			BuildVersion: "v1.2.3",
+			ConfigHash:   "abc",
+		},
+		{
+			Name:         "self",
+			Addr:         "127.0.0.1:12345",
+			Self:         true,
+			State:        peer.StateParticipant.String(),
+			BuildVersion: "v1.2.4",
+			ConfigHash:   "def",
+		},
+	}}, bundle.summary)
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, ServeClusterSupportBundle(rec, bundle))
+
+	b := rec.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(content)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"alloy-cluster-support-bundle/cluster-summary.yaml",
+		"alloy-cluster-support-bundle/nodes/remote/alloy-metadata.yaml",
+		"alloy-cluster-support-bundle/nodes/remote/pprof/cpu.pprof",
+		"alloy-cluster-support-bundle/nodes/self/alloy-metadata.yaml",
+	}, slices.Collect(maps.Keys(files)))
+	assert.Equal(t, "cpu", files["alloy-cluster-support-bundle/nodes/remote/pprof/cpu.pprof"])
+
+	var summary ClusterSummary
+	require.NoError(t, yaml.Unmarshal([]byte(files["alloy-cluster-support-bundle/cluster-summary.yaml"]), &summary))
+	assert.Equal(t, bundle.summary, summary)
+}
+
+func TestFetchPeerSupportBundle_Timeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	_, err := fetchPeerSupportBundle(context.Background(), slow.Client(), strings.TrimPrefix(slow.URL, "http://"), PeerBundleOptions{
+		Scheme:      "http",
+		Duration:    time.Second,
+		PeerTimeout: 100 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFetchPeerSupportBundle_TooLarge(t *testing.T) {
+	defer func(size int) { maxPeerSupportBundleSize = size }(maxPeerSupportBundleSize)
+	maxPeerSupportBundleSize = 1024
+
+	large := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte{0}, 2048))
+	}))
+	defer large.Close()
+
+	_, err := fetchPeerSupportBundle(context.Background(), large.Client(), strings.TrimPrefix(large.URL, "http://"), PeerBundleOptions{
+		Scheme:      "http",
+		Duration:    time.Second,
+		PeerTimeout: time.Second,
+	})
+	require.EqualError(t, err, "support bundle is larger than 1024 bytes")
+}
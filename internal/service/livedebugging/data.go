@@ -9,6 +9,7 @@ const (
 	OtelMetric       DataType = "otel_metric"
 	OtelLog          DataType = "otel_log"
 	OtelTrace        DataType = "otel_trace"
+	PyroscopeProfile DataType = "pyroscope_profile"
 )
 
 type DataOption func(Data) Data
@@ -2,11 +2,13 @@ package remotecfg
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/grafana/alloy/internal/alloyseed"
 	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/signature"
 	"github.com/grafana/alloy/syntax"
 )
 
@@ -17,9 +19,72 @@ type Arguments struct {
 	Name             string                   `alloy:"name,attr,optional"`
 	Attributes       map[string]string        `alloy:"attributes,attr,optional"`
 	PollFrequency    time.Duration            `alloy:"poll_frequency,attr,optional"`
+	Protocol         Protocol                 `alloy:"protocol,attr,optional"`
+	Signature        *signature.Arguments     `alloy:"signature,block,optional"`
+	Rollback         *RollbackArguments       `alloy:"rollback,block,optional"`
 	HTTPClientConfig *config.HTTPClientConfig `alloy:",squash"`
 }
 
+// Protocol is the protocol used to communicate with the remote server.
+type Protocol string
+
+const (
+	// ProtocolConnect uses the Connect-based collector API.
+	ProtocolConnect Protocol = "connect"
+	// ProtocolOpAMP acts as an OpAMP agent.
+	ProtocolOpAMP Protocol = "opamp"
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Protocol) MarshalText() ([]byte, error) {
+	return []byte(p), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Protocol) UnmarshalText(text []byte) error {
+	switch Protocol(text) {
+	case ProtocolConnect, ProtocolOpAMP:
+		*p = Protocol(text)
+		return nil
+	default:
+		return fmt.Errorf("unknown protocol %q, must be one of %q or %q", string(text), ProtocolConnect, ProtocolOpAMP)
+	}
+}
+
+// RollbackArguments configures the automatic rollback of remote
+// configurations which leave components unhealthy.
+type RollbackArguments struct {
+	ProbationPeriod    time.Duration `alloy:"probation_period,attr,optional"`
+	CheckInterval      time.Duration `alloy:"check_interval,attr,optional"`
+	UnhealthyThreshold float64       `alloy:"unhealthy_threshold,attr,optional"`
+}
+
+// DefaultRollbackArguments holds default settings for RollbackArguments.
+var DefaultRollbackArguments = RollbackArguments{
+	ProbationPeriod:    time.Minute,
+	CheckInterval:      5 * time.Second,
+	UnhealthyThreshold: 0.5,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (r *RollbackArguments) SetToDefault() {
+	*r = DefaultRollbackArguments
+}
+
+// Validate implements syntax.Validator.
+func (r *RollbackArguments) Validate() error {
+	if r.ProbationPeriod <= 0 {
+		return fmt.Errorf("probation_period must be greater than 0")
+	}
+	if r.CheckInterval <= 0 || r.CheckInterval > r.ProbationPeriod {
+		return fmt.Errorf("check_interval must be greater than 0 and at most probation_period")
+	}
+	if r.UnhealthyThreshold < 0 || r.UnhealthyThreshold >= 1 {
+		return fmt.Errorf("unhealthy_threshold must be between 0 and 1, got %v", r.UnhealthyThreshold)
+	}
+	return nil
+}
+
 // Make sure Arguments implements the syntax.Defaulter interface
 var _ syntax.Defaulter = (*Arguments)(nil)
 
@@ -32,6 +97,7 @@ func getDefaultArguments() Arguments {
 		ID:               alloyseed.Get().UID,
 		Attributes:       make(map[string]string),
 		PollFrequency:    1 * time.Minute,
+		Protocol:         ProtocolConnect,
 		HTTPClientConfig: config.CloneDefaultHTTPClientConfig(),
 	}
 }
@@ -53,6 +119,21 @@ func (a *Arguments) Validate() error {
 		}
 	}
 
+	if a.Protocol == ProtocolOpAMP && a.URL != "" {
+		u, err := url.Parse(a.URL)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		switch u.Scheme {
+		case "ws", "wss", "http", "https":
+		default:
+			return fmt.Errorf("url must use one of the ws, wss, http or https schemes with the opamp protocol, got %q", u.Scheme)
+		}
+		if a.HTTPClientConfig != nil && a.HTTPClientConfig.OAuth2 != nil {
+			return fmt.Errorf("oauth2 is not supported with the opamp protocol")
+		}
+	}
+
 	// We must explicitly Validate because HTTPClientConfig is squashed and it
 	// won't run otherwise
 	if a.HTTPClientConfig != nil {
@@ -23,6 +23,7 @@ func TestGetDefaultArguments(t *testing.T) {
 	assert.NotNil(t, defaults.Attributes)
 	assert.Empty(t, defaults.Attributes)
 	assert.Equal(t, 1*time.Minute, defaults.PollFrequency)
+	assert.Equal(t, ProtocolConnect, defaults.Protocol)
 	assert.NotNil(t, defaults.HTTPClientConfig)
 }
 
@@ -322,3 +323,66 @@ func TestArguments_Hash_Format(t *testing.T) {
 	assert.Regexp(t, "^[a-f0-9]+$", hash)
 	assert.Len(t, hash, 8) // fnv.New32() produces 4 bytes = 8 hex chars
 }
+
+func TestArguments_Validate_Protocol(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		url         string
+		protocol    Protocol
+		oauth2      bool
+		expectedErr string
+	}{
+		{name: "connect", url: "https://example.com", protocol: ProtocolConnect},
+		{name: "opamp websocket", url: "wss://example.com/v1/opamp", protocol: ProtocolOpAMP},
+		{name: "opamp http", url: "https://example.com/v1/opamp", protocol: ProtocolOpAMP},
+		{
+			name:        "opamp invalid scheme",
+			url:         "grpc://example.com",
+			protocol:    ProtocolOpAMP,
+			expectedErr: `url must use one of the ws, wss, http or https schemes with the opamp protocol, got "grpc"`,
+		},
+		{
+			name:        "opamp oauth2",
+			url:         "wss://example.com/v1/opamp",
+			protocol:    ProtocolOpAMP,
+			oauth2:      true,
+			expectedErr: "oauth2 is not supported with the opamp protocol",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := getDefaultArguments()
+			args.URL = tc.url
+			args.Protocol = tc.protocol
+			if tc.oauth2 {
+				args.HTTPClientConfig.OAuth2 = &config.OAuth2Config{ClientID: "id"}
+			}
+
+			err := args.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestProtocol_UnmarshalText(t *testing.T) {
+	var p Protocol
+	require.NoError(t, p.UnmarshalText([]byte("opamp")))
+	require.Equal(t, ProtocolOpAMP, p)
+
+	require.EqualError(t, p.UnmarshalText([]byte("grpc")), `unknown protocol "grpc", must be one of "connect" or "opamp"`)
+}
+
+func TestRollbackArguments_Validate(t *testing.T) {
+	args := DefaultRollbackArguments
+	require.NoError(t, args.Validate())
+
+	args.CheckInterval = 2 * time.Minute
+	require.EqualError(t, args.Validate(), "check_interval must be greater than 0 and at most probation_period")
+
+	args = DefaultRollbackArguments
+	args.UnhealthyThreshold = 1
+	require.EqualError(t, args.Validate(), "unhealthy_threshold must be between 0 and 1, got 1")
+}
@@ -7,11 +7,13 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	collectorv1 "github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1"
+	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service"
 	"github.com/grafana/alloy/internal/util/jitter"
@@ -89,6 +91,36 @@ type configManager struct {
 
 	// lastSentEffectiveConfig tracks the last effective config sent to the server to avoid redundant updates
 	lastSentEffectiveConfig *collectorv1.EffectiveConfig
+
+	// rollback configures the probation of new remote configurations. It is nil
+	// if automatic rollback is disabled.
+	rollback *RollbackArguments
+
+	// lastGoodConfig is the last configuration which passed probation, or was
+	// loaded while no probation was needed. It is restored on rollback.
+	lastGoodConfig []byte
+
+	// probation tracks the remote configuration on probation, if any.
+	probation *probation
+
+	// listComponents returns the components of the controller along with
+	// their health. It defaults to listing the components of ctrl.
+	listComponents func() ([]*component.Info, error)
+}
+
+// probation is a remote configuration which was loaded, but is rolled back if
+// it leaves too many components unhealthy before its deadline.
+type probation struct {
+	config   []byte
+	hash     string
+	deadline time.Time
+	ticker   *time.Ticker
+}
+
+// hostProvider is implemented by controllers which expose the host of their
+// components.
+type hostProvider interface {
+	GetHost() service.Host
 }
 
 func newConfigManager(metrics *metrics, logger log.Logger, remotecfgPath string, configPath string) *configManager {
@@ -256,10 +288,11 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 
 		// Only mark APPLIED if the last received remote config matches the currently
 		// loaded config. This prevents flipping to APPLIED when the server continues
-		// to serve a bad config that failed to load previously.
+		// to serve a bad config that failed to load previously, or while the
+		// config is still on probation.
 		loaded := cm.getLastLoadedCfgHash()
 		received := cm.getLastReceivedCfgHash()
-		if loaded != "" && received != "" && loaded == received {
+		if loaded != "" && received != "" && loaded == received && !cm.onProbation() {
 			cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
 		} else {
 			level.Debug(cm.logger).Log("msg", "not modified but loaded config does not match last received; retaining status", "loaded_hash", loaded, "received_hash", received)
@@ -296,6 +329,11 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 
 	if alreadyReceived {
 		level.Debug(cm.logger).Log("msg", "skipping over API response since it matched the last received one", "config_hash", newConfigHash)
+		// The config may have been served again after a rejected one, in which
+		// case it's still running and must be reported as such.
+		if alreadyLoaded && !cm.onProbation() {
+			cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+		}
 		return nil
 	}
 
@@ -307,15 +345,20 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 	// to reload the config in this case since it is already loaded.
 	if alreadyLoaded {
 		level.Debug(cm.logger).Log("msg", "skipping over API response since it matched the last loaded one", "config_hash", newConfigHash)
-		// Set status to APPLIED since the new remote config was previously loaded.
-		cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+		// Set status to APPLIED since the new remote config was previously loaded,
+		// unless it's still on probation.
+		if !cm.onProbation() {
+			cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+		}
 		return nil
 	}
 
 	level.Info(cm.logger).Log("msg", "attempting to parse and load new remote configuration", "config_hash", newConfigHash)
 
-	// Set status to APPLYING when we start processing remote config
+	// Set status to APPLYING when we start processing remote config. Any
+	// configuration on probation is replaced, so its probation ends.
 	cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLYING, "")
+	cm.stopProbation()
 	err = cm.parseAndLoad(b)
 	if err != nil {
 		// Failed to parse/load the configuration - received hash is recorded, but loaded hash unchanged
@@ -343,6 +386,7 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 			}
 
 			level.Info(cm.logger).Log("msg", "successfully restored cached configuration")
+			cm.setLastLoadedCfgHash(getHash(cachedConfig))
 			cm.metrics.lastLoadSuccess.Set(1)
 			return nil
 		}
@@ -354,6 +398,14 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 	cm.setLastLoadedCfgHash(newConfigHash)
 	cm.metrics.lastLoadSuccess.Set(1)
 
+	// The configuration stays APPLYING and isn't cached until it passes
+	// probation.
+	if cm.startProbation(b, newConfigHash) {
+		level.Info(cm.logger).Log("msg", "loaded remote configuration, starting probation",
+			"config_hash", newConfigHash, "config_size", len(b))
+		return nil
+	}
+
 	// Set status to APPLIED for successful remote config load and notify immediately
 	// so the server knows about both the status change and the effective config update
 	cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
@@ -362,10 +414,188 @@ func (cm *configManager) fetchLoadRemoteConfig(getAPIConfig func() (*collectorv1
 		"config_hash", newConfigHash, "config_size", len(b))
 
 	// If successful, flush to disk and keep a copy.
+	cm.commitConfig(b)
+	return nil
+}
+
+// commitConfig records b as the last good configuration and flushes it to the
+// on-disk cache.
+func (cm *configManager) commitConfig(b []byte) {
+	cm.mut.Lock()
+	cm.lastGoodConfig = b
+	cm.mut.Unlock()
+
 	cm.setCachedConfig(b)
+}
+
+// setRollback updates the probation settings of new configurations.
+func (cm *configManager) setRollback(r *RollbackArguments) {
+	cm.mut.Lock()
+	cm.rollback = r
+	p := cm.probation
+	cm.mut.Unlock()
+
+	// Accept the configuration on probation if rollback gets disabled.
+	if r == nil && p != nil {
+		cm.passProbation(p)
+	}
+}
+
+// startProbation puts a newly loaded configuration on probation. It returns
+// false if rollback is disabled, or if there's no good configuration to roll
+// back to.
+func (cm *configManager) startProbation(b []byte, hash string) bool {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+
+	if cm.rollback == nil || len(cm.lastGoodConfig) == 0 {
+		return false
+	}
+
+	cm.stopProbationLocked()
+	cm.probation = &probation{
+		config:   b,
+		hash:     hash,
+		deadline: time.Now().Add(cm.rollback.ProbationPeriod),
+		ticker:   time.NewTicker(cm.rollback.CheckInterval),
+	}
+	return true
+}
+
+// stopProbation abandons the probation of the current configuration, if any.
+func (cm *configManager) stopProbation() {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+	cm.stopProbationLocked()
+}
+
+// endProbation ends the probation p. It returns false if p is no longer the
+// current probation, for example because it was replaced by a newer
+// configuration while its health was being checked.
+func (cm *configManager) endProbation(p *probation) bool {
+	cm.mut.Lock()
+	defer cm.mut.Unlock()
+	if cm.probation != p {
+		return false
+	}
+	cm.stopProbationLocked()
+	return true
+}
+
+func (cm *configManager) stopProbationLocked() {
+	if cm.probation != nil {
+		cm.probation.ticker.Stop()
+		cm.probation = nil
+	}
+}
+
+func (cm *configManager) onProbation() bool {
+	cm.mut.RLock()
+	defer cm.mut.RUnlock()
+	return cm.probation != nil
+}
+
+// getProbationC returns the channel on which the health of the configuration
+// on probation must be checked. It returns nil if there's no probation.
+func (cm *configManager) getProbationC() <-chan time.Time {
+	cm.mut.RLock()
+	defer cm.mut.RUnlock()
+	if cm.probation != nil {
+		return cm.probation.ticker.C
+	}
 	return nil
 }
 
+// checkProbation checks the health of the components of the configuration
+// on probation. The configuration is rolled back if the ratio of unhealthy
+// components exceeds the threshold, and accepted once the probation period
+// is over.
+func (cm *configManager) checkProbation() {
+	cm.mut.RLock()
+	p, rollback := cm.probation, cm.rollback
+	cm.mut.RUnlock()
+
+	if p == nil || rollback == nil {
+		return
+	}
+
+	infos, err := cm.getComponentsHealth()
+	if err != nil {
+		level.Warn(cm.logger).Log("msg", "failed to check component health of the configuration on probation", "err", err)
+		return
+	}
+
+	var unhealthy []string
+	for _, info := range infos {
+		if info.Health.Health == component.HealthTypeUnhealthy {
+			unhealthy = append(unhealthy, info.ID.String())
+		}
+	}
+	if len(infos) > 0 && float64(len(unhealthy))/float64(len(infos)) > rollback.UnhealthyThreshold {
+		reason := fmt.Sprintf("rolled back: %d of %d components unhealthy after applying the configuration: %s",
+			len(unhealthy), len(infos), strings.Join(unhealthy, ", "))
+		cm.rollbackProbation(p, reason)
+		return
+	}
+
+	if !time.Now().Before(p.deadline) {
+		cm.passProbation(p)
+	}
+}
+
+func (cm *configManager) getComponentsHealth() ([]*component.Info, error) {
+	cm.mut.RLock()
+	listComponents, ctrl := cm.listComponents, cm.ctrl
+	cm.mut.RUnlock()
+
+	if listComponents != nil {
+		return listComponents()
+	}
+	hp, ok := ctrl.(hostProvider)
+	if !ok {
+		return nil, fmt.Errorf("controller doesn't expose component health")
+	}
+	return hp.GetHost().ListComponents("", component.InfoOptions{GetHealth: true})
+}
+
+// passProbation accepts the configuration on probation.
+func (cm *configManager) passProbation(p *probation) {
+	if !cm.endProbation(p) {
+		return
+	}
+
+	level.Info(cm.logger).Log("msg", "remote configuration passed probation", "config_hash", p.hash)
+	cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+	cm.commitConfig(p.config)
+}
+
+// rollbackProbation restores the last good configuration in place of the
+// configuration on probation. The hash of the rejected configuration stays
+// recorded as the last received one, so that it isn't loaded again until the
+// remote configuration changes.
+func (cm *configManager) rollbackProbation(p *probation, reason string) {
+	if !cm.endProbation(p) {
+		return
+	}
+
+	level.Error(cm.logger).Log("msg", "rolling back remote configuration which failed probation", "config_hash", p.hash, "reason", reason)
+	cm.metrics.rollbacks.Inc()
+	cm.metrics.lastLoadSuccess.Set(0)
+	cm.setRemoteConfigStatus(collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, reason)
+
+	cm.mut.RLock()
+	lastGood := cm.lastGoodConfig
+	cm.mut.RUnlock()
+
+	if err := cm.parseAndLoad(lastGood); err != nil {
+		level.Error(cm.logger).Log("msg", "failed to restore last good configuration", "err", err)
+		return
+	}
+	cm.setLastLoadedCfgHash(getHash(lastGood))
+	cm.metrics.lastLoadSuccess.Set(1)
+	level.Info(cm.logger).Log("msg", "successfully restored last good configuration")
+}
+
 func (cm *configManager) fetchLoadLocalConfig() {
 	b, err := cm.getCachedConfig()
 	if err != nil {
@@ -383,6 +613,13 @@ func (cm *configManager) fetchLoadLocalConfig() {
 	cacheHash := getHash(b)
 	cm.setLastLoadedCfgHash(cacheHash)
 
+	// The cached configuration replaces any configuration on probation and
+	// becomes the one to roll back to.
+	cm.stopProbation()
+	cm.mut.Lock()
+	cm.lastGoodConfig = b
+	cm.mut.Unlock()
+
 	level.Info(cm.logger).Log("msg", "successfully loaded configuration from cache",
 		"config_hash", cacheHash, "config_size", len(b), "cache_path", cm.getCachedConfigPath())
 }
@@ -396,6 +633,7 @@ func (cm *configManager) cleanup() {
 		cm.ticker.Stop()
 		cm.ticker = nil
 	}
+	cm.stopProbationLocked()
 }
 
 // Getters for safe access to configManager fields
@@ -15,6 +15,7 @@ type metrics struct {
 	lastFetchSuccessTime   prometheus.Gauge
 	totalAttempts          prometheus.Counter
 	getConfigTime          prometheus.Histogram
+	rollbacks              prometheus.Counter
 }
 
 func registerMetrics(reg prometheus.Registerer) *metrics {
@@ -73,6 +74,12 @@ func registerMetrics(reg prometheus.Registerer) *metrics {
 				Help: "Duration of remote configuration requests.",
 			},
 		),
+		rollbacks: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "remotecfg_rollbacks_total",
+				Help: "Remote configurations rolled back after failing probation",
+			},
+		),
 	}
 
 	// Register metrics safely - ignore AlreadyRegisteredError
@@ -83,6 +90,7 @@ func registerMetrics(reg prometheus.Registerer) *metrics {
 	safeRegister(reg, m.totalAttempts)
 	safeRegister(reg, m.lastFetchSuccessTime)
 	safeRegister(reg, m.getConfigTime)
+	safeRegister(reg, m.rollbacks)
 
 	return m
 }
@@ -28,6 +28,7 @@ func TestRegisterMetrics(t *testing.T) {
 	assert.NotNil(t, m.lastFetchSuccessTime)
 	assert.NotNil(t, m.totalAttempts)
 	assert.NotNil(t, m.getConfigTime)
+	assert.NotNil(t, m.rollbacks)
 }
 
 func TestMetricsRegistration(t *testing.T) {
@@ -53,6 +54,7 @@ func TestMetricsRegistration(t *testing.T) {
 		"remotecfg_load_attempts_total",
 		"remotecfg_last_load_success_timestamp_seconds",
 		"remotecfg_request_duration_seconds",
+		"remotecfg_rollbacks_total",
 	}
 
 	// Check that all expected metrics are registered
@@ -99,6 +101,7 @@ func TestMetricNames(t *testing.T) {
 		"remotecfg_load_attempts_total":                 "Attempts to load remote configuration",
 		"remotecfg_last_load_success_timestamp_seconds": "Timestamp of the last successful remote configuration load",
 		"remotecfg_request_duration_seconds":            "Duration of remote configuration requests.",
+		"remotecfg_rollbacks_total":                     "Remote configurations rolled back after failing probation",
 	}
 
 	for expectedName, expectedHelp := range expectedMetrics {
@@ -157,6 +160,7 @@ func TestMetricTypes(t *testing.T) {
 		"remotecfg_load_attempts_total":                 "COUNTER",
 		"remotecfg_last_load_success_timestamp_seconds": "GAUGE",
 		"remotecfg_request_duration_seconds":            "HISTOGRAM",
+		"remotecfg_rollbacks_total":                     "COUNTER",
 	}
 
 	for metricName, expectedType := range expectedTypes {
@@ -0,0 +1,457 @@
+package remotecfg
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+	collectorv1 "github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1"
+	"github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1/collectorv1connect"
+	"github.com/open-telemetry/opamp-go/client"
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	commonconfig "github.com/prometheus/common/config"
+
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/signature"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// errOpAMPNotConnected is returned by GetConfig when the agent hasn't
+// connected to the OpAMP server yet, so that the cached configuration is used
+// on startup.
+var errOpAMPNotConnected = errors.New("not connected to the OpAMP server yet")
+
+// opampSignatureFile is the name of the file of the remote config map which
+// holds the detached signature of the configuration. It isn't part of the
+// configuration itself.
+const opampSignatureFile = "signature"
+
+// opampStopTimeout is the maximum time to wait for the OpAMP client to
+// disconnect from the server.
+const opampStopTimeout = 5 * time.Second
+
+// opampClient is an implementation of collectorv1connect.CollectorServiceClient
+// which acts as an OpAMP agent. The OpAMP server pushes configuration to the
+// agent, which hands it over to the service on the next call to GetConfig.
+// The OpAMP client reconnects to the server on its own when the connection is
+// lost.
+type opampClient struct {
+	logger   log.Logger
+	client   client.OpAMPClient
+	settings types.StartSettings
+
+	mut             sync.Mutex
+	started         bool
+	connected       bool
+	remoteConfig    *protobufs.AgentRemoteConfig
+	effectiveConfig *protobufs.EffectiveConfig
+
+	// updates is notified when the server sends a new configuration.
+	updates chan struct{}
+}
+
+var (
+	_ collectorv1connect.CollectorServiceClient = (*opampClient)(nil)
+	_ io.Closer                                 = (*opampClient)(nil)
+)
+
+// newOpAMPClient creates an opampClient based on the provided Arguments
+// configuration. The client connects to the server on the first call to
+// RegisterCollector.
+func newOpAMPClient(args Arguments, logger log.Logger) (*opampClient, error) {
+	header, err := opampHeader(args.HTTPClientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &opampClient{
+		logger:  logger,
+		updates: make(chan struct{}, 1),
+	}
+
+	opampLogger := &opampLogger{logger: logger}
+	switch {
+	case strings.HasPrefix(args.URL, "ws://"), strings.HasPrefix(args.URL, "wss://"):
+		c.client = client.NewWebSocket(opampLogger)
+	default:
+		c.client = client.NewHTTP(opampLogger)
+	}
+
+	c.settings = types.StartSettings{
+		OpAMPServerURL: args.URL,
+		Header:         header,
+		InstanceUid:    opampInstanceUID(args.ID),
+		Callbacks: types.Callbacks{
+			OnConnect:          c.onConnect,
+			OnConnectFailed:    c.onConnectFailed,
+			OnError:            c.onError,
+			OnMessage:          c.onMessage,
+			GetEffectiveConfig: c.getEffectiveConfig,
+		},
+	}
+	if args.HTTPClientConfig != nil {
+		tlsConfig, err := commonconfig.NewTLSConfig(&args.HTTPClientConfig.Convert().TLSConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls_config: %w", err)
+		}
+		c.settings.TLSConfig = tlsConfig
+	}
+
+	return c, nil
+}
+
+// ConfigUpdates implements configNotifier.
+func (c *opampClient) ConfigUpdates() <-chan struct{} {
+	return c.updates
+}
+
+// GetConfig reports the status and effective configuration of the agent, and
+// returns the last configuration received from the server.
+func (c *opampClient) GetConfig(ctx context.Context, req *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
+	c.mut.Lock()
+	connected := c.connected
+	remoteConfig := c.remoteConfig
+	if req.Msg.EffectiveConfig != nil {
+		c.effectiveConfig = toOpAMPEffectiveConfig(req.Msg.EffectiveConfig)
+	}
+	c.mut.Unlock()
+
+	// The status describes the configuration with the hash of the request,
+	// which isn't necessarily the last one received from the server. The
+	// OpAMP client rejects statuses without a hash, so nothing is reported
+	// before a configuration was received.
+	if req.Msg.RemoteConfigStatus != nil && req.Msg.Hash != "" {
+		hash, err := hex.DecodeString(req.Msg.Hash)
+		if err != nil {
+			// Configurations received from the OpAMP server always have a hex
+			// encoded hash, so this one was cached by another transport.
+			level.Debug(c.logger).Log("msg", "not reporting the status of a configuration not received over OpAMP", "hash", req.Msg.Hash)
+		} else {
+			status := toOpAMPRemoteConfigStatus(req.Msg.RemoteConfigStatus, hash)
+			if err := c.client.SetRemoteConfigStatus(status); err != nil {
+				return nil, fmt.Errorf("failed to report remote config status: %w", err)
+			}
+		}
+	}
+	if req.Msg.EffectiveConfig != nil {
+		if err := c.client.UpdateEffectiveConfig(ctx); err != nil {
+			return nil, fmt.Errorf("failed to report effective config: %w", err)
+		}
+	}
+
+	switch {
+	case remoteConfig == nil && !connected:
+		return nil, errOpAMPNotConnected
+	case remoteConfig == nil:
+		return nil, errNotModified
+	}
+
+	content := opampConfigContent(remoteConfig)
+	hash := hex.EncodeToString(remoteConfig.GetConfigHash())
+	if hash == "" {
+		hash = getHash([]byte(content))
+	}
+	if hash == req.Msg.Hash {
+		return nil, errNotModified
+	}
+
+	resp := connect.NewResponse(&collectorv1.GetConfigResponse{
+		Content: content,
+		Hash:    hash,
+	})
+	if f, ok := remoteConfig.GetConfig().GetConfigMap()[opampSignatureFile]; ok {
+		resp.Header().Set(signature.HTTPHeader, string(f.GetBody()))
+	}
+	return resp, nil
+}
+
+// RegisterCollector reports the description of the agent, and connects to the
+// server the first time it's called.
+func (c *opampClient) RegisterCollector(ctx context.Context, req *connect.Request[collectorv1.RegisterCollectorRequest]) (*connect.Response[collectorv1.RegisterCollectorResponse], error) {
+	description := opampAgentDescription(req.Msg.Id, req.Msg.Name, req.Msg.LocalAttributes)
+	if err := c.client.SetAgentDescription(description); err != nil {
+		return nil, fmt.Errorf("failed to set agent description: %w", err)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.started {
+		return connect.NewResponse(&collectorv1.RegisterCollectorResponse{}), nil
+	}
+
+	capabilities := protobufs.AgentCapabilities_AgentCapabilities_ReportsStatus |
+		protobufs.AgentCapabilities_AgentCapabilities_AcceptsRemoteConfig |
+		protobufs.AgentCapabilities_AgentCapabilities_ReportsRemoteConfig |
+		protobufs.AgentCapabilities_AgentCapabilities_ReportsEffectiveConfig
+	if err := c.client.SetCapabilities(&capabilities); err != nil {
+		return nil, fmt.Errorf("failed to set agent capabilities: %w", err)
+	}
+
+	// The client outlives the request, so it mustn't be started with the
+	// request context.
+	if err := c.client.Start(context.Background(), c.settings); err != nil {
+		return nil, fmt.Errorf("failed to start OpAMP client: %w", err)
+	}
+	c.started = true
+
+	return connect.NewResponse(&collectorv1.RegisterCollectorResponse{}), nil
+}
+
+// UnregisterCollector disconnects from the server.
+func (c *opampClient) UnregisterCollector(ctx context.Context, _ *connect.Request[collectorv1.UnregisterCollectorRequest]) (*connect.Response[collectorv1.UnregisterCollectorResponse], error) {
+	if err := c.stop(ctx); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&collectorv1.UnregisterCollectorResponse{}), nil
+}
+
+// Close implements io.Closer and disconnects from the server.
+func (c *opampClient) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), opampStopTimeout)
+	defer cancel()
+	return c.stop(ctx)
+}
+
+func (c *opampClient) stop(ctx context.Context) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.started {
+		return nil
+	}
+	c.started = false
+	c.connected = false
+	return c.client.Stop(ctx)
+}
+
+func (c *opampClient) onConnect(_ context.Context) {
+	level.Info(c.logger).Log("msg", "connected to the OpAMP server", "url", c.settings.OpAMPServerURL)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.connected = true
+}
+
+func (c *opampClient) onConnectFailed(_ context.Context, err error) {
+	level.Warn(c.logger).Log("msg", "failed to connect to the OpAMP server, retrying", "url", c.settings.OpAMPServerURL, "err", err)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.connected = false
+}
+
+func (c *opampClient) onError(_ context.Context, resp *protobufs.ServerErrorResponse) {
+	level.Error(c.logger).Log("msg", "OpAMP server returned an error", "type", resp.GetType().String(), "err", resp.GetErrorMessage())
+}
+
+func (c *opampClient) onMessage(_ context.Context, msg *types.MessageData) {
+	if msg.RemoteConfig == nil {
+		return
+	}
+
+	c.mut.Lock()
+	c.remoteConfig = msg.RemoteConfig
+	c.mut.Unlock()
+
+	level.Debug(c.logger).Log("msg", "received remote configuration from the OpAMP server", "hash", hex.EncodeToString(msg.RemoteConfig.GetConfigHash()))
+
+	select {
+	case c.updates <- struct{}{}:
+	default:
+	}
+}
+
+func (c *opampClient) getEffectiveConfig(_ context.Context) (*protobufs.EffectiveConfig, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.effectiveConfig, nil
+}
+
+// opampConfigContent returns the Alloy configuration of an AgentRemoteConfig.
+// If the config map holds several files, they're concatenated in the order of
+// their names. The signature file is left out.
+func opampConfigContent(rc *protobufs.AgentRemoteConfig) string {
+	files := rc.GetConfig().GetConfigMap()
+	if f, ok := files[""]; ok {
+		return string(f.GetBody())
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name == opampSignatureFile {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.Write(files[name].GetBody())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// opampAgentDescription describes the agent to the server. The ID of the
+// collector is used as the service.instance.id identifying attribute, and
+// the attributes of the collector are sent as non-identifying attributes.
+func opampAgentDescription(id, name string, attrs map[string]string) *protobufs.AgentDescription {
+	identifying := []*protobufs.KeyValue{
+		opampKeyValue("service.name", "alloy"),
+		opampKeyValue("service.instance.id", id),
+		opampKeyValue("service.version", build.Version),
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	nonIdentifying := make([]*protobufs.KeyValue, 0, len(attrs)+1)
+	if name != "" {
+		nonIdentifying = append(nonIdentifying, opampKeyValue("collector.name", name))
+	}
+	for _, k := range keys {
+		nonIdentifying = append(nonIdentifying, opampKeyValue(k, attrs[k]))
+	}
+
+	return &protobufs.AgentDescription{
+		IdentifyingAttributes:    identifying,
+		NonIdentifyingAttributes: nonIdentifying,
+	}
+}
+
+func opampKeyValue(key, value string) *protobufs.KeyValue {
+	return &protobufs.KeyValue{
+		Key:   key,
+		Value: &protobufs.AnyValue{Value: &protobufs.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// opampInstanceUID returns the OpAMP instance UID of the collector. IDs which
+// aren't UUIDs are hashed into one, so that the UID is stable across restarts.
+func opampInstanceUID(id string) types.InstanceUid {
+	u, err := uuid.Parse(id)
+	if err != nil {
+		u = uuid.NewSHA1(uuid.NameSpaceOID, []byte(id))
+	}
+	return types.InstanceUid(u)
+}
+
+func toOpAMPRemoteConfigStatus(status *collectorv1.RemoteConfigStatus, hash []byte) *protobufs.RemoteConfigStatus {
+	out := &protobufs.RemoteConfigStatus{
+		LastRemoteConfigHash: hash,
+		ErrorMessage:         status.GetErrorMessage(),
+	}
+	switch status.GetStatus() {
+	case collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED:
+		out.Status = protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED
+	case collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLYING:
+		out.Status = protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLYING
+	case collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_FAILED:
+		out.Status = protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED
+	default:
+		out.Status = protobufs.RemoteConfigStatuses_RemoteConfigStatuses_UNSET
+	}
+	return out
+}
+
+func toOpAMPEffectiveConfig(ec *collectorv1.EffectiveConfig) *protobufs.EffectiveConfig {
+	files := make(map[string]*protobufs.AgentConfigFile, len(ec.GetConfigMap().GetConfigMap()))
+	for name, f := range ec.GetConfigMap().GetConfigMap() {
+		files[name] = &protobufs.AgentConfigFile{
+			Body:        f.GetBody(),
+			ContentType: f.GetContentType(),
+		}
+	}
+	return &protobufs.EffectiveConfig{
+		ConfigMap: &protobufs.AgentConfigMap{ConfigMap: files},
+	}
+}
+
+// opampHeader returns the headers sent to the OpAMP server, including the
+// credentials configured in cfg.
+func opampHeader(cfg *config.HTTPClientConfig) (http.Header, error) {
+	header := http.Header{}
+	header.Set("User-Agent", userAgent)
+	if cfg == nil {
+		return header, nil
+	}
+
+	if cfg.HTTPHeaders != nil {
+		for name, values := range cfg.HTTPHeaders.Headers {
+			for _, v := range values {
+				header.Add(name, string(v))
+			}
+		}
+	}
+
+	readSecret := func(value, file string) (string, error) {
+		if file == "" {
+			return value, nil
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	switch {
+	case cfg.BearerToken != "" || cfg.BearerTokenFile != "":
+		token, err := readSecret(string(cfg.BearerToken), cfg.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer_token_file: %w", err)
+		}
+		header.Set("Authorization", "Bearer "+token)
+	case cfg.Authorization != nil:
+		credentials, err := readSecret(string(cfg.Authorization.Credentials), cfg.Authorization.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read authorization credentials_file: %w", err)
+		}
+		typ := cfg.Authorization.Type
+		if typ == "" {
+			typ = "Bearer"
+		}
+		header.Set("Authorization", typ+" "+credentials)
+	case cfg.BasicAuth != nil:
+		password, err := readSecret(string(cfg.BasicAuth.Password), cfg.BasicAuth.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read basic_auth password_file: %w", err)
+		}
+		req := http.Request{Header: header}
+		req.SetBasicAuth(cfg.BasicAuth.Username, password)
+	}
+
+	return header, nil
+}
+
+// opampLogger adapts a log.Logger to the logger of the OpAMP client.
+type opampLogger struct {
+	logger log.Logger
+}
+
+var _ types.Logger = (*opampLogger)(nil)
+
+func (l *opampLogger) Debugf(_ context.Context, format string, v ...any) {
+	level.Debug(l.logger).Log("msg", fmt.Sprintf(format, v...))
+}
+
+func (l *opampLogger) Errorf(_ context.Context, format string, v ...any) {
+	level.Error(l.logger).Log("msg", fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,206 @@
+package remotecfg
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+	collectorv1 "github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1"
+	"github.com/open-telemetry/opamp-go/client"
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/config"
+)
+
+// mockOpAMPClient records the status reported to the OpAMP server. Methods
+// which aren't overridden panic through the embedded nil interface.
+type mockOpAMPClient struct {
+	client.OpAMPClient
+
+	remoteConfigStatus     *protobufs.RemoteConfigStatus
+	effectiveConfigUpdates int
+}
+
+func (m *mockOpAMPClient) SetRemoteConfigStatus(status *protobufs.RemoteConfigStatus) error {
+	// The OpAMP client rejects statuses without a hash.
+	if status.LastRemoteConfigHash == nil {
+		return errors.New("LastRemoteConfigHash is nil")
+	}
+	m.remoteConfigStatus = status
+	return nil
+}
+
+func (m *mockOpAMPClient) UpdateEffectiveConfig(context.Context) error {
+	m.effectiveConfigUpdates++
+	return nil
+}
+
+func newTestOpAMPClient(t *testing.T) (*opampClient, *mockOpAMPClient) {
+	args := getDefaultArguments()
+	args.URL = "wss://example.com/v1/opamp"
+	args.Protocol = ProtocolOpAMP
+
+	c, err := newOpAMPClient(args, log.NewNopLogger())
+	require.NoError(t, err)
+
+	mock := &mockOpAMPClient{}
+	c.client = mock
+	return c, mock
+}
+
+func getOpAMPConfig(c *opampClient, hash string) (*connect.Response[collectorv1.GetConfigResponse], error) {
+	return c.GetConfig(context.Background(), connect.NewRequest(&collectorv1.GetConfigRequest{Hash: hash}))
+}
+
+func TestOpAMPClient_GetConfig(t *testing.T) {
+	c, _ := newTestOpAMPClient(t)
+
+	// The cache is used until the client connects to the server.
+	_, err := getOpAMPConfig(c, "")
+	require.ErrorIs(t, err, errOpAMPNotConnected)
+
+	c.onConnect(context.Background())
+	_, err = getOpAMPConfig(c, "")
+	require.ErrorIs(t, err, errNotModified)
+
+	c.onMessage(context.Background(), &types.MessageData{
+		RemoteConfig: &protobufs.AgentRemoteConfig{
+			Config: &protobufs.AgentConfigMap{ConfigMap: map[string]*protobufs.AgentConfigFile{
+				"": {Body: []byte(`logging {}`)},
+			}},
+			ConfigHash: []byte{0xca, 0xfe},
+		},
+	})
+	select {
+	case <-c.ConfigUpdates():
+	default:
+		t.Fatal("expected a config update notification")
+	}
+
+	resp, err := getOpAMPConfig(c, "")
+	require.NoError(t, err)
+	assert.Equal(t, "logging {}", resp.Msg.Content)
+	assert.Equal(t, "cafe", resp.Msg.Hash)
+
+	_, err = getOpAMPConfig(c, "cafe")
+	require.ErrorIs(t, err, errNotModified)
+}
+
+func TestOpAMPClient_GetConfig_ReportsStatus(t *testing.T) {
+	c, mock := newTestOpAMPClient(t)
+	c.onConnect(context.Background())
+	c.onMessage(context.Background(), &types.MessageData{
+		RemoteConfig: &protobufs.AgentRemoteConfig{ConfigHash: []byte{0x01}},
+	})
+
+	_, err := c.GetConfig(context.Background(), connect.NewRequest(&collectorv1.GetConfigRequest{
+		Hash: "01",
+		RemoteConfigStatus: &collectorv1.RemoteConfigStatus{
+			Status:       collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_FAILED,
+			ErrorMessage: "boom",
+		},
+		EffectiveConfig: &collectorv1.EffectiveConfig{
+			ConfigMap: &collectorv1.AgentConfigMap{ConfigMap: map[string]*collectorv1.AgentConfigFile{
+				"": {Body: []byte(`logging {}`), ContentType: "text/plain"},
+			}},
+		},
+	}))
+	require.ErrorIs(t, err, errNotModified)
+
+	require.NotNil(t, mock.remoteConfigStatus)
+	assert.Equal(t, protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, mock.remoteConfigStatus.Status)
+	assert.Equal(t, "boom", mock.remoteConfigStatus.ErrorMessage)
+	assert.Equal(t, []byte{0x01}, mock.remoteConfigStatus.LastRemoteConfigHash)
+
+	assert.Equal(t, 1, mock.effectiveConfigUpdates)
+	ec, err := c.getEffectiveConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`logging {}`), ec.ConfigMap.ConfigMap[""].Body)
+}
+
+func TestOpAMPClient_GetConfig_ReportsStatusWithoutServerHash(t *testing.T) {
+	c, mock := newTestOpAMPClient(t)
+	c.onConnect(context.Background())
+	c.onMessage(context.Background(), &types.MessageData{
+		RemoteConfig: &protobufs.AgentRemoteConfig{
+			Config: &protobufs.AgentConfigMap{ConfigMap: map[string]*protobufs.AgentConfigFile{
+				"": {Body: []byte(`logging {}`)},
+			}},
+		},
+	})
+
+	// Nothing is reported before a configuration was received.
+	resp, err := c.GetConfig(context.Background(), connect.NewRequest(&collectorv1.GetConfigRequest{
+		RemoteConfigStatus: &collectorv1.RemoteConfigStatus{
+			Status: collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_UNSET,
+		},
+	}))
+	require.NoError(t, err)
+	require.Nil(t, mock.remoteConfigStatus)
+	hash := resp.Msg.Hash
+	require.Equal(t, getHash([]byte(`logging {}`)), hash)
+
+	// The server sends a newer configuration before the status of the
+	// previous one is reported.
+	c.onMessage(context.Background(), &types.MessageData{
+		RemoteConfig: &protobufs.AgentRemoteConfig{ConfigHash: []byte{0x02}},
+	})
+
+	resp, err = c.GetConfig(context.Background(), connect.NewRequest(&collectorv1.GetConfigRequest{
+		Hash: hash,
+		RemoteConfigStatus: &collectorv1.RemoteConfigStatus{
+			Status: collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED,
+		},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "02", resp.Msg.Hash)
+
+	require.NotNil(t, mock.remoteConfigStatus)
+	assert.Equal(t, protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, mock.remoteConfigStatus.Status)
+	want, err := hex.DecodeString(hash)
+	require.NoError(t, err)
+	assert.Equal(t, want, mock.remoteConfigStatus.LastRemoteConfigHash)
+}
+
+func TestOpAMPConfigContent(t *testing.T) {
+	rc := &protobufs.AgentRemoteConfig{
+		Config: &protobufs.AgentConfigMap{ConfigMap: map[string]*protobufs.AgentConfigFile{
+			"b.alloy": {Body: []byte(`b {}`)},
+			"a.alloy": {Body: []byte(`a {}`)},
+		}},
+	}
+	assert.Equal(t, "a {}\nb {}\n", opampConfigContent(rc))
+}
+
+func TestOpAMPInstanceUID(t *testing.T) {
+	uid := opampInstanceUID("0197a3c9-5cd4-7a1b-8f0e-5b3c2d1e0f4a")
+	assert.Equal(t, "0197a3c9-5cd4-7a1b-8f0e-5b3c2d1e0f4a", uuid.UUID(uid).String())
+
+	// Other IDs are hashed to a stable UUID.
+	assert.Equal(t, opampInstanceUID("my-collector"), opampInstanceUID("my-collector"))
+	assert.NotEqual(t, opampInstanceUID("my-collector"), opampInstanceUID("other-collector"))
+}
+
+func TestOpAMPHeader(t *testing.T) {
+	cfg := config.CloneDefaultHTTPClientConfig()
+	cfg.BasicAuth = &config.BasicAuth{Username: "user", Password: "pass"}
+
+	header, err := opampHeader(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")), header.Get("Authorization"))
+	assert.Equal(t, userAgent, header.Get("User-Agent"))
+
+	cfg = config.CloneDefaultHTTPClientConfig()
+	cfg.BearerToken = "token"
+	header, err = opampHeader(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"path/filepath"
@@ -18,6 +19,7 @@ import (
 	"github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1/collectorv1connect"
 	"github.com/grafana/alloy/internal/build"
 	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/common/signature"
 	"github.com/grafana/alloy/internal/featuregate"
 	alloy_runtime "github.com/grafana/alloy/internal/runtime"
 	"github.com/grafana/alloy/internal/service"
@@ -40,6 +42,10 @@ type Service struct {
 	attrs       map[string]string
 	cm          *configManager
 
+	// verifier checks the signature of the configuration received from the
+	// API. It's nil if signature verification is disabled.
+	verifier *signature.Verifier
+
 	// runCtx is the context from Run method, used for service lifecycle operations
 	runCtx context.Context
 }
@@ -155,6 +161,10 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 			s.fetchLoadConfig(false) // Don't reload cache during periodic polling
 		case <-s.cm.getUpdateTickerChan():
 			s.cm.getTicker().Reset(s.cm.getPollFrequency())
+		case <-s.configUpdates():
+			s.fetchLoadConfig(false) // The server pushed a new configuration
+		case <-s.cm.getProbationC():
+			s.checkProbation()
 		case <-ctx.Done():
 			cleanupCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 			defer cancel()
@@ -203,7 +213,9 @@ func (s *Service) updateHandleEmptyUrl(args Arguments) {
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
+	closeAPIClient(s.apiClient)
 	s.apiClient = newNoopClient()
+	s.verifier = nil
 	s.args.HTTPClientConfig = config.CloneDefaultHTTPClientConfig()
 	s.args = args
 
@@ -231,31 +243,60 @@ func (s *Service) updateHandleArgs(newArgs Arguments) error {
 
 	// Check if we need to create a new API client
 	s.mut.RLock()
-	needsNewClient := !reflect.DeepEqual(s.args.HTTPClientConfig, newArgs.HTTPClientConfig) || s.args.URL != newArgs.URL
+	needsNewClient := !reflect.DeepEqual(s.args.HTTPClientConfig, newArgs.HTTPClientConfig) ||
+		s.args.URL != newArgs.URL ||
+		s.args.Protocol != newArgs.Protocol ||
+		(newArgs.Protocol == ProtocolOpAMP && s.args.ID != newArgs.ID)
 	s.mut.RUnlock()
 
 	// Create new API client if needed
 	if needsNewClient {
-		newAPIClient, err := createAPIClient(newArgs, s.metrics)
+		var (
+			newAPIClient collectorv1connect.CollectorServiceClient
+			err          error
+		)
+		switch newArgs.Protocol {
+		case ProtocolOpAMP:
+			newAPIClient, err = newOpAMPClient(newArgs, s.opts.Logger)
+		default:
+			newAPIClient, err = createAPIClient(newArgs, s.metrics)
+		}
 		if err != nil {
-			s.opts.Logger.Log("level", "error", "msg", "failed to create API client", "url", newArgs.URL, "err", err)
+			s.opts.Logger.Log("level", "error", "msg", "failed to create API client", "url", newArgs.URL, "protocol", newArgs.Protocol, "err", err)
 			return err
 		}
 		s.mut.Lock()
+		closeAPIClient(s.apiClient)
 		s.apiClient = newAPIClient
 		s.mut.Unlock()
 	}
 
+	// Build the signature verifier before acquiring the lock, since it may
+	// read key files from disk.
+	var verifier *signature.Verifier
+	if newArgs.Signature != nil {
+		verifier, err = signature.NewVerifier(*newArgs.Signature)
+		if err != nil {
+			s.opts.Logger.Log("level", "error", "msg", "failed to create signature verifier", "err", err)
+			return err
+		}
+	}
+
 	// Now acquire write lock only for state updates (fast operations)
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
+	s.verifier = verifier
+
 	// Update cache location hash
 	s.cm.setArgsHash(hash)
 
 	// Update the poll frequency
 	s.cm.setPollFrequency(newArgs.PollFrequency)
 
+	// Update the probation settings of new configurations
+	s.cm.setRollback(newArgs.Rollback)
+
 	// Combine the new attributes on top of the system attributes
 	s.attrs = maps.Clone(s.systemAttrs)
 	maps.Copy(s.attrs, newArgs.Attributes)
@@ -277,6 +318,17 @@ func (s *Service) fetchLoadConfig(allowCacheFallback bool) {
 	s.cm.fetchLoadConfig(s.getConfig, allowCacheFallback)
 }
 
+// checkProbation checks the health of the configuration on probation, and
+// reports any resulting status change to the API.
+func (s *Service) checkProbation() {
+	if !s.isEnabled() {
+		return
+	}
+
+	s.cm.checkProbation()
+	s.cm.notifyStatusUpdate(s.getConfig)
+}
+
 func (s *Service) getConfig() (*collectorv1.GetConfigResponse, error) {
 	s.mut.RLock()
 	defer s.mut.RUnlock()
@@ -302,6 +354,18 @@ func (s *Service) getConfig() (*collectorv1.GetConfigResponse, error) {
 		}
 		return nil, err
 	}
+
+	if s.verifier != nil {
+		content, err := s.verifier.Verify([]byte(response.Msg.GetContent()), response.Header().Get(signature.HTTPHeader))
+		if err != nil {
+			s.opts.Logger.Log("level", "error", "msg", "rejecting configuration from remote server", "id", s.args.ID, "err", err)
+			return nil, fmt.Errorf("failed to verify configuration signature: %w", err)
+		}
+		return &collectorv1.GetConfigResponse{
+			Content: string(content),
+			Hash:    response.Msg.GetHash(),
+		}, nil
+	}
 	return response.Msg, nil
 }
 
@@ -347,6 +411,33 @@ func (s *Service) isEnabled() bool {
 	return s.args.URL != "" && s.apiClient != nil
 }
 
+// configNotifier is implemented by API clients which are notified when the
+// remote server has a new configuration, instead of having to poll for it.
+type configNotifier interface {
+	ConfigUpdates() <-chan struct{}
+}
+
+// configUpdates returns the channel on which the current API client is
+// notified of new configurations. It returns nil if the client only supports
+// polling, which blocks forever.
+func (s *Service) configUpdates() <-chan struct{} {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if n, ok := s.apiClient.(configNotifier); ok {
+		return n.ConfigUpdates()
+	}
+	return nil
+}
+
+// closeAPIClient releases the resources held by an API client which is being
+// replaced.
+func closeAPIClient(c collectorv1connect.CollectorServiceClient) {
+	if closer, ok := c.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
 func getSystemAttributes() map[string]string {
 	return map[string]string{
 		reservedAttributeNamespace + namespaceDelimiter + "version": build.Version,
@@ -2,6 +2,11 @@ package remotecfg
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"os"
@@ -15,6 +20,7 @@ import (
 	collectorv1 "github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1"
 	"github.com/grafana/alloy-remote-config/api/gen/proto/go/collector/v1/collectorv1connect"
 	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/signature"
 	_ "github.com/grafana/alloy/internal/component/loki/process"
 	"github.com/grafana/alloy/internal/featuregate"
 	alloy_runtime "github.com/grafana/alloy/internal/runtime"
@@ -917,3 +923,204 @@ func buildUnregisterCollectorFunc(called *atomic.Bool) func(ctx context.Context,
 		}, nil
 	}
 }
+
+func TestSignatureVerification(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	cfgSigned := `loki.process "signed" { forward_to = [] }`
+	cfgTampered := `loki.process "tampered" { forward_to = [] }`
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(cfgSigned)))
+
+	buildSignedHandler := func(content, sig string) func(context.Context, *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
+		return func(context.Context, *connect.Request[collectorv1.GetConfigRequest]) (*connect.Response[collectorv1.GetConfigResponse], error) {
+			rsp := connect.NewResponse(&collectorv1.GetConfigResponse{Content: content})
+			if sig != "" {
+				rsp.Header().Set(signature.HTTPHeader, sig)
+			}
+			return rsp, nil
+		}
+	}
+
+	client := &mockCollectorClient{}
+	var registerCalled atomic.Bool
+	client.mut.Lock()
+	client.getConfigFunc = buildSignedHandler(cfgSigned, sig)
+	client.registerCollectorFunc = buildRegisterCollectorFunc(&registerCalled)
+	client.mut.Unlock()
+
+	env := newTestEnvironment(t, client)
+	require.NoError(t, env.ApplyConfig(fmt.Sprintf(`
+		url            = "https://example.com/"
+		poll_frequency = "10s"
+
+		signature {
+			public_keys = [%q]
+		}
+	`, pubPEM)))
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, env.Run(ctx))
+	}()
+	defer func() { cancel(); wg.Wait() }()
+
+	// The signed configuration is loaded and cached.
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, getHash([]byte(cfgSigned)), env.svc.cm.getLastLoadedCfgHash())
+		b, err := env.svc.cm.getCachedConfig()
+		assert.NoError(c, err)
+		assert.Equal(c, cfgSigned, string(b))
+	}, time.Second, 10*time.Millisecond)
+
+	// Tampered and unsigned configurations are rejected, and the signed one
+	// keeps running.
+	for _, tamperedSig := range []string{sig, ""} {
+		client.mut.Lock()
+		client.getConfigFunc = buildSignedHandler(cfgTampered, tamperedSig)
+		client.mut.Unlock()
+
+		assertRemoteConfigStatus(t, env, collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, true)
+		require.Contains(t, env.svc.cm.getRemoteConfigStatus().ErrorMessage, "failed to verify configuration signature")
+		require.Equal(t, getHash([]byte(cfgSigned)), env.svc.cm.getLastLoadedCfgHash())
+
+		b, err := env.svc.cm.getCachedConfig()
+		require.NoError(t, err)
+		require.Equal(t, cfgSigned, string(b))
+
+		client.mut.Lock()
+		client.getConfigFunc = buildSignedHandler(cfgSigned, sig)
+		client.mut.Unlock()
+		assertRemoteConfigStatus(t, env, collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, false)
+	}
+}
+
+func TestRollbackUnhealthyConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	cfgGood := `loki.process "good" { forward_to = [] }`
+	cfgUnhealthy := `loki.process "unhealthy" { forward_to = [] }`
+	cfgHealthy := `loki.process "healthy" { forward_to = [] }`
+
+	client := &mockCollectorClient{}
+	var registerCalled atomic.Bool
+	client.mut.Lock()
+	client.getConfigFunc = buildGetConfigHandler(cfgGood, "", false)
+	client.registerCollectorFunc = buildRegisterCollectorFunc(&registerCalled)
+	client.mut.Unlock()
+
+	env := newTestEnvironment(t, client)
+	require.NoError(t, env.ApplyConfig(`
+		url            = "https://example.com/"
+		poll_frequency = "10s"
+
+		rollback {
+			probation_period = "200ms"
+			check_interval   = "10ms"
+		}
+	`))
+
+	// Report the components as unhealthy on demand.
+	var unhealthy atomic.Bool
+	env.svc.cm.mut.Lock()
+	env.svc.cm.listComponents = func() ([]*component.Info, error) {
+		health := component.HealthTypeHealthy
+		if unhealthy.Load() {
+			health = component.HealthTypeUnhealthy
+		}
+		return []*component.Info{
+			{ID: component.ID{LocalID: "loki.process.a"}, Health: component.Health{Health: health}},
+			{ID: component.ID{LocalID: "loki.process.b"}, Health: component.Health{Health: health}},
+		}, nil
+	}
+	env.svc.cm.mut.Unlock()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, env.Run(ctx))
+	}()
+	defer func() { cancel(); wg.Wait() }()
+
+	// The first configuration has nothing to roll back to, so it's applied
+	// right away.
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, getHash([]byte(cfgGood)), env.svc.cm.getLastLoadedCfgHash())
+		assert.False(c, env.svc.cm.onProbation())
+	}, time.Second, 10*time.Millisecond)
+	assertRemoteConfigStatus(t, env, collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, false)
+
+	// A configuration which leaves components unhealthy is rolled back, and
+	// isn't loaded again while it doesn't change.
+	unhealthy.Store(true)
+	client.mut.Lock()
+	client.getConfigFunc = buildGetConfigHandler(cfgUnhealthy, "", false)
+	client.mut.Unlock()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, float64(1), testutil.ToFloat64(env.svc.metrics.rollbacks))
+		assert.Equal(c, getHash([]byte(cfgGood)), env.svc.cm.getLastLoadedCfgHash())
+		assert.Equal(c, getHash([]byte(cfgUnhealthy)), env.svc.cm.getLastReceivedCfgHash())
+	}, time.Second, 10*time.Millisecond)
+	assertRemoteConfigStatus(t, env, collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, true)
+	require.Contains(t, env.svc.cm.getRemoteConfigStatus().ErrorMessage, "2 of 2 components unhealthy")
+
+	b, err := env.svc.cm.getCachedConfig()
+	require.NoError(t, err)
+	require.Equal(t, cfgGood, string(b))
+
+	// A configuration which keeps components healthy passes probation.
+	unhealthy.Store(false)
+	client.mut.Lock()
+	client.getConfigFunc = buildGetConfigHandler(cfgHealthy, "", false)
+	client.mut.Unlock()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, getHash([]byte(cfgHealthy)), env.svc.cm.getLastLoadedCfgHash())
+		assert.False(c, env.svc.cm.onProbation())
+		b, err := env.svc.cm.getCachedConfig()
+		assert.NoError(c, err)
+		assert.Equal(c, cfgHealthy, string(b))
+	}, 2*time.Second, 10*time.Millisecond)
+	assertRemoteConfigStatus(t, env, collectorv1.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, false)
+	require.Equal(t, float64(1), testutil.ToFloat64(env.svc.metrics.rollbacks))
+}
+
+func TestStaleProbationIsIgnored(t *testing.T) {
+	env := newTestEnvironment(t, &mockCollectorClient{})
+	cm := env.svc.cm
+
+	rollback := DefaultRollbackArguments
+	cm.setRollback(&rollback)
+	cm.mut.Lock()
+	cm.lastGoodConfig = []byte(`loki.process "good" { forward_to = [] }`)
+	cm.mut.Unlock()
+
+	getProbation := func() *probation {
+		cm.mut.RLock()
+		defer cm.mut.RUnlock()
+		return cm.probation
+	}
+
+	require.True(t, cm.startProbation([]byte(`loki.process "old" { forward_to = [] }`), "old"))
+	stale := getProbation()
+	require.True(t, cm.startProbation([]byte(`loki.process "new" { forward_to = [] }`), "new"))
+	current := getProbation()
+	defer cm.stopProbation()
+
+	// A late check of the replaced probation must neither end nor roll back
+	// the probation of the newer configuration.
+	cm.passProbation(stale)
+	cm.rollbackProbation(stale, "unhealthy")
+	require.Same(t, current, getProbation())
+	require.Equal(t, float64(0), testutil.ToFloat64(env.svc.metrics.rollbacks))
+	require.Empty(t, cm.getLastLoadedCfgHash())
+}
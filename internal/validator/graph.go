@@ -3,14 +3,18 @@ package validator
 import (
 	"fmt"
 	"iter"
+	"maps"
 	"slices"
 	"strings"
 
 	"github.com/grafana/alloy/internal/dag"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/nodeconf/function"
+	astutil "github.com/grafana/alloy/internal/util/ast"
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/grafana/alloy/syntax/diag"
 	"github.com/grafana/alloy/syntax/typecheck"
+	"github.com/grafana/alloy/syntax/vm"
 )
 
 func newGraph() *graph {
@@ -106,6 +110,11 @@ func validateGraph(s *state, minStability featuregate.Stability) diag.Diagnostic
 				diags.Merge(typecheck.Block(node.n.block, node.n.args))
 			}
 			diags.Merge(validateGraph(node.state, minStability))
+		case *functionNode:
+			diags.Merge(node.n.diags)
+			diags.Merge(validateFunctionReferences(node, s, minStability))
+			// Function references are only checked within the function.
+			continue
 		}
 
 		refs, refDiags := findReferences(n, s.graph, s.scope, minStability)
@@ -164,6 +173,31 @@ func validateGraph(s *state, minStability featuregate.Stability) diag.Diagnostic
 	return diags
 }
 
+// validateFunctionReferences checks that a function only references its
+// arguments, other functions and the stdlib. Edges are added between
+// functions calling each other so that recursion is reported as a cycle.
+func validateFunctionReferences(fn *functionNode, s *state, minStability featuregate.Stability) diag.Diagnostics {
+	variables := maps.Clone(s.scope.Variables)
+	variables["argument"] = fn.arguments
+
+	refs, diags := findReferences(fn, s.graph, vm.NewScope(variables), minStability)
+	for _, ref := range refs {
+		diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			StartPos: ast.StartPos(ref.Traversal[0]).Position(),
+			EndPos:   ast.EndPos(ref.Traversal[len(ref.Traversal)-1]).Position(),
+			Message:  "functions can only reference their arguments, other functions and the standard library",
+		})
+	}
+
+	for _, t := range astutil.TraversalsFromBody(fn.Block().Body) {
+		if callee, ok := s.graph.GetByID(function.BlockName + "." + t[0].Name).(*functionNode); ok {
+			s.graph.AddEdge(dag.Edge{From: fn, To: callee})
+		}
+	}
+	return diags
+}
+
 type blockNode interface {
 	dag.Node
 	Block() *ast.BlockStmt
@@ -280,3 +314,30 @@ func (f *foreachNode) Block() *ast.BlockStmt {
 func (f *foreachNode) NodeID() string {
 	return f.n.NodeID()
 }
+
+func newFunctionNode(n *node, arguments map[string]struct{}) *functionNode {
+	return &functionNode{
+		n:         n,
+		arguments: arguments,
+	}
+}
+
+var (
+	_ dag.Node  = (*functionNode)(nil)
+	_ blockNode = (*functionNode)(nil)
+)
+
+// functionNode is used for function blocks, which can reference their
+// arguments but not the other nodes of the graph.
+type functionNode struct {
+	n         *node
+	arguments map[string]struct{}
+}
+
+func (f *functionNode) Block() *ast.BlockStmt {
+	return f.n.Block()
+}
+
+func (f *functionNode) NodeID() string {
+	return f.n.NodeID()
+}
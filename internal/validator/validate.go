@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/alloy/internal/nodeconf/argument"
 	"github.com/grafana/alloy/internal/nodeconf/export"
 	"github.com/grafana/alloy/internal/nodeconf/foreach"
+	"github.com/grafana/alloy/internal/nodeconf/function"
 	"github.com/grafana/alloy/internal/nodeconf/importsource"
 	alloy_runtime "github.com/grafana/alloy/internal/runtime"
 	"github.com/grafana/alloy/internal/runtime/logging"
@@ -195,7 +196,7 @@ func (v *validator) validateConfigs(s *state) {
 		}
 
 		// In configs we store blocks for logging, tracing, argument, export, import.file,
-		// import.string, import.http, import.git and foreach.
+		// import.string, import.http, import.git, foreach and function.
 		switch node.block.GetBlockName() {
 		case "logging":
 			node.args = &logging.Options{}
@@ -211,6 +212,8 @@ func (v *validator) validateConfigs(s *state) {
 			s.graph.Add(node)
 		case foreach.BlockName:
 			v.validateForeach(node, s)
+		case function.BlockName:
+			v.validateFunction(node, register, s)
 		case argument.BlockName:
 			node.args = &argument.Arguments{}
 			if s.root {
@@ -275,6 +278,78 @@ func (v *validator) validateImport(node *node, register bool, s *state) {
 
 	if register {
 		s.cr.registerCustomComponent(node.block, nil)
+		// Imports can also export functions, called as LABEL.NAME.
+		s.scope.Variables[node.block.Label] = struct{}{}
+	}
+}
+
+func (v *validator) validateFunction(node *node, register bool, s *state) {
+	name := node.block.GetBlockName()
+
+	// Check required stability level.
+	if err := featuregate.CheckAllowed(function.StabilityLevel, v.minStability, fmt.Sprintf("function block %q", name)); err != nil {
+		node.diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			StartPos: node.block.NamePos.Position(),
+			EndPos:   node.block.NamePos.Add(len(name) - 1).Position(),
+			Message:  err.Error(),
+		})
+	}
+
+	// Require label for function block.
+	if diag, ok := blockMissingLabel(node.block); ok {
+		register = false
+		node.diags.Add(diag)
+	}
+
+	var (
+		arguments = make(map[string]struct{})
+		hasReturn bool
+	)
+	for _, stmt := range node.block.Body {
+		switch stmt := stmt.(type) {
+		case *ast.BlockStmt:
+			if stmt.GetBlockName() != function.ArgumentBlockName {
+				node.diags.Add(diag.Diagnostic{
+					Severity: diag.SeverityLevelError,
+					StartPos: ast.StartPos(stmt).Position(),
+					EndPos:   stmt.LCurlyPos.Position(),
+					Message:  fmt.Sprintf("unsupported block %s in function block", stmt.GetBlockName()),
+				})
+				continue
+			}
+			if diag, ok := blockMissingLabel(stmt); ok {
+				node.diags.Add(diag)
+			} else {
+				arguments[stmt.Label] = struct{}{}
+			}
+			node.diags.Merge(typecheck.Block(stmt, &function.Argument{}))
+		case *ast.AttributeStmt:
+			if stmt.Name.Name != function.ReturnAttrName {
+				node.diags.Add(diag.Diagnostic{
+					Severity: diag.SeverityLevelError,
+					StartPos: ast.StartPos(stmt.Name).Position(),
+					EndPos:   ast.EndPos(stmt.Name).Position(),
+					Message:  fmt.Sprintf("unsupported attribute %s in function block", stmt.Name.Name),
+				})
+				continue
+			}
+			hasReturn = true
+		}
+	}
+
+	if !hasReturn {
+		node.diags.Add(diag.Diagnostic{
+			Severity: diag.SeverityLevelError,
+			StartPos: ast.StartPos(node.block).Position(),
+			EndPos:   node.block.LCurlyPos.Position(),
+			Message:  fmt.Sprintf("function block must have a %s attribute", function.ReturnAttrName),
+		})
+	}
+
+	s.graph.Add(newFunctionNode(node, arguments))
+	if register {
+		s.scope.Variables[node.block.Label] = struct{}{}
 	}
 }
 
@@ -415,7 +490,7 @@ func (v *validator) validateServices(s *state) {
 }
 
 var configBlockNames = [...]string{
-	foreach.BlockName, argument.BlockName, export.BlockName, "logging", "tracing",
+	foreach.BlockName, function.BlockName, argument.BlockName, export.BlockName, "logging", "tracing",
 	importsource.BlockNameFile, importsource.BlockNameString, importsource.BlockNameHTTP, importsource.BlockNameGit,
 }
 
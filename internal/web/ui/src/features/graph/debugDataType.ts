@@ -13,6 +13,7 @@ export enum DebugDataType {
   OTEL_METRIC = 'otel_metric',
   OTEL_LOG = 'otel_log',
   OTEL_TRACE = 'otel_trace',
+  PYROSCOPE_PROFILE = 'pyroscope_profile',
 }
 
 export const DebugDataTypeColorMap: Record<DebugDataType, string> = {
@@ -23,4 +24,5 @@ export const DebugDataTypeColorMap: Record<DebugDataType, string> = {
   [DebugDataType.OTEL_METRIC]: '#F39C12', // Yellow
   [DebugDataType.OTEL_LOG]: '#009E73', // Green
   [DebugDataType.OTEL_TRACE]: '#56B4E9', // Light Blue
+  [DebugDataType.PYROSCOPE_PROFILE]: '#CC79A7', // Purple
 };
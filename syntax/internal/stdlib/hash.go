@@ -0,0 +1,34 @@
+package stdlib
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+)
+
+var hash = map[string]any{
+	"sha256": sha256Hash,
+	"md5":    md5Hash,
+	"fnv":    fnvHash,
+}
+
+// sha256Hash returns the hex-encoded SHA-256 checksum of in.
+func sha256Hash(in string) string {
+	sum := sha256.Sum256([]byte(in))
+	return hex.EncodeToString(sum[:])
+}
+
+// md5Hash returns the hex-encoded MD5 checksum of in.
+func md5Hash(in string) string {
+	sum := md5.Sum([]byte(in))
+	return hex.EncodeToString(sum[:])
+}
+
+// fnvHash returns the 64-bit FNV-1a hash of in as a number, which makes it
+// usable for sharding with the modulo operator.
+func fnvHash(in string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(in))
+	return h.Sum64()
+}
@@ -0,0 +1,158 @@
+package stdlib
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+var mapFuncs = map[string]any{
+	"keys":   mapKeys,
+	"values": mapValues,
+	"merge":  mapMerge,
+	"pick":   mapPick,
+	"omit":   mapOmit,
+}
+
+// mapKeys returns the keys of an object in lexicographical order.
+var mapKeys = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, fmt.Errorf("keys: expected 1 argument, got %d", len(args))
+	}
+
+	obj, err := objectArg(funcValue, args[0], 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	keys := slices.Sorted(maps.Keys(obj))
+	res := make([]value.Value, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, value.String(key))
+	}
+	return value.Array(res...), nil
+})
+
+// mapValues returns the values of an object, ordered by their key.
+var mapValues = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, fmt.Errorf("values: expected 1 argument, got %d", len(args))
+	}
+
+	obj, err := objectArg(funcValue, args[0], 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	keys := slices.Sorted(maps.Keys(obj))
+	res := make([]value.Value, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, obj[key])
+	}
+	return value.Array(res...), nil
+})
+
+// mapMerge merges any number of objects. If a key exists in more than one
+// object, the value from the last object is used.
+var mapMerge = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	res := make(map[string]value.Value)
+	for i, arg := range args {
+		obj, err := objectArg(funcValue, arg, i)
+		if err != nil {
+			return value.Null, err
+		}
+		maps.Copy(res, obj)
+	}
+	return value.Object(res), nil
+})
+
+// mapPick returns a copy of an object which only contains the given keys.
+var mapPick = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	return filterKeys("pick", funcValue, args, true)
+})
+
+// mapOmit returns a copy of an object without the given keys.
+var mapOmit = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	return filterKeys("omit", funcValue, args, false)
+})
+
+// filterKeys implements map.pick and map.omit. Keys listed in args[1] are
+// retained when keep is true and removed otherwise.
+func filterKeys(name string, funcValue value.Value, args []value.Value, keep bool) (value.Value, error) {
+	if len(args) != 2 {
+		return value.Null, fmt.Errorf("%s: expected 2 arguments, got %d", name, len(args))
+	}
+
+	obj, err := objectArg(funcValue, args[0], 0)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if args[1].Type() != value.TypeArray {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[1],
+			Index:    1,
+			Inner: value.TypeError{
+				Value:    args[1],
+				Expected: value.TypeArray,
+			},
+		}
+	}
+	keys := make(map[string]struct{}, args[1].Len())
+	for i := 0; i < args[1].Len(); i++ {
+		key := args[1].Index(i)
+		if key.Type() != value.TypeString {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: args[1],
+				Index:    1,
+				Inner: value.ElementError{
+					Value: args[1],
+					Index: i,
+					Inner: value.TypeError{
+						Value:    key,
+						Expected: value.TypeString,
+					},
+				},
+			}
+		}
+		keys[key.Text()] = struct{}{}
+	}
+
+	res := make(map[string]value.Value)
+	for key, val := range obj {
+		if _, found := keys[key]; found == keep {
+			res[key] = val
+		}
+	}
+	return value.Object(res), nil
+}
+
+// objectArg returns the fields of arg, which must be an object or a capsule
+// convertible to an object.
+func objectArg(funcValue value.Value, arg value.Value, index int) (map[string]value.Value, error) {
+	if arg.Type() == value.TypeObject {
+		res := make(map[string]value.Value, arg.Len())
+		for _, key := range arg.Keys() {
+			res[key], _ = arg.Key(key)
+		}
+		return res, nil
+	}
+
+	if obj, ok := arg.TryConvertToObject(); ok {
+		return obj, nil
+	}
+
+	return nil, value.ArgError{
+		Function: funcValue,
+		Argument: arg,
+		Index:    index,
+		Inner: value.TypeError{
+			Value:    arg,
+			Expected: value.TypeObject,
+		},
+	}
+}
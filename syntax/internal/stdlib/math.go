@@ -0,0 +1,72 @@
+package stdlib
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+
+	"github.com/grafana/alloy/syntax/internal/value"
+)
+
+var mathFuncs = map[string]any{
+	"min":   minNumber,
+	"max":   maxNumber,
+	"floor": floor,
+}
+
+// minNumber returns the smallest of its arguments. The argument is returned
+// unmodified so that integers stay integers.
+var minNumber = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	return pickNumber("min", funcValue, args, func(c int) bool { return c < 0 })
+})
+
+// maxNumber returns the largest of its arguments. The argument is returned
+// unmodified so that integers stay integers.
+var maxNumber = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	return pickNumber("max", funcValue, args, func(c int) bool { return c > 0 })
+})
+
+// pickNumber returns the number from args for which better reports true when
+// compared against every other argument.
+func pickNumber(name string, funcValue value.Value, args []value.Value, better func(c int) bool) (value.Value, error) {
+	if len(args) == 0 {
+		return value.Null, fmt.Errorf("%s: expected at least 1 argument, got 0", name)
+	}
+
+	var res value.Value
+	for i, arg := range args {
+		if arg.Type() != value.TypeNumber {
+			return value.Null, value.ArgError{
+				Function: funcValue,
+				Argument: arg,
+				Index:    i,
+				Inner: value.TypeError{
+					Value:    arg,
+					Expected: value.TypeNumber,
+				},
+			}
+		}
+		if i == 0 || better(compareNumbers(arg.Number(), res.Number())) {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+func floor(in float64) float64 {
+	return math.Floor(in)
+}
+
+// compareNumbers returns -1, 0 or 1 depending on whether a is smaller than,
+// equal to, or larger than b. Numbers of different kinds are compared using
+// the most precise of both kinds, so that 3 and 3.0 are equal.
+func compareNumbers(a, b value.Number) int {
+	switch {
+	case a.Kind() == value.NumberKindFloat || b.Kind() == value.NumberKindFloat:
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == value.NumberKindInt || b.Kind() == value.NumberKindInt:
+		return cmp.Compare(a.Int(), b.Int())
+	default:
+		return cmp.Compare(a.Uint(), b.Uint())
+	}
+}
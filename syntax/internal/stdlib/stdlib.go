@@ -9,6 +9,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/ohler55/ojg/jp"
@@ -22,8 +25,28 @@ import (
 // ExperimentalIdentifiers contains the full name (namespace + identifier's name) of stdlib
 // identifiers that are considered "experimental".
 var ExperimentalIdentifiers = map[string]bool{
-	"array.combine_maps": true,
-	"array.group_by":     true,
+	"array.combine_maps":   true,
+	"array.group_by":       true,
+	"array.distinct":       true,
+	"array.filter_by_key":  true,
+	"array.flatten":        true,
+	"array.length":         true,
+	"hash.sha256":          true,
+	"hash.md5":             true,
+	"hash.fnv":             true,
+	"map.keys":             true,
+	"map.values":           true,
+	"map.merge":            true,
+	"map.pick":             true,
+	"map.omit":             true,
+	"math.min":             true,
+	"math.max":             true,
+	"math.floor":           true,
+	"string.regex_match":   true,
+	"string.regex_replace": true,
+	"time.now":             true,
+	"time.format":          true,
+	"time.parse_duration":  true,
 }
 
 // DeprecatedIdentifiers are deprecated in favour of the namespaced ones.
@@ -64,6 +87,10 @@ var Identifiers = map[string]any{
 	"encoding": encoding,
 	"string":   str,
 	"file":     file,
+	"hash":     hash,
+	"math":     mathFuncs,
+	"map":      mapFuncs,
+	"time":     timeFuncs,
 }
 
 func init() {
@@ -98,6 +125,30 @@ var str = map[string]any{
 	"trim_prefix": strings.TrimPrefix,
 	"trim_suffix": strings.TrimSuffix,
 	"trim_space":  strings.TrimSpace,
+
+	"regex_match":   regexMatch,
+	"regex_replace": regexReplace,
+}
+
+// regexMatch reports whether in contains any match of the regular expression
+// pattern.
+func regexMatch(in string, pattern string) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(in), nil
+}
+
+// regexReplace replaces all matches of the regular expression pattern in in
+// with replacement. Inside replacement, $ signs are interpreted as in
+// regexp.Expand, so $1 refers to the first capture group.
+func regexReplace(in string, pattern string, replacement string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(in, replacement), nil
 }
 
 // groupBy takes an array of objects, a key to group by, and a boolean to determine
@@ -206,9 +257,13 @@ var groupBy = value.RawFunction(func(funcValue value.Value, args ...value.Value)
 })
 
 var array = map[string]any{
-	"concat":       concat,
-	"combine_maps": combineMaps,
-	"group_by":     groupBy,
+	"concat":        concat,
+	"combine_maps":  combineMaps,
+	"group_by":      groupBy,
+	"filter_by_key": filterByKey,
+	"distinct":      distinct,
+	"flatten":       flatten,
+	"length":        length,
 }
 
 var convert = map[string]any{
@@ -431,6 +486,196 @@ var combineMaps = value.RawFunction(func(funcValue value.Value, args ...value.Va
 	return value.Array(res...), nil
 })
 
+// filterByKey takes an array of objects, a key and a value. It returns the
+// objects for which key is set to value. Objects missing the key are dropped.
+var filterByKey = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 3 {
+		return value.Null, fmt.Errorf("filter_by_key: expected 3 arguments, got %d", len(args))
+	}
+
+	if args[0].Type() != value.TypeArray {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[0],
+			Index:    0,
+			Inner: value.TypeError{
+				Value:    args[0],
+				Expected: value.TypeArray,
+			},
+		}
+	}
+
+	if args[1].Type() != value.TypeString {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[1],
+			Index:    1,
+			Inner: value.TypeError{
+				Value:    args[1],
+				Expected: value.TypeString,
+			},
+		}
+	}
+
+	key := args[1].Text()
+
+	res := []value.Value{}
+	for i := 0; i < args[0].Len(); i++ {
+		item := args[0].Index(i)
+		if item.Type() != value.TypeObject {
+			obj, ok := item.TryConvertToObject()
+			if !ok {
+				return value.Null, value.ArgError{
+					Function: funcValue,
+					Argument: args[0],
+					Index:    0,
+					Inner: value.ElementError{
+						Value: args[0],
+						Index: i,
+						Inner: value.TypeError{
+							Value:    item,
+							Expected: value.TypeObject,
+						},
+					},
+				}
+			}
+			item = value.Object(obj)
+		}
+
+		if val, ok := item.Key(key); ok && valuesEqual(val, args[2]) {
+			res = append(res, args[0].Index(i))
+		}
+	}
+
+	return value.Array(res...), nil
+})
+
+// distinct returns the elements of an array with duplicates removed. The
+// first occurrence of each element is kept, preserving order.
+var distinct = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, fmt.Errorf("distinct: expected 1 argument, got %d", len(args))
+	}
+	if args[0].Type() != value.TypeArray {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[0],
+			Index:    0,
+			Inner: value.TypeError{
+				Value:    args[0],
+				Expected: value.TypeArray,
+			},
+		}
+	}
+
+	res := make([]value.Value, 0, args[0].Len())
+	for i := 0; i < args[0].Len(); i++ {
+		elem := args[0].Index(i)
+		if !slices.ContainsFunc(res, func(v value.Value) bool { return valuesEqual(v, elem) }) {
+			res = append(res, elem)
+		}
+	}
+	return value.Array(res...), nil
+})
+
+// flatten recursively replaces nested arrays with their elements.
+var flatten = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, fmt.Errorf("flatten: expected 1 argument, got %d", len(args))
+	}
+	if args[0].Type() != value.TypeArray {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[0],
+			Index:    0,
+			Inner: value.TypeError{
+				Value:    args[0],
+				Expected: value.TypeArray,
+			},
+		}
+	}
+
+	var flattenInto func(res []value.Value, arr value.Value) []value.Value
+	flattenInto = func(res []value.Value, arr value.Value) []value.Value {
+		for i := 0; i < arr.Len(); i++ {
+			elem := arr.Index(i)
+			if elem.Type() == value.TypeArray {
+				res = flattenInto(res, elem)
+			} else {
+				res = append(res, elem)
+			}
+		}
+		return res
+	}
+
+	return value.Array(flattenInto([]value.Value{}, args[0])...), nil
+})
+
+// length returns the number of elements in an array.
+var length = value.RawFunction(func(funcValue value.Value, args ...value.Value) (value.Value, error) {
+	if len(args) != 1 {
+		return value.Null, fmt.Errorf("length: expected 1 argument, got %d", len(args))
+	}
+	if args[0].Type() != value.TypeArray {
+		return value.Null, value.ArgError{
+			Function: funcValue,
+			Argument: args[0],
+			Index:    0,
+			Inner: value.TypeError{
+				Value:    args[0],
+				Expected: value.TypeArray,
+			},
+		}
+	}
+	return value.Int(int64(args[0].Len())), nil
+})
+
+// valuesEqual reports whether two values are deeply equal, following the
+// semantics of the == operator.
+func valuesEqual(lhs value.Value, rhs value.Value) bool {
+	if lhs.Type() != rhs.Type() {
+		return false
+	}
+
+	switch lhs.Type() {
+	case value.TypeNull:
+		return true
+	case value.TypeNumber:
+		return compareNumbers(lhs.Number(), rhs.Number()) == 0
+	case value.TypeString:
+		return lhs.Text() == rhs.Text()
+	case value.TypeBool:
+		return lhs.Bool() == rhs.Bool()
+	case value.TypeArray:
+		if lhs.Len() != rhs.Len() {
+			return false
+		}
+		for i := 0; i < lhs.Len(); i++ {
+			if !valuesEqual(lhs.Index(i), rhs.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case value.TypeObject:
+		if lhs.Len() != rhs.Len() {
+			return false
+		}
+		for _, key := range lhs.Keys() {
+			lhsElement, _ := lhs.Key(key)
+			rhsElement, inRHS := rhs.Key(key)
+			if !inRHS || !valuesEqual(lhsElement, rhsElement) {
+				return false
+			}
+		}
+		return true
+	case value.TypeCapsule:
+		return reflect.DeepEqual(lhs.Interface(), rhs.Interface())
+	default:
+		// Functions can't be compared.
+		return false
+	}
+}
+
 func jsonDecode(in string) (any, error) {
 	var res any
 	err := json.Unmarshal([]byte(in), &res)
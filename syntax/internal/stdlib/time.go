@@ -0,0 +1,37 @@
+package stdlib
+
+import (
+	"time"
+)
+
+var timeFuncs = map[string]any{
+	"now":            timeNow,
+	"format":         timeFormat,
+	"parse_duration": parseDuration,
+}
+
+// timeNow returns the current time in UTC formatted as RFC 3339. Unlike most
+// stdlib functions, it isn't pure: its result changes every time the
+// expression is evaluated.
+func timeNow() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// timeFormat formats an RFC 3339 timestamp using a Go reference layout.
+func timeFormat(timestamp string, layout string) (string, error) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// parseDuration parses a Go duration string and returns the number of
+// seconds it represents.
+func parseDuration(in string) (float64, error) {
+	d, err := time.ParseDuration(in)
+	if err != nil {
+		return 0, err
+	}
+	return d.Seconds(), nil
+}
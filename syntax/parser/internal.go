@@ -379,9 +379,34 @@ func (n blockName) ValidAttribute() bool {
 
 // ParseExpression parses a single expression.
 //
-//	Expression = BinOpExpr
+//	Expression = ConditionalExpr
 func (p *parser) ParseExpression() ast.Expr {
-	return p.parseBinOp(1)
+	return p.parseConditionalExpr()
+}
+
+// parseConditionalExpr parses a conditional expression. The conditional
+// operator has the lowest precedence of all operators and is
+// right-associative, so a ? b : c ? d : e is parsed as a ? b : (c ? d : e).
+//
+//	ConditionalExpr = BinOpExpr [ "?" Expression ":" Expression ]
+func (p *parser) parseConditionalExpr() ast.Expr {
+	cond := p.parseBinOp(1)
+	if p.tok != token.QUESTION {
+		return cond
+	}
+
+	questionPos, _, _ := p.expect(token.QUESTION)
+	trueExpr := p.ParseExpression()
+	colonPos, _, _ := p.expect(token.COLON)
+	falseExpr := p.ParseExpression()
+
+	return &ast.ConditionalExpr{
+		Condition:   cond,
+		QuestionPos: questionPos,
+		True:        trueExpr,
+		ColonPos:    colonPos,
+		False:       falseExpr,
+	}
 }
 
 // parseBinOp is the entrypoint for binary expressions. If there is no binary
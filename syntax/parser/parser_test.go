@@ -108,6 +108,13 @@ func TestParseExpressions(t *testing.T) {
 
 		"parens": `(1 + 5) * 100`,
 
+		"conditional":        `a ? b : c`,
+		"nested conditional": `a ? b : c ? d : e`,
+		"conditional binops": `a == 1 || b ? c + 1 : [c]`,
+		"conditional multiline": `a ?
+			b :
+			c`,
+
 		"mixed expression": `(a.b.c)(1, 3 * some_list[magic_index * 2]).resulting_field`,
 	}
 
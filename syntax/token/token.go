@@ -57,6 +57,9 @@ const (
 	RBRACK // ]
 	COMMA  // ,
 	DOT    // .
+
+	QUESTION // ?
+	COLON    // :
 	operatorEnd
 
 	TERMINATOR // \n
@@ -103,6 +106,9 @@ var tokenNames = [...]string{
 	COMMA:  ",",
 	DOT:    ".",
 
+	QUESTION: "?",
+	COLON:    ":",
+
 	TERMINATOR: "TERMINATOR",
 }
 
@@ -54,8 +54,9 @@ func makeDiagnostic(err error, assoc map[value.Value]ast.Node) error {
 		if foundNode, ok := assoc[val]; ok {
 			// If we just found a direct node, we can reset the expression buffer so
 			// we don't unnecessarily print element and field accesses for we can see
-			// directly in the file.
-			if literal {
+			// directly in the file. Accesses into the same node, such as an element
+			// of a function argument, are kept.
+			if literal && foundNode != node {
 				expr.Reset()
 			}
 
@@ -63,6 +63,22 @@ func TestVM_ExprErrors(t *testing.T) {
 			}{},
 			expect: `test:1:7: [0, 1, 2] should be string, got array`,
 		},
+		{
+			name:  "non-bool conditional",
+			input: `key = 1 ? "a" : "b"`,
+			into: &struct {
+				Key string `alloy:"key,attr"`
+			}{},
+			expect: `test:1:7: 1 should be bool, got number`,
+		},
+		{
+			name:  "error in selected conditional branch",
+			input: `key = true ? {}.missing : "b"`,
+			into: &struct {
+				Key string `alloy:"key,attr"`
+			}{},
+			expect: `test:1:17: field "missing" does not exist`,
+		},
 	}
 
 	for _, tc := range tt {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/grafana/alloy/syntax/internal/value"
@@ -364,6 +365,10 @@ func TestStdlib_StringFunc(t *testing.T) {
 		{"string.trim2", `string.trim("   hello! world.!  ", "! ")`, "hello! world."},
 		{"string.trim_prefix", `string.trim_prefix("helloworld", "hello")`, "world"},
 		{"string.trim_suffix", `string.trim_suffix("helloworld", "world")`, "hello"},
+		{"string.regex_match", `string.regex_match("api-server-0", "^api-.*-[0-9]+$")`, true},
+		{"string.regex_match no match", `string.regex_match("web-0", "^api-")`, false},
+		{"string.regex_replace", `string.regex_replace("10.0.0.1:9090", ":[0-9]+$", "")`, "10.0.0.1"},
+		{"string.regex_replace groups", `string.regex_replace("pod-abc", "^pod-(.*)$", "${1}-pod")`, "abc-pod"},
 	}
 
 	for _, tc := range tt {
@@ -404,6 +409,258 @@ func TestStdlibFileFunc(t *testing.T) {
 	}
 }
 
+func TestStdlibHashFunc(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect any
+	}{
+		{"hash.sha256", `hash.sha256("alloy")`, "65d9d6c5c7c2d5c29e38b777edf8da1dbd764f67deeeaa230724967cafe4e684"},
+		{"hash.sha256 empty", `hash.sha256("")`, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"hash.md5", `hash.md5("alloy")`, "78b82064884b15d740b0d1fb69df3903"},
+		{"hash.md5 empty", `hash.md5("")`, "d41d8cd98f00b204e9800998ecf8427e"},
+		{"hash.fnv empty", `hash.fnv("")`, uint64(14695981039346656037)},
+		{"hash.fnv", `hash.fnv("a")`, uint64(12638187200555641996)},
+		{"hash.fnv modulo", `hash.fnv("a") % 4`, uint64(0)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(nil, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlibMathFunc(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect any
+	}{
+		{"math.min", `math.min(3, 1, 2)`, 1},
+		{"math.min single", `math.min(5)`, 5},
+		{"math.min negative", `math.min(3, -1.5, 2)`, -1.5},
+		{"math.min mixed kinds", `math.min(2.5, 2)`, float64(2)},
+		{"math.max", `math.max(3, 1, 2)`, 3},
+		{"math.max float", `math.max(0.5, 0.25)`, 0.5},
+		{"math.floor", `math.floor(2.7)`, float64(2)},
+		{"math.floor negative", `math.floor(-2.5)`, float64(-3)},
+		{"math.floor int", `math.floor(4)`, 4},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(nil, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlibTimeFunc(t *testing.T) {
+	t.Run("time.now", func(t *testing.T) {
+		expr, err := parser.ParseExpression(`time.now()`)
+		require.NoError(t, err)
+
+		var res string
+		require.NoError(t, vm.New(expr).Evaluate(nil, &res))
+
+		now, err := time.Parse(time.RFC3339Nano, res)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), now, time.Minute)
+	})
+
+	tt := []struct {
+		name   string
+		input  string
+		expect any
+	}{
+		{"time.format", `time.format("2024-03-05T10:20:30Z", "2006-01-02")`, "2024-03-05"},
+		{"time.format with offset", `time.format("2024-03-05T10:20:30+02:00", "15:04 MST")`, "10:20 +0200"},
+		{"time.format now", `string.regex_match(time.format(time.now(), "2006"), "^[0-9]{4}$")`, true},
+		{"time.parse_duration", `time.parse_duration("1m30s")`, float64(90)},
+		{"time.parse_duration subsecond", `time.parse_duration("250ms")`, 0.25},
+		{"time.parse_duration arithmetic", `time.parse_duration("2h") / 60`, float64(120)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(nil, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlibMapFunc(t *testing.T) {
+	scope := vm.NewScope(map[string]any{
+		"labels": map[string]string{"job": "api", "instance": "localhost:9090", "env": "prod"},
+	})
+
+	tt := []struct {
+		name   string
+		input  string
+		expect any
+	}{
+		{"map.keys", `map.keys({"b" = 1, "a" = 2, "c" = 3})`, []string{"a", "b", "c"}},
+		{"map.keys empty", `map.keys({})`, []string{}},
+		{"map.keys scope", `map.keys(labels)`, []string{"env", "instance", "job"}},
+		{"map.values", `map.values({"b" = 1, "a" = 2, "c" = 3})`, []int{2, 1, 3}},
+		{"map.values mixed", `map.values({"a" = "x", "b" = [1]})`, []any{"x", []any{1}}},
+		{"map.merge", `map.merge({"a" = 1, "b" = 2}, {"b" = 3}, {"c" = 4})`, map[string]int{"a": 1, "b": 3, "c": 4}},
+		{"map.merge none", `map.merge()`, map[string]int{}},
+		{"map.pick", `map.pick(labels, ["job", "env", "missing"])`, map[string]string{"job": "api", "env": "prod"}},
+		{"map.pick none", `map.pick(labels, [])`, map[string]string{}},
+		{"map.omit", `map.omit(labels, ["instance"])`, map[string]string{"job": "api", "env": "prod"}},
+		{"map.omit missing", `map.omit({"a" = 1}, ["b"])`, map[string]int{"a": 1}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(scope, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlibArrayFunc(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		expect any
+	}{
+		{
+			"array.filter_by_key",
+			`array.filter_by_key([{"env" = "prod", "name" = "a"}, {"env" = "dev", "name" = "b"}, {"name" = "c"}], "env", "prod")`,
+			[]map[string]any{{"env": "prod", "name": "a"}},
+		},
+		{
+			"array.filter_by_key number",
+			`array.filter_by_key([{"port" = 80}, {"port" = 443}, {"port" = 80.0}], "port", 80)`,
+			[]map[string]any{{"port": 80}, {"port": float64(80)}},
+		},
+		{
+			"array.filter_by_key no match",
+			`array.filter_by_key([{"env" = "dev"}], "env", "prod")`,
+			[]map[string]any{},
+		},
+		{"array.distinct", `array.distinct([1, 2, 1, 3, 2])`, []int{1, 2, 3}},
+		{"array.distinct strings", `array.distinct(["b", "a", "b"])`, []string{"b", "a"}},
+		{"array.distinct objects", `array.distinct([{"a" = 1}, {"a" = 2}, {"a" = 1}])`, []map[string]int{{"a": 1}, {"a": 2}}},
+		{"array.distinct mixed types", `array.distinct([1, "1", 1.0])`, []any{1, "1"}},
+		{"array.distinct empty", `array.distinct([])`, []any{}},
+		{"array.flatten", `array.flatten([[1, 2], [3], [], 4])`, []int{1, 2, 3, 4}},
+		{"array.flatten nested", `array.flatten([[1, [2, [3]]], [[[4]]]])`, []int{1, 2, 3, 4}},
+		{"array.flatten empty", `array.flatten([])`, []any{}},
+		{"array.length", `array.length([1, 2, 3])`, 3},
+		{"array.length empty", `array.length([])`, 0},
+		{"array.length nested", `array.length([[1, 2], [3]])`, 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			rv := reflect.New(reflect.TypeOf(tc.expect))
+			require.NoError(t, eval.Evaluate(nil, rv.Interface()))
+			require.Equal(t, tc.expect, rv.Elem().Interface())
+		})
+	}
+}
+
+func TestStdlibFunc_Errors(t *testing.T) {
+	tt := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{"string.regex_match invalid pattern", `string.regex_match("a", "(")`, "missing closing )"},
+		{"string.regex_replace invalid pattern", `string.regex_replace("a", "[", "")`, "missing closing ]"},
+		{"math.min no arguments", `math.min()`, "min: expected at least 1 argument, got 0"},
+		{"math.max not a number", `math.max(1, "2")`, `"2" should be number, got string`},
+		{"time.format invalid timestamp", `time.format("yesterday", "2006")`, `cannot parse "yesterday"`},
+		{"time.parse_duration invalid", `time.parse_duration("1 hour")`, `unknown unit " hour"`},
+		{"map.keys not an object", `map.keys([1])`, `[1] should be object, got array`},
+		{"map.merge not an object", `map.merge({"a" = 1}, "b")`, `"b" should be object, got string`},
+		{"map.pick wrong number of arguments", `map.pick({})`, "pick: expected 2 arguments, got 1"},
+		{"map.omit keys not array", `map.omit({}, "a")`, `"a" should be array, got string`},
+		{"map.omit key not string", `map.omit({}, [1])`, `1 should be string, got number`},
+		{"array.filter_by_key element not object", `array.filter_by_key([1], "a", 1)`, `1 should be object, got number`},
+		{"array.filter_by_key key not string", `array.filter_by_key([], 1, 1)`, `1 should be string, got number`},
+		{"array.distinct not an array", `array.distinct("a")`, `"a" should be array, got string`},
+		{"array.flatten wrong number of arguments", `array.flatten([], [])`, "flatten: expected 1 argument, got 2"},
+		{"array.length not an array", `array.length({})`, `{} should be array, got object`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			var res any
+			err = eval.Evaluate(nil, &res)
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestStdlibFunc_ElementErrors(t *testing.T) {
+	scope := vm.NewScope(map[string]any{
+		"keys":    []any{"a", "b", 1},
+		"targets": []any{map[string]any{"a": 1}, "b"},
+	})
+
+	tt := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{"map.pick key not string", `map.pick({}, keys)`, `keys[2] should be string, got number`},
+		{"array.filter_by_key element not object", `array.filter_by_key(targets, "a", 1)`, `targets[1] should be object, got string`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := parser.ParseExpression(tc.input)
+			require.NoError(t, err)
+
+			eval := vm.New(expr)
+
+			var res any
+			err = eval.Evaluate(scope, &res)
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
 func BenchmarkConcat(b *testing.B) {
 	// There's a bit of setup work to do here: we want to create a scope holding
 	// a slice of the Person type, which has a fair amount of data in it.
@@ -181,6 +181,15 @@ func TestVM_Evaluate(t *testing.T) {
 		{`!true`, bool(false)},
 		{`!false`, bool(true)},
 		{`-15`, int(-15)},
+
+		// Conditional
+		{`true ? 1 : 2`, int(1)},
+		{`false ? 1 : 2`, int(2)},
+		{`foobar == 42 ? "yes" : "no"`, string("yes")},
+		{`false ? 1 : true ? 2 : 3`, int(2)},
+		{`true ? null : 3`, nil},
+		{`false ? {}.missing.field : 3`, int(3)}, // Unselected branch isn't evaluated
+		{`(true ? 1 : 2) + 1`, int(2)},
 	}
 
 	for _, tc := range tt {